@@ -0,0 +1,231 @@
+package jsonutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeOptions 保存文件写入选项
+type writeOptions struct {
+	perm    os.FileMode
+	hasPerm bool
+	backups int
+	lock    bool
+}
+
+// WriteOption 是设置文件写入选项的函数类型
+type WriteOption func(*writeOptions)
+
+// WithFileMode 设置写入文件的权限，默认沿用已有文件的权限，文件不存在时为 0644
+func WithFileMode(perm os.FileMode) WriteOption {
+	return func(o *writeOptions) {
+		o.perm = perm.Perm()
+		o.hasPerm = true
+	}
+}
+
+// WithBackups 覆盖前保留最近 n 个旧版本，依次命名为 filename.1（最新）到 filename.n
+func WithBackups(n int) WriteOption {
+	return func(o *writeOptions) {
+		o.backups = n
+	}
+}
+
+// WithWriteLock 写入期间持有文件的排他锁，见 LockFile
+func WithWriteLock() WriteOption {
+	return func(o *writeOptions) {
+		o.lock = true
+	}
+}
+
+// WriteFileAtomic 原子地写入文件：先写入同目录下的临时文件并 fsync，再重命名为目标文件，
+// 写入过程中崩溃不会留下不完整的文件，读者看到的总是旧内容或新内容之一
+func WriteFileAtomic(filename string, data []byte, opts ...WriteOption) error {
+	o := writeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.lock {
+		lock, err := LockFile(filename)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+	}
+	return writeFileAtomic(filename, data, &o)
+}
+
+// writeFileAtomic 执行原子写入，调用方负责加锁
+func writeFileAtomic(filename string, data []byte, o *writeOptions) error {
+	perm := o.perm
+	if !o.hasPerm {
+		perm = 0644
+		if info, err := os.Stat(filename); err == nil {
+			perm = info.Mode().Perm()
+		}
+	}
+
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if o.backups > 0 {
+		if err := rotateBackups(filename, o.backups); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+	committed = true
+	return syncDir(dir)
+}
+
+// rotateBackups 将 filename.1 … filename.n-1 依次后移一位，并把当前文件保存为 filename.1
+// 当前文件通过硬链接保留，不支持硬链接时复制内容，目标文件始终存在
+func rotateBackups(filename string, n int) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	for i := n - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", filename, i)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", filename, i+1)); err != nil {
+			return err
+		}
+	}
+	first := filename + ".1"
+	if err := os.Remove(first); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(filename, first); err == nil {
+		return nil
+	}
+	return copyFile(filename, first)
+}
+
+// copyFile 复制文件内容和权限
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// FileLock 是基于 filename.lock 辅助文件的建议锁，只约束同样使用 LockFile、RLockFile 的读写方
+// 在 Linux、macOS、BSD 和 illumos 上使用 flock，可跨进程生效；其他平台（包括 Windows、Solaris、AIX）退化为进程内的锁
+type FileLock struct {
+	path   string
+	f      *os.File
+	unlock func()
+}
+
+// LockFile 获取文件的排他锁，阻塞直到成功，用于读取-修改-写入等需要独占的操作
+func LockFile(filename string) (*FileLock, error) {
+	return lockFile(filename, true)
+}
+
+// RLockFile 获取文件的共享锁，多个读者可同时持有，与排他锁互斥
+func RLockFile(filename string) (*FileLock, error) {
+	return lockFile(filename, false)
+}
+
+// Path 返回锁文件的路径
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// Unlock 释放锁，重复调用无副作用
+func (l *FileLock) Unlock() error {
+	if l.unlock == nil {
+		return nil
+	}
+	l.unlock()
+	l.unlock = nil
+	if l.f != nil {
+		err := l.f.Close()
+		l.f = nil
+		return err
+	}
+	return nil
+}
+
+// UpdateJSONFile 在排他锁保护下读取 JSON 文件、调用 fn 修改后原子地写回，文件不存在时从零值开始
+// fn 返回错误时不写入；数字按 UnmarshalExact 的规则解码，写回时使用两个空格缩进
+func UpdateJSONFile[T any](filename string, fn func(*T) error, opts ...WriteOption) error {
+	o := writeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	lock, err := LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	v := new(T)
+	data, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		if err := UnmarshalExact(data, v); err != nil {
+			return annotateJSONError(data, filename, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	if err := fn(v); err != nil {
+		return err
+	}
+	out, err := MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, out, &o)
+}
@@ -0,0 +1,294 @@
+package jsonutil
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/govvii/go-hutool/codec"
+)
+
+// HashAlgorithm 表示 CanonicalHash 使用的哈希算法
+type HashAlgorithm int
+
+const (
+	// HashSHA256 SHA-256（默认推荐）
+	HashSHA256 HashAlgorithm = iota
+	// HashSHA512 SHA-512
+	HashSHA512
+	// HashSHA1 SHA-1，仅用于兼容旧系统
+	HashSHA1
+	// HashMD5 MD5，仅用于去重等非安全场景
+	HashMD5
+)
+
+// newHash 返回算法对应的 hash.Hash
+func (a HashAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	case HashSHA1:
+		return sha1.New(), nil
+	case HashMD5:
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm %d", int(a))
+}
+
+// Canonicalize 按 RFC 8785（JSON Canonicalization Scheme）将 Go 值编码为规范 JSON
+// 对象的键按 UTF-16 码元排序，数字按 ECMAScript 规则格式化，字符串只做最少的转义；
+// 值先按 Marshal 的规则编码，已是 JSON 文本的字节请使用 CanonicalizeJSON 或包装为 json.RawMessage
+func Canonicalize(v interface{}) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return CanonicalizeJSON(data)
+}
+
+// CanonicalizeJSON 按 RFC 8785 将 JSON 文本转换为规范形式
+// RFC 8785 要求输入符合 I-JSON，对象中出现重复的成员名时返回错误，而不是静默保留其中一个
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeCanonical(dec, nil)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCanonical 逐个读取记号解码 JSON 值，对象中出现重复的成员名时返回包含其路径的错误
+func decodeCanonical(dec *json.Decoder, keys []interface{}) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			if _, ok := obj[key]; ok {
+				return nil, fmt.Errorf("%s: duplicate member name %q", pathLabel(dottedPath(keys)), key)
+			}
+			value, err := decodeCanonical(dec, append(append([]interface{}{}, keys...), key))
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeCanonical(dec, append(append([]interface{}{}, keys...), len(arr)))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return tok, nil
+}
+
+// CanonicalHash 计算值的 RFC 8785 规范 JSON 的哈希，返回十六进制字符串
+// 语义相同的 JSON 值（键顺序、空白、数字写法不同）得到相同的哈希，可用于签名和去重
+func CanonicalHash(v interface{}, algo HashAlgorithm) (string, error) {
+	data, err := Canonicalize(v)
+	if err != nil {
+		return "", err
+	}
+	h, err := algo.newHash()
+	if err != nil {
+		return "", err
+	}
+	return codec.HexEncode(codec.HashBytes(h, data)), nil
+}
+
+// writeCanonical 将解析后的值写为规范 JSON
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch c := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(c))
+	case string:
+		writeCanonicalString(buf, c)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(c), 64)
+		if err != nil {
+			return fmt.Errorf("number %s cannot be represented as an IEEE 754 double", c)
+		}
+		s, err := formatESNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sortUTF16(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, c[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range c {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+// sortUTF16 按 UTF-16 码元序列对字符串排序
+func sortUTF16(keys []string) {
+	encoded := make(map[string][]uint16, len(keys))
+	for _, k := range keys {
+		encoded[k] = utf16.Encode([]rune(k))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := encoded[keys[i]], encoded[keys[j]]
+		for n := 0; n < len(a) && n < len(b); n++ {
+			if a[n] != b[n] {
+				return a[n] < b[n]
+			}
+		}
+		return len(a) < len(b)
+	})
+}
+
+// writeCanonicalString 按 RFC 8785 转义字符串：只转义引号、反斜杠和控制字符
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xF])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formatESNumber 按 ECMAScript Number.prototype.toString 的规则格式化 IEEE 754 双精度数
+func formatESNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%v cannot be represented in JSON", f)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+	// 最短往返表示的有效数字和十进制指数
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, expStr, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	exp, _ := strconv.Atoi(expStr)
+	k, n := len(digits), exp+1
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	switch {
+	case k <= n && n <= 21:
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		sb.WriteString(digits[:n])
+		sb.WriteByte('.')
+		sb.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -n))
+		sb.WriteString(digits)
+	default:
+		sb.WriteByte(digits[0])
+		if k > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+		sb.WriteByte('e')
+		if n-1 >= 0 {
+			sb.WriteByte('+')
+		}
+		sb.WriteString(strconv.Itoa(n - 1))
+	}
+	return sb.String(), nil
+}
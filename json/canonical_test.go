@@ -0,0 +1,102 @@
+package jsonutil
+
+import (
+	"io"
+	"testing"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			// RFC 8785 第 3.2.2 节
+			name: "rfc8785 sample",
+			in: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 第 3.2.3 节，按 UTF-16 码元排序
+			name: "rfc8785 sorting",
+			in: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			want: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{name: "zero", in: `0`, want: `0`},
+		{name: "negative zero", in: `-0`, want: `0`},
+		{name: "large integer", in: `9007199254740992`, want: `9007199254740992`},
+		{name: "exponent threshold", in: `1e21`, want: `1e+21`},
+		{name: "below exponent threshold", in: `295147905179352830000`, want: `295147905179352830000`},
+		{name: "small fraction", in: `0.000001`, want: `0.000001`},
+		{name: "small exponent", in: `1e-7`, want: `1e-7`},
+		{name: "min subnormal", in: `5e-324`, want: `5e-324`},
+		{name: "max float", in: `1.7976931348623157e308`, want: `1.7976931348623157e+308`},
+		{name: "nested", in: `{"b":[{"d":1,"c":2}],"a":{}}`, want: `{"a":{},"b":[{"c":2,"d":1}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("CanonicalizeJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalizeJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "duplicate member", in: `{"a":1,"a":2}`},
+		{name: "nested duplicate member", in: `{"x":[{"a":1,"a":1}]}`},
+		{name: "trailing value", in: `{"a":1} 2`},
+		{name: "trailing object end", in: `{"a":1}}`},
+		{name: "trailing array end", in: `[1]]`},
+		{name: "empty", in: ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := CanonicalizeJSON([]byte(tt.in)); err == nil {
+				t.Errorf("CanonicalizeJSON() = %s, want error", got)
+			}
+		})
+	}
+
+	if _, err := CanonicalizeJSON([]byte(`{"a":`)); err != io.ErrUnexpectedEOF {
+		t.Errorf("CanonicalizeJSON() truncated error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	a, err := CanonicalHash(map[string]interface{}{"b": 1, "a": []int{1, 2}}, HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CanonicalHash(struct {
+		A []float64 `json:"a"`
+		B float64   `json:"b"`
+	}{A: []float64{1, 2}, B: 1.0}, HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("CanonicalHash() differs for equivalent values: %s != %s", a, b)
+	}
+}
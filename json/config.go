@@ -0,0 +1,552 @@
+package jsonutil
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// configFile 表示一层配置文件
+type configFile struct {
+	path     string
+	optional bool
+}
+
+// configOptions 保存配置加载器的选项
+type configOptions struct {
+	files         []configFile
+	envPrefix     string
+	envSeparator  string
+	environ       func() []string
+	watchInterval time.Duration
+	lenient       []LenientOption
+}
+
+// ConfigOption 是设置配置加载器选项的函数类型
+type ConfigOption func(*configOptions)
+
+// WithConfigFiles 追加必须存在的配置文件，后加入的文件覆盖先加入的文件，格式由扩展名决定
+func WithConfigFiles(files ...string) ConfigOption {
+	return func(o *configOptions) {
+		for _, f := range files {
+			o.files = append(o.files, configFile{path: f})
+		}
+	}
+}
+
+// WithOptionalConfigFiles 追加可以不存在的配置文件，如按环境区分的 config.prod.yaml
+func WithOptionalConfigFiles(files ...string) ConfigOption {
+	return func(o *configOptions) {
+		for _, f := range files {
+			o.files = append(o.files, configFile{path: f, optional: true})
+		}
+	}
+}
+
+// WithEnvPrefix 启用环境变量覆盖，只处理以 prefix_ 开头的变量，如 "APP" 对应 APP_DB__HOST
+func WithEnvPrefix(prefix string) ConfigOption {
+	return func(o *configOptions) {
+		o.envPrefix = strings.TrimSuffix(prefix, "_")
+	}
+}
+
+// WithEnvSeparator 设置环境变量中表示层级的分隔符，默认为 "__"
+func WithEnvSeparator(sep string) ConfigOption {
+	return func(o *configOptions) {
+		o.envSeparator = sep
+	}
+}
+
+// WithConfigEnviron 设置环境变量的来源，默认为 os.Environ
+func WithConfigEnviron(environ func() []string) ConfigOption {
+	return func(o *configOptions) {
+		o.environ = environ
+	}
+}
+
+// WithWatchInterval 设置 Watch 检查文件变化的间隔，默认为 1 秒
+func WithWatchInterval(interval time.Duration) ConfigOption {
+	return func(o *configOptions) {
+		o.watchInterval = interval
+	}
+}
+
+// WithConfigLenientOptions 设置解码到结构体时使用的宽松解码选项，如 WithTimeLayouts
+func WithConfigLenientOptions(opts ...LenientOption) ConfigOption {
+	return func(o *configOptions) {
+		o.lenient = append(o.lenient, opts...)
+	}
+}
+
+// fileStamp 记录文件的状态，用于检测变化
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// ConfigLoader 按层次加载配置并解码为 T，优先级从低到高依次为：
+// 字段标签中的默认值、按顺序加入的配置文件、环境变量
+// 默认值来自 default 标签或 jsonschema 标签的 default= 选项，
+// 必填字段由 required:"true" 标签或 jsonschema 标签的 required 选项标记；
+// 合并结果使用 UnmarshalLenient 解码，因此环境变量中的 "8080"、"true"、"30s" 等字符串可以直接赋给对应类型的字段
+type ConfigLoader[T any] struct {
+	opts configOptions
+
+	mu       sync.RWMutex
+	value    *T
+	tree     interface{}
+	stamps   map[string]fileStamp
+	onChange []func(old, new *T)
+	onError  []func(error)
+
+	watchMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewConfigLoader 创建配置加载器
+func NewConfigLoader[T any](opts ...ConfigOption) *ConfigLoader[T] {
+	o := configOptions{envSeparator: "__", environ: os.Environ, watchInterval: time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.envSeparator == "" {
+		o.envSeparator = "__"
+	}
+	if o.environ == nil {
+		o.environ = os.Environ
+	}
+	if o.watchInterval <= 0 {
+		o.watchInterval = time.Second
+	}
+	return &ConfigLoader[T]{opts: o}
+}
+
+// LoadConfig 一次性加载配置，见 ConfigLoader
+func LoadConfig[T any](opts ...ConfigOption) (*T, error) {
+	return NewConfigLoader[T](opts...).Load()
+}
+
+// Load 加载配置并保存为当前值，不触发变更回调
+func (l *ConfigLoader[T]) Load() (*T, error) {
+	value, tree, stamps, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.value, l.tree, l.stamps = value, tree, stamps
+	l.mu.Unlock()
+	return value, nil
+}
+
+// Get 返回当前配置，尚未成功加载时返回 nil，返回值应视为只读
+func (l *ConfigLoader[T]) Get() *T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.value
+}
+
+// Tree 返回合并后、解码前的配置树（*JSONObject），便于调试或用 GetValueByPath 等函数读取
+func (l *ConfigLoader[T]) Tree() interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return cloneValue(l.tree)
+}
+
+// OnChange 注册配置变化时的回调，old 为变化前的配置，首次加载时为 nil
+func (l *ConfigLoader[T]) OnChange(fn func(old, new *T)) {
+	l.mu.Lock()
+	l.onChange = append(l.onChange, fn)
+	l.mu.Unlock()
+}
+
+// OnError 注册重新加载失败时的回调，失败时保留原有配置
+func (l *ConfigLoader[T]) OnError(fn func(error)) {
+	l.mu.Lock()
+	l.onError = append(l.onError, fn)
+	l.mu.Unlock()
+}
+
+// Reload 重新加载配置，内容有变化时替换当前值并依次调用变更回调，返回是否发生了变化
+func (l *ConfigLoader[T]) Reload() (bool, error) {
+	value, tree, stamps, err := l.load()
+	l.mu.Lock()
+	l.stamps = stamps
+	if err != nil {
+		handlers := l.onError
+		l.mu.Unlock()
+		for _, fn := range handlers {
+			fn(err)
+		}
+		return false, err
+	}
+	if l.value != nil && valuesEqual(l.tree, tree) {
+		l.mu.Unlock()
+		return false, nil
+	}
+	old := l.value
+	l.value, l.tree = value, tree
+	callbacks := l.onChange
+	l.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(old, value)
+	}
+	return true, nil
+}
+
+// Watch 在后台按 WithWatchInterval 设置的间隔轮询配置文件，文件被修改、创建或删除时调用 Reload
+// 尚未加载时先执行一次 Load；重复调用不会启动多个轮询，调用 Close 停止
+func (l *ConfigLoader[T]) Watch() error {
+	l.watchMu.Lock()
+	defer l.watchMu.Unlock()
+	if l.stop != nil {
+		return nil
+	}
+	if l.Get() == nil {
+		if _, err := l.Load(); err != nil {
+			return err
+		}
+	}
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	go l.poll(l.stop, l.done)
+	return nil
+}
+
+// Close 停止 Watch 启动的轮询并等待其退出
+func (l *ConfigLoader[T]) Close() {
+	l.watchMu.Lock()
+	defer l.watchMu.Unlock()
+	if l.stop == nil {
+		return
+	}
+	close(l.stop)
+	<-l.done
+	l.stop, l.done = nil, nil
+}
+
+// poll 定期检查文件状态
+func (l *ConfigLoader[T]) poll(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(l.opts.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if l.filesChanged() {
+				_, _ = l.Reload()
+			}
+		}
+	}
+}
+
+// filesChanged 判断配置文件的状态是否与上次加载时不同
+func (l *ConfigLoader[T]) filesChanged() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, f := range l.opts.files {
+		if statFile(f.path) != l.stamps[f.path] {
+			return true
+		}
+	}
+	return false
+}
+
+// statFile 返回文件当前的状态
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// load 合并各层配置、校验必填字段并解码
+func (l *ConfigLoader[T]) load() (*T, interface{}, map[string]fileStamp, error) {
+	// 先记录文件状态，加载失败时也据此避免对同一次修改反复报错
+	stamps := make(map[string]fileStamp, len(l.opts.files))
+	for _, f := range l.opts.files {
+		stamps[f.path] = statFile(f.path)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	tree := interface{}(NewJSONObject())
+	if derefType(t).Kind() == reflect.Struct {
+		defaults := NewJSONObject()
+		collectConfigDefaults(derefType(t), defaults)
+		tree = defaults
+	}
+
+	for _, f := range l.opts.files {
+		layer, err := readConfigFile(f)
+		if err != nil {
+			return nil, nil, stamps, err
+		}
+		if layer != nil {
+			tree = DeepMerge(tree, layer)
+		}
+	}
+
+	if l.opts.envPrefix != "" {
+		if err := l.applyEnv(tree, t); err != nil {
+			return nil, nil, stamps, err
+		}
+	}
+
+	if derefType(t).Kind() == reflect.Struct {
+		var missing []string
+		checkConfigRequired(derefType(t), tree, nil, &missing)
+		if len(missing) > 0 {
+			return nil, nil, stamps, fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	value := new(T)
+	d := &lenientDecoder{opts: lenientOptions{layouts: lenientTimeLayouts, location: time.Local, epochUnit: time.Millisecond}}
+	for _, opt := range l.opts.lenient {
+		opt(&d.opts)
+	}
+	if d.opts.epochUnit <= 0 {
+		d.opts.epochUnit = time.Millisecond
+	}
+	if err := d.decode(nil, tree, reflect.ValueOf(value).Elem(), false); err != nil {
+		return nil, nil, stamps, fmt.Errorf("decode config: %w", err)
+	}
+	return value, tree, stamps, nil
+}
+
+// readConfigFile 读取并解析一层配置文件，可选文件不存在时返回 nil
+func readConfigFile(f configFile) (interface{}, error) {
+	format := DetectFormat(f.path)
+	if format == UnknownFormat {
+		return nil, fmt.Errorf("unsupported file format: %s", f.path)
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if f.optional && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	js, err := ToJSONFrom(data, format)
+	if err != nil {
+		if format == JSONFormat {
+			return nil, annotateJSONError(data, f.path, err)
+		}
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	layer, err := decodeOrderedBytes(js, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	if layer == nil {
+		return nil, nil
+	}
+	if _, ok := layer.(*JSONObject); !ok {
+		return nil, fmt.Errorf("%s: config root must be an object, got %s", f.path, jsonTypeName(layer))
+	}
+	return layer, nil
+}
+
+// applyEnv 将带前缀的环境变量写入配置树，变量名按名称排序后依次处理
+func (l *ConfigLoader[T]) applyEnv(tree interface{}, t reflect.Type) error {
+	prefix := l.opts.envPrefix + "_"
+	var names []string
+	values := map[string]string{}
+	for _, kv := range l.opts.environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		if _, seen := values[name]; !seen {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		segments := strings.Split(name[len(prefix):], l.opts.envSeparator)
+		if err := setEnvValue(tree, t, segments, parseConfigText(values[name])); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setEnvValue 按环境变量名的各段在配置树中定位并写入值
+// 每一段不区分大小写、忽略下划线地匹配已有的键或结构体字段的 JSON 名称，都不匹配时使用小写形式；
+// 已有数组中的数字段表示下标，只能替换已有的元素
+func setEnvValue(tree interface{}, t reflect.Type, segments []string, value interface{}) error {
+	node := tree
+	for i, seg := range segments {
+		if seg == "" {
+			return fmt.Errorf("empty path segment")
+		}
+		last := i == len(segments)-1
+		if t != nil {
+			t = derefType(t)
+		}
+		if arr, ok := node.([]interface{}); ok {
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(arr) {
+				return fmt.Errorf("array index %q out of range", seg)
+			}
+			if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+			if last {
+				arr[idx] = value
+				return nil
+			}
+			if !isObject(arr[idx]) {
+				if _, isArr := arr[idx].([]interface{}); !isArr {
+					arr[idx] = NewJSONObject()
+				}
+			}
+			node = arr[idx]
+			continue
+		}
+
+		key, fieldType := resolveConfigKey(node, t, seg)
+		t = fieldType
+		if last {
+			objectSet(node, key, value)
+			return nil
+		}
+		child, _ := objectGet(node, key)
+		if !isObject(child) {
+			if _, isArr := child.([]interface{}); !isArr {
+				child = NewJSONObject()
+				objectSet(node, key, child)
+			}
+		}
+		node = child
+	}
+	return nil
+}
+
+// resolveConfigKey 为环境变量的一段找到对应的键，并返回该键对应的字段类型（未知时为 nil）
+func resolveConfigKey(node interface{}, t reflect.Type, seg string) (string, reflect.Type) {
+	want := normalizeConfigName(seg)
+	var fieldType reflect.Type
+	if t != nil {
+		switch t.Kind() {
+		case reflect.Struct:
+			for _, f := range cachedStructFields(t) {
+				if normalizeConfigName(f.name) == want {
+					fieldType = f.typ
+					for _, k := range objectKeys(node) {
+						if k == f.name {
+							return k, fieldType
+						}
+					}
+					return f.name, fieldType
+				}
+			}
+		case reflect.Map:
+			fieldType = t.Elem()
+		}
+	}
+	for _, k := range objectKeys(node) {
+		if normalizeConfigName(k) == want {
+			return k, fieldType
+		}
+	}
+	return strings.ToLower(seg), fieldType
+}
+
+// normalizeConfigName 返回用于匹配的名称：转为小写并去掉下划线和连字符
+func normalizeConfigName(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "_", "")
+	return strings.ReplaceAll(s, "-", "")
+}
+
+// parseConfigText 解析环境变量或默认值的文本，形如 JSON 数组或对象的文本按 JSON 解析，其余保留为字符串
+func parseConfigText(s string) interface{} {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		if v, err := decodeOrderedBytes([]byte(trimmed), true); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// configFieldTags 读取字段的默认值和是否必填
+func configFieldTags(f fieldInfo) (def string, hasDefault bool, required bool) {
+	def, hasDefault = f.field.Tag.Lookup("default")
+	if r, ok := f.field.Tag.Lookup("required"); ok {
+		required, _ = strconv.ParseBool(r)
+	}
+	for _, part := range splitSchemaTag(f.field.Tag.Get("jsonschema")) {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.TrimSpace(key) {
+		case "required":
+			required = true
+		case "default":
+			if !hasDefault {
+				def, hasDefault = value, true
+			}
+		}
+	}
+	return def, hasDefault, required
+}
+
+// isConfigSection 判断字段类型是否为需要递归处理默认值和必填项的嵌套结构体
+func isConfigSection(t reflect.Type) bool {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || t == timeType || t == decimalType {
+		return false
+	}
+	p := reflect.PtrTo(t)
+	return !p.Implements(jsonUnmarshalerType) && !p.Implements(textUnmarshalerType)
+}
+
+// collectConfigDefaults 将结构体字段的默认值写入 obj，嵌套结构体递归处理
+func collectConfigDefaults(t reflect.Type, obj *JSONObject) {
+	for _, f := range cachedStructFields(t) {
+		def, hasDefault, _ := configFieldTags(f)
+		if hasDefault {
+			obj.Set(f.name, parseConfigText(def))
+			continue
+		}
+		if isConfigSection(f.typ) && f.typ.Kind() != reflect.Ptr {
+			child := NewJSONObject()
+			collectConfigDefaults(f.typ, child)
+			if child.Len() > 0 {
+				obj.Set(f.name, child)
+			}
+		}
+	}
+}
+
+// checkConfigRequired 检查必填字段是否存在且不为 null 或空字符串，缺失的路径追加到 missing
+// 指针类型的嵌套结构体只在配置中出现时才检查
+func checkConfigRequired(t reflect.Type, node interface{}, keys []interface{}, missing *[]string) {
+	for _, f := range cachedStructFields(t) {
+		path := append(append([]interface{}{}, keys...), f.name)
+		v, ok := objectGet(node, f.name)
+		if _, _, required := configFieldTags(f); required {
+			if s, isStr := v.(string); !ok || v == nil || (isStr && s == "") {
+				*missing = append(*missing, dottedPath(path))
+				continue
+			}
+		}
+		if !isConfigSection(f.typ) {
+			continue
+		}
+		if f.typ.Kind() == reflect.Ptr && (!ok || v == nil) {
+			continue
+		}
+		checkConfigRequired(derefType(f.typ), v, path, missing)
+	}
+}
@@ -0,0 +1,402 @@
+package jsonutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// csvOptions 保存 CSV 转换的选项
+type csvOptions struct {
+	comma       rune
+	useCRLF     bool
+	columns     []string
+	sortHeader  bool
+	rawStrings  bool
+	emptyAsNull bool
+	flatten     flattenOptions
+}
+
+// CSVOption 是设置 CSV 转换选项的函数类型
+type CSVOption func(*csvOptions)
+
+// WithCSVComma 设置字段分隔符，默认为逗号，如 ';' 或 '\t'
+func WithCSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = comma
+	}
+}
+
+// WithCSVCRLF 使用 \r\n 作为行尾，便于 Excel 等工具打开
+func WithCSVCRLF() CSVOption {
+	return func(o *csvOptions) {
+		o.useCRLF = true
+	}
+}
+
+// WithCSVColumns 指定列及其顺序：导出时只输出这些列且无需推断表头；导入时表示 CSV 没有表头行
+func WithCSVColumns(columns ...string) CSVOption {
+	return func(o *csvOptions) {
+		o.columns = columns
+	}
+}
+
+// WithCSVSortedHeader 导出时推断出的表头按字典序排列，默认按各列首次出现的顺序
+func WithCSVSortedHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.sortHeader = true
+	}
+}
+
+// WithCSVStringValues 导入时不推断类型，所有单元格都作为字符串
+func WithCSVStringValues() CSVOption {
+	return func(o *csvOptions) {
+		o.rawStrings = true
+	}
+}
+
+// WithCSVEmptyAsNull 导入时空单元格解析为 null，默认省略对应的键
+func WithCSVEmptyAsNull() CSVOption {
+	return func(o *csvOptions) {
+		o.emptyAsNull = true
+	}
+}
+
+// WithCSVFlatten 设置展开和还原嵌套对象时使用的选项，见 Flatten
+func WithCSVFlatten(opts ...FlattenOption) CSVOption {
+	return func(o *csvOptions) {
+		o.flatten = newFlattenOptions(opts)
+	}
+}
+
+// newCSVOptions 创建默认选项并应用自定义选项
+func newCSVOptions(opts []CSVOption) csvOptions {
+	o := csvOptions{comma: ',', flatten: newFlattenOptions(nil)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// JSONToCSV 将对象数组转换为 CSV，嵌套的值按 Flatten 展开为 a.b[0].c 形式的列
+// 表头为所有记录中出现过的列，缺失的值和 null 输出为空单元格
+func JSONToCSV(jsonStr string, opts ...CSVOption) (string, error) {
+	var buf bytes.Buffer
+	if _, err := JSONArrayToCSV(strings.NewReader(jsonStr), &buf, opts...); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// CSVToJSON 将带表头的 CSV 转换为对象数组，列名按 Unflatten 还原为嵌套结构
+// 默认推断类型：true/false 为布尔值，合法的 JSON 数字为数字，{} 和 [] 为空对象和空数组，其余为字符串
+func CSVToJSON(csvStr string, opts ...CSVOption) (string, error) {
+	var buf bytes.Buffer
+	if _, err := CSVToJSONArray(strings.NewReader(csvStr), &buf, opts...); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// JSONArrayToCSV 将 r 中的对象数组流式转换为 CSV 写入 w，返回转换的记录数
+// 指定了 WithCSVColumns 时每次只在内存中保存一条记录；否则需要先推断表头：
+// r 实现了 io.Seeker 时读取两遍，不支持定位时会将全部记录缓存在内存中
+func JSONArrayToCSV(r io.Reader, w io.Writer, opts ...CSVOption) (int, error) {
+	o := newCSVOptions(opts)
+	if o.columns != nil {
+		return writeCSVRecords(r, w, o, o.columns)
+	}
+	if s, ok := r.(io.Seeker); ok {
+		if start, err := s.Seek(0, io.SeekCurrent); err == nil {
+			header := newCSVHeader()
+			if _, err := eachCSVRecord(r, o, func(flat *JSONObject) error {
+				header.add(flat)
+				return nil
+			}); err != nil {
+				return 0, err
+			}
+			if _, err := s.Seek(start, io.SeekStart); err != nil {
+				return 0, err
+			}
+			return writeCSVRecords(r, w, o, header.columns(o))
+		}
+	}
+	header := newCSVHeader()
+	var records []*JSONObject
+	if _, err := eachCSVRecord(r, o, func(flat *JSONObject) error {
+		header.add(flat)
+		records = append(records, flat)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	cw := newCSVWriter(w, o)
+	columns := header.columns(o)
+	if len(columns) > 0 {
+		if err := cw.Write(columns); err != nil {
+			return 0, err
+		}
+	}
+	row := make([]string, len(columns))
+	for i, flat := range records {
+		if err := writeCSVRow(cw, row, columns, flat); err != nil {
+			return i, err
+		}
+	}
+	cw.Flush()
+	return len(records), cw.Error()
+}
+
+// CSVToJSONArray 将 r 中的 CSV 流式转换为 JSON 对象数组写入 w，返回转换的记录数
+// 每次只在内存中保存一行；第一行为表头，除非通过 WithCSVColumns 指定了列名
+func CSVToJSONArray(r io.Reader, w io.Writer, opts ...CSVOption) (int, error) {
+	o := newCSVOptions(opts)
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header := o.columns
+	if header == nil {
+		record, err := cr.Read()
+		if err == io.EOF {
+			_, err = io.WriteString(w, "[]")
+			return 0, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		header = append([]string(nil), record...)
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	paths := make([][]objectPathToken, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		if seen[name] {
+			return 0, fmt.Errorf("csv: duplicate column %q", name)
+		}
+		seen[name] = true
+		tokens, err := parseFlatKey(name, o.flatten)
+		if err != nil {
+			return 0, fmt.Errorf("csv: column %q: %w", name, err)
+		}
+		paths[i] = tokens
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := bw.WriteByte('['); err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) > len(header) {
+			return count, fmt.Errorf("csv: line %d has %d fields, header has %d", line, len(record), len(header))
+		}
+		var root interface{}
+		for i, cell := range record {
+			if cell == "" && !o.emptyAsNull {
+				continue
+			}
+			if root, err = unflattenSet(root, paths[i], csvCellValue(cell, o), header[i]); err != nil {
+				return count, fmt.Errorf("csv: line %d: %w", line, err)
+			}
+		}
+		if root == nil {
+			root = NewJSONObject()
+		}
+		data, err := Marshal(root)
+		if err != nil {
+			return count, err
+		}
+		if count > 0 {
+			if err := bw.WriteByte(','); err != nil {
+				return count, err
+			}
+		}
+		if _, err := bw.Write(data); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := bw.WriteByte(']'); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// JSONFileToCSV 将对象数组文件转换为 CSV 文件
+func JSONFileToCSV(src, dst string, opts ...CSVOption) (int, error) {
+	return convertFile(src, dst, func(r io.Reader, w io.Writer) (int, error) {
+		return JSONArrayToCSV(r, w, opts...)
+	})
+}
+
+// CSVFileToJSON 将 CSV 文件转换为对象数组文件
+func CSVFileToJSON(src, dst string, opts ...CSVOption) (int, error) {
+	return convertFile(src, dst, func(r io.Reader, w io.Writer) (int, error) {
+		return CSVToJSONArray(r, w, opts...)
+	})
+}
+
+// csvHeader 按首次出现的顺序收集列名
+type csvHeader struct {
+	names []string
+	seen  map[string]bool
+}
+
+// newCSVHeader 创建空的列名集合
+func newCSVHeader() *csvHeader {
+	return &csvHeader{seen: make(map[string]bool)}
+}
+
+// add 加入一条展开后的记录中的列名
+func (h *csvHeader) add(flat *JSONObject) {
+	for _, k := range flat.keys {
+		if !h.seen[k] {
+			h.seen[k] = true
+			h.names = append(h.names, k)
+		}
+	}
+}
+
+// columns 返回最终的表头
+func (h *csvHeader) columns(o csvOptions) []string {
+	if o.sortHeader {
+		sort.Strings(h.names)
+	}
+	return h.names
+}
+
+// eachCSVRecord 逐个读取 r 中数组的元素并展开后传给 fn，返回读取的记录数
+func eachCSVRecord(r io.Reader, o csvOptions, fn func(flat *JSONObject) error) (int, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok != json.Delim('[') {
+		return 0, fmt.Errorf("expected JSON array, got %v", tok)
+	}
+	count := 0
+	for dec.More() {
+		v, err := decodeOrdered(dec)
+		if err != nil {
+			return count, fmt.Errorf("array element %d: %w", count, err)
+		}
+		if _, ok := v.(*JSONObject); !ok {
+			return count, fmt.Errorf("array element %d: expected object, got %s", count, jsonTypeName(v))
+		}
+		flat, err := flattenTree(v, o.flatten)
+		if err != nil {
+			return count, err
+		}
+		if err := fn(flat); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if _, err := dec.Token(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// writeCSVRecords 写出表头并逐条读取、写出记录
+func writeCSVRecords(r io.Reader, w io.Writer, o csvOptions, columns []string) (int, error) {
+	cw := newCSVWriter(w, o)
+	if len(columns) > 0 {
+		if err := cw.Write(columns); err != nil {
+			return 0, err
+		}
+	}
+	row := make([]string, len(columns))
+	n, err := eachCSVRecord(r, o, func(flat *JSONObject) error {
+		return writeCSVRow(cw, row, columns, flat)
+	})
+	if err != nil {
+		return n, err
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+// newCSVWriter 按选项创建 CSV 写入器
+func newCSVWriter(w io.Writer, o csvOptions) *csv.Writer {
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+	cw.UseCRLF = o.useCRLF
+	return cw
+}
+
+// writeCSVRow 按表头的顺序写出一条展开后的记录，row 为可复用的缓冲
+func writeCSVRow(cw *csv.Writer, row, columns []string, flat *JSONObject) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	for i, col := range columns {
+		cell, err := csvCellText(flat.values[col])
+		if err != nil {
+			return fmt.Errorf("column %q: %w", col, err)
+		}
+		row[i] = cell
+	}
+	return cw.Write(row)
+}
+
+// csvCellText 将展开后的叶子值转换为单元格文本
+func csvCellText(v interface{}) (string, error) {
+	switch c := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return c, nil
+	case bool:
+		return strconv.FormatBool(c), nil
+	case json.Number:
+		return c.String(), nil
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// csvCellValue 将单元格文本转换为 JSON 值
+func csvCellValue(cell string, o csvOptions) interface{} {
+	if cell == "" {
+		return nil
+	}
+	if o.rawStrings {
+		return cell
+	}
+	switch cell {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "{}":
+		return NewJSONObject()
+	case "[]":
+		return []interface{}{}
+	}
+	if isJSONNumberText(cell) {
+		return json.Number(cell)
+	}
+	return cell
+}
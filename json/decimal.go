@@ -0,0 +1,374 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalExponent 是 Decimal 允许的最大十进制指数，防止 1e1000000000 这类输入展开为巨大的文本
+const maxDecimalExponent = 10000
+
+// ErrDecimalRange 表示数字的指数超出了 Decimal 支持的范围
+var ErrDecimalRange = errors.New("decimal exponent out of range")
+
+// Decimal 是任意精度的十进制数，值为 coef × 10^exp
+// 保留原始的小数位数（如 12.50），编码为 JSON 时输出不含指数的数字，适用于金额和超过 2^53 的 ID；
+// 零值表示 0，所有运算都返回新值，可安全地复制和并发读取
+type Decimal struct {
+	coef *big.Int // nil 表示 0
+	exp  int
+}
+
+// ParseDecimal 解析十进制数，接受 JSON 数字语法以及前导的 + 号，如 "-12.50"、"1e-3"、"+7"
+func ParseDecimal(s string) (Decimal, error) {
+	text := s
+	neg := false
+	if text != "" && (text[0] == '+' || text[0] == '-') {
+		neg = text[0] == '-'
+		text = text[1:]
+	}
+	mantissa, expText := text, ""
+	i := strings.IndexAny(text, "eE")
+	if i >= 0 {
+		mantissa, expText = text[:i], text[i+1:]
+	}
+	intPart, fracPart := mantissa, ""
+	if dot := strings.IndexByte(mantissa, '.'); dot >= 0 {
+		intPart, fracPart = mantissa[:dot], mantissa[dot+1:]
+	}
+	if (intPart == "" && fracPart == "") || !isDecimalDigits(intPart) || !isDecimalDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	exp := -len(fracPart)
+	if i >= 0 {
+		e, err := strconv.Atoi(expText)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		if e > maxDecimalExponent || e < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrDecimalRange, s)
+		}
+		exp += e
+	}
+	coef, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	return Decimal{coef: coef, exp: exp}, nil
+}
+
+// MustParseDecimal 解析十进制数，失败时 panic，用于常量
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimalFromInt 由整数创建 Decimal
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{coef: big.NewInt(i)}
+}
+
+// NewDecimalFromFloat 由浮点数创建 Decimal，使用能精确还原该浮点数的最短十进制表示，NaN 和 Inf 返回错误
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("cannot convert %v to decimal", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// isDecimalDigits 判断字符串是否只包含十进制数字（可以为空）
+func isDecimalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// coefficient 返回系数，零值返回 0
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// pow10 返回 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// rescale 返回指数调整为 exp（不大于当前指数）后的系数
+func (d Decimal) rescale(exp int) *big.Int {
+	c := d.coefficient()
+	if exp >= d.exp {
+		return c
+	}
+	return new(big.Int).Mul(c, pow10(d.exp-exp))
+}
+
+// String 返回不含指数的十进制表示，保留原有的小数位数
+func (d Decimal) String() string {
+	c := d.coefficient()
+	if c.Sign() == 0 && d.exp >= 0 {
+		return "0"
+	}
+	digits := new(big.Int).Abs(c).String()
+	sign := ""
+	if c.Sign() < 0 {
+		sign = "-"
+	}
+	if d.exp >= 0 {
+		return sign + digits + strings.Repeat("0", d.exp)
+	}
+	scale := -d.exp
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	point := len(digits) - scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// normalize 去掉系数末尾的零，使数值相等的 Decimal 具有相同的表示
+func (d Decimal) normalize() Decimal {
+	c := d.coefficient()
+	if c.Sign() == 0 {
+		return Decimal{}
+	}
+	ten := big.NewInt(10)
+	exp := d.exp
+	q, r := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(c, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		c = new(big.Int).Set(q)
+		exp++
+	}
+	return Decimal{coef: c, exp: exp}
+}
+
+// Number 返回对应的 json.Number
+func (d Decimal) Number() json.Number {
+	return json.Number(d.String())
+}
+
+// Sign 返回 -1、0 或 1
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// IsZero 判断是否为 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp 比较两个数的大小，返回 -1、0 或 1；1.0 与 1.00 相等
+func (d Decimal) Cmp(o Decimal) int {
+	exp := d.exp
+	if o.exp < exp {
+		exp = o.exp
+	}
+	return d.rescale(exp).Cmp(o.rescale(exp))
+}
+
+// Equal 判断两个数的值是否相等
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Neg 返回相反数
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), exp: d.exp}
+}
+
+// Abs 返回绝对值
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.coefficient()), exp: d.exp}
+}
+
+// Add 返回 d + o，小数位数取两者中较多的一个
+func (d Decimal) Add(o Decimal) Decimal {
+	exp := d.exp
+	if o.exp < exp {
+		exp = o.exp
+	}
+	return Decimal{coef: new(big.Int).Add(d.rescale(exp), o.rescale(exp)), exp: exp}
+}
+
+// Sub 返回 d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul 返回 d × o，小数位数为两者之和
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), o.coefficient()), exp: d.exp + o.exp}
+}
+
+// Div 返回 d ÷ o 保留 places 位小数的结果，按四舍五入（远离零）舍入；o 为 0 时 panic
+func (d Decimal) Div(o Decimal, places int) Decimal {
+	if o.IsZero() {
+		panic("decimal division by zero")
+	}
+	// d/o = (cd × 10^(ed-eo+places+1)) / co × 10^-(places+1)，多算一位用于舍入
+	shift := d.exp - o.exp + places + 1
+	num := d.coefficient()
+	den := o.coefficient()
+	if shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+	q := new(big.Int).Quo(num, den)
+	return Decimal{coef: q, exp: -(places + 1)}.Round(places)
+}
+
+// Round 保留 places 位小数，按四舍五入（远离零）舍入；places 可为负数，表示舍入到十位、百位等
+func (d Decimal) Round(places int) Decimal {
+	if -d.exp <= places {
+		return d
+	}
+	divisor := pow10(-d.exp - places)
+	q, r := new(big.Int).QuoRem(d.coefficient(), divisor, new(big.Int))
+	// |r| × 2 >= divisor 时远离零进一
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(divisor) >= 0 {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{coef: q, exp: -places}
+}
+
+// Int64 返回整数值，带非零小数或超出 int64 范围时返回 false
+func (d Decimal) Int64() (int64, bool) {
+	c := d.coefficient()
+	if d.exp > 0 {
+		if d.exp > 19 && c.Sign() != 0 {
+			return 0, false
+		}
+		c = d.rescale(0)
+	} else if d.exp < 0 {
+		q, r := new(big.Int).QuoRem(c, pow10(-d.exp), new(big.Int))
+		if r.Sign() != 0 {
+			return 0, false
+		}
+		c = q
+	}
+	if !c.IsInt64() {
+		return 0, false
+	}
+	return c.Int64(), true
+}
+
+// Float64 返回最接近的 float64 值，超出范围时为 ±Inf
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.coefficient().String()+"e"+strconv.Itoa(d.exp), 64)
+	return f
+}
+
+// Rat 返回对应的有理数
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.coefficient())
+	if d.exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(pow10(d.exp)))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(pow10(-d.exp)))
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，输出不含指数的数字
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，接受数字和内容为数字的字符串，null 保持原值不变
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口，可用作 map 的键
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// toDecimal 将 JSON 数值转换为 Decimal，NaN、Inf 和超出范围的数返回 false
+func toDecimal(v interface{}) (Decimal, bool) {
+	switch n := v.(type) {
+	case Decimal:
+		return n, true
+	case *Decimal:
+		if n == nil {
+			return Decimal{}, false
+		}
+		return *n, true
+	case json.Number:
+		d, err := ParseDecimal(string(n))
+		return d, err == nil
+	case int:
+		return NewDecimalFromInt(int64(n)), true
+	case int8:
+		return NewDecimalFromInt(int64(n)), true
+	case int16:
+		return NewDecimalFromInt(int64(n)), true
+	case int32:
+		return NewDecimalFromInt(int64(n)), true
+	case int64:
+		return NewDecimalFromInt(n), true
+	case uint:
+		return Decimal{coef: new(big.Int).SetUint64(uint64(n))}, true
+	case uint8:
+		return NewDecimalFromInt(int64(n)), true
+	case uint16:
+		return NewDecimalFromInt(int64(n)), true
+	case uint32:
+		return NewDecimalFromInt(int64(n)), true
+	case uint64:
+		return Decimal{coef: new(big.Int).SetUint64(n)}, true
+	case float32:
+		if math.IsNaN(float64(n)) || math.IsInf(float64(n), 0) {
+			return Decimal{}, false
+		}
+		d, err := ParseDecimal(strconv.FormatFloat(float64(n), 'g', -1, 32))
+		return d, err == nil
+	case float64:
+		d, err := NewDecimalFromFloat(n)
+		return d, err == nil
+	}
+	return Decimal{}, false
+}
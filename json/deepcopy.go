@@ -0,0 +1,233 @@
+package jsonutil
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// deepCopyOptions 保存深拷贝的选项
+type deepCopyOptions struct {
+	unexported   bool
+	ignoreCloner bool
+}
+
+// DeepCopyOption 是设置深拷贝选项的函数类型
+type DeepCopyOption func(*deepCopyOptions)
+
+// WithUnexportedFields 同时深拷贝结构体的未导出字段，默认未导出字段按值浅拷贝
+func WithUnexportedFields() DeepCopyOption {
+	return func(o *deepCopyOptions) {
+		o.unexported = true
+	}
+}
+
+// WithoutCloneMethods 不调用类型自身的 Clone 方法，在 Clone 方法内部使用 DeepCopy 时需要设置
+func WithoutCloneMethods() DeepCopyOption {
+	return func(o *deepCopyOptions) {
+		o.ignoreCloner = true
+	}
+}
+
+// DeepCopy 基于反射深拷贝任意值
+// 支持指针、map、切片、数组、接口和结构体，共享和循环引用在副本中保持相同的结构；
+// time.Time 按值复制，chan 和 func 保持引用；
+// 类型实现了 Clone() T 方法（或指针实现了 Clone() *T）时优先调用该方法
+func DeepCopy[T any](src T, opts ...DeepCopyOption) T {
+	c := &copier{visited: make(map[visitKey]reflect.Value)}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	v := reflect.ValueOf(&src).Elem()
+	if !v.IsValid() {
+		return src
+	}
+	var dst T
+	reflect.ValueOf(&dst).Elem().Set(c.copy(v))
+	return dst
+}
+
+// visitKey 标识已经复制过的引用，用于处理共享和循环引用
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// copier 保存一次深拷贝过程中的状态
+type copier struct {
+	opts    deepCopyOptions
+	visited map[visitKey]reflect.Value
+}
+
+// pointerFreeTypes 缓存类型是否不包含任何引用，这类值直接按值复制即可
+var pointerFreeTypes sync.Map
+
+// isPointerFree 判断类型的值是否不包含任何指针、map、切片、接口等引用
+func isPointerFree(t reflect.Type) bool {
+	if v, ok := pointerFreeTypes.Load(t); ok {
+		return v.(bool)
+	}
+	free := computePointerFree(t, map[reflect.Type]bool{})
+	pointerFreeTypes.Store(t, free)
+	return free
+}
+
+// computePointerFree 递归判断类型是否不含引用，visiting 用于处理递归类型
+func computePointerFree(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	case reflect.Array:
+		return computePointerFree(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if !computePointerFree(t.Field(i).Type, visiting) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// addressable 返回可寻址的值，必要时复制到新分配的变量中
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	tmp := reflect.New(v.Type()).Elem()
+	tmp.Set(v)
+	return tmp
+}
+
+// accessible 返回可读写的未导出字段
+func accessible(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// cloneMethod 查找并调用类型自身的 Clone 方法
+func (c *copier) cloneMethod(v reflect.Value) (reflect.Value, bool) {
+	if c.opts.ignoreCloner {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || t.Kind() == reflect.Map || t.Kind() == reflect.Slice {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		return reflect.Value{}, false
+	}
+	if m, ok := t.MethodByName("Clone"); ok && isCloneMethod(m.Type, t) {
+		return v.Method(m.Index).Call(nil)[0], true
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() {
+		pt := reflect.PtrTo(t)
+		if m, ok := pt.MethodByName("Clone"); ok && isCloneMethod(m.Type, pt) {
+			out := v.Addr().Method(m.Index).Call(nil)[0]
+			if out.IsNil() {
+				return reflect.Zero(t), true
+			}
+			return out.Elem(), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isCloneMethod 判断方法签名是否为 func(T) T
+func isCloneMethod(mt, t reflect.Type) bool {
+	return mt.NumIn() == 1 && mt.NumOut() == 1 && mt.Out(0) == t
+}
+
+// copy 返回 src 的深拷贝
+func (c *copier) copy(src reflect.Value) reflect.Value {
+	t := src.Type()
+	if t == timeType || isPointerFree(t) {
+		return src
+	}
+	if out, ok := c.cloneMethod(src); ok {
+		return out
+	}
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return reflect.Zero(t)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: t}
+		if out, ok := c.visited[key]; ok {
+			return out
+		}
+		out := reflect.New(t.Elem())
+		c.visited[key] = out
+		out.Elem().Set(c.copy(src.Elem()))
+		return out
+	case reflect.Interface:
+		if src.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.New(t).Elem()
+		out.Set(c.copy(addressable(src.Elem())))
+		return out
+	case reflect.Map:
+		if src.IsNil() {
+			return reflect.Zero(t)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: t}
+		if out, ok := c.visited[key]; ok {
+			return out
+		}
+		out := reflect.MakeMapWithSize(t, src.Len())
+		c.visited[key] = out
+		iter := src.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(c.copy(addressable(iter.Key())), c.copy(addressable(iter.Value())))
+		}
+		return out
+	case reflect.Slice:
+		if src.IsNil() {
+			return reflect.Zero(t)
+		}
+		key := visitKey{ptr: src.Pointer(), typ: t, len: src.Len()}
+		if out, ok := c.visited[key]; ok {
+			return out
+		}
+		out := reflect.MakeSlice(t, src.Len(), src.Len())
+		c.visited[key] = out
+		for i := 0; i < src.Len(); i++ {
+			out.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		src = addressable(src)
+		for i := 0; i < src.Len(); i++ {
+			out.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return out
+	case reflect.Struct:
+		src = addressable(src)
+		out := reflect.New(t).Elem()
+		out.Set(src)
+		for i := 0; i < t.NumField(); i++ {
+			if isPointerFree(t.Field(i).Type) {
+				continue
+			}
+			if t.Field(i).IsExported() {
+				out.Field(i).Set(c.copy(src.Field(i)))
+			} else if c.opts.unexported {
+				accessible(out.Field(i)).Set(c.copy(accessible(src.Field(i))))
+			}
+		}
+		return out
+	}
+	// chan、func 和 unsafe.Pointer 保持引用
+	return src
+}
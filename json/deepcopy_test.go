@@ -0,0 +1,71 @@
+package jsonutil
+
+import (
+	"testing"
+	"time"
+)
+
+type benchAddress struct {
+	Street string   `json:"street"`
+	City   string   `json:"city"`
+	Tags   []string `json:"tags"`
+}
+
+type benchOrderItem struct {
+	SKU      string            `json:"sku"`
+	Quantity int               `json:"quantity"`
+	Price    float64           `json:"price"`
+	Attrs    map[string]string `json:"attrs"`
+}
+
+type benchOrder struct {
+	ID        int64                  `json:"id"`
+	Customer  string                 `json:"customer"`
+	CreatedAt time.Time              `json:"created_at"`
+	Shipping  *benchAddress          `json:"shipping"`
+	Billing   benchAddress           `json:"billing"`
+	Items     []benchOrderItem       `json:"items"`
+	Meta      map[string]interface{} `json:"meta"`
+}
+
+// newBenchOrder 构造一个包含嵌套结构体、指针、切片和 map 的典型对象
+func newBenchOrder() benchOrder {
+	order := benchOrder{
+		ID:        9007199254740993,
+		Customer:  "alice",
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Shipping:  &benchAddress{Street: "1 Main St", City: "Springfield", Tags: []string{"home", "default"}},
+		Billing:   benchAddress{Street: "2 Side Ave", City: "Shelbyville", Tags: []string{"office"}},
+		Meta:      map[string]interface{}{"source": "web", "coupon": "SPRING", "score": 4.5, "flags": []interface{}{"a", "b"}},
+	}
+	for i := 0; i < 20; i++ {
+		order.Items = append(order.Items, benchOrderItem{
+			SKU:      "SKU-" + string(rune('A'+i)),
+			Quantity: i + 1,
+			Price:    9.99 * float64(i+1),
+			Attrs:    map[string]string{"color": "red", "size": "M"},
+		})
+	}
+	return order
+}
+
+func BenchmarkDeepCopy(b *testing.B) {
+	src := newBenchOrder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = DeepCopy(src)
+	}
+}
+
+func BenchmarkDeepCopyByJSON(b *testing.B) {
+	src := newBenchOrder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var dst benchOrder
+		if err := DeepCopyByJSON(src, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,609 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxLCSCells 限制 LCS 动态规划表的大小，超出时退化为按位置配对
+const maxLCSCells = 1 << 24
+
+// ChangeType 表示差异的类型
+type ChangeType string
+
+const (
+	ChangeAdd     ChangeType = "add"
+	ChangeRemove  ChangeType = "remove"
+	ChangeReplace ChangeType = "replace"
+	ChangeMove    ChangeType = "move"
+)
+
+// Change 表示两个文档之间的一处差异
+// 变更按顺序排列，Path 和 From 是依次应用前面各变更后的位置，因此可直接转换为 JSON Patch
+type Change struct {
+	Type     ChangeType  // 差异类型
+	Path     JSONPointer // 变更位置
+	From     JSONPointer // 移动的源位置，仅 ChangeMove 有效
+	OldValue interface{} // 旧值，ChangeAdd 时为 nil
+	NewValue interface{} // 新值，ChangeRemove 时为 nil
+	// keys 和 source 是 Diff 使用的位置：删除的数组元素取旧数组中的下标，其余取新数组中的下标
+	keys   []interface{}
+	source []interface{}
+}
+
+// DiffResult 保存两个文档之间的结构化差异
+type DiffResult struct {
+	Changes []Change
+}
+
+// HasChanges 判断两个文档是否存在差异
+func (r *DiffResult) HasChanges() bool {
+	return len(r.Changes) > 0
+}
+
+// Patch 将差异转换为 RFC 6902 JSON Patch
+func (r *DiffResult) Patch() Patch {
+	patch := make(Patch, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		op := PatchOperation{Path: c.Path.String()}
+		switch c.Type {
+		case ChangeAdd:
+			op.Op, op.Value = PatchAdd, c.NewValue
+		case ChangeRemove:
+			op.Op = PatchRemove
+		case ChangeReplace:
+			op.Op, op.Value = PatchReplace, c.NewValue
+		case ChangeMove:
+			op.Op, op.From = PatchMove, c.From.String()
+		}
+		patch = append(patch, op)
+	}
+	return patch
+}
+
+// Text 以类似 unified diff 的格式输出差异报告
+func (r *DiffResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString("--- original\n+++ modified\n")
+	for _, c := range r.Changes {
+		switch c.Type {
+		case ChangeMove:
+			fmt.Fprintf(&sb, "@@ %s -> %s @@\n", displayPointer(c.From), displayPointer(c.Path))
+			writeDiffLines(&sb, " ", c.NewValue)
+		case ChangeAdd:
+			fmt.Fprintf(&sb, "@@ %s @@\n", displayPointer(c.Path))
+			writeDiffLines(&sb, "+", c.NewValue)
+		case ChangeRemove:
+			fmt.Fprintf(&sb, "@@ %s @@\n", displayPointer(c.Path))
+			writeDiffLines(&sb, "-", c.OldValue)
+		case ChangeReplace:
+			fmt.Fprintf(&sb, "@@ %s @@\n", displayPointer(c.Path))
+			writeDiffLines(&sb, "-", c.OldValue)
+			writeDiffLines(&sb, "+", c.NewValue)
+		}
+	}
+	return sb.String()
+}
+
+// String 实现 fmt.Stringer 接口
+func (r *DiffResult) String() string {
+	return r.Text()
+}
+
+// displayPointer 返回用于报告显示的指针
+func displayPointer(p JSONPointer) string {
+	if p.IsRoot() {
+		return "(root)"
+	}
+	return p.String()
+}
+
+// writeDiffLines 将值格式化后逐行加上前缀写入
+func writeDiffLines(sb *strings.Builder, sign string, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b = []byte(fmt.Sprint(v))
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		sb.WriteString(sign)
+		sb.WriteByte(' ')
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+}
+
+// diffOptions 保存结构化比较的选项
+type diffOptions struct {
+	identityKey string
+	ignore      [][]string
+	tolerance   float64
+}
+
+// DiffOption 是设置比较选项的函数类型
+type DiffOption func(*diffOptions)
+
+// WithArrayIdentityKey 按指定字段（如 "id"）匹配数组中的对象元素
+func WithArrayIdentityKey(key string) DiffOption {
+	return func(o *diffOptions) {
+		o.identityKey = key
+	}
+}
+
+// WithIgnorePaths 忽略指定路径上的差异
+// 路径可以是 JSON Pointer（如 /items/*/updatedAt）或点号分隔的形式（如 items.*.updatedAt），* 匹配任意一段
+func WithIgnorePaths(paths ...string) DiffOption {
+	return func(o *diffOptions) {
+		for _, path := range paths {
+			if strings.HasPrefix(path, "/") {
+				if p, err := ParseJSONPointer(path); err == nil {
+					o.ignore = append(o.ignore, p)
+				}
+				continue
+			}
+			o.ignore = append(o.ignore, strings.Split(path, "."))
+		}
+	}
+}
+
+// WithNumericTolerance 将差值不超过 tolerance 的两个数值视为相等
+func WithNumericTolerance(tolerance float64) DiffOption {
+	return func(o *diffOptions) {
+		o.tolerance = tolerance
+	}
+}
+
+// Compare 比较两个已解析的文档，返回结构化差异
+// 数组使用 LCS 比较，可识别插入、删除和移动；配置标识字段后按该字段匹配对象元素；
+// 对象成员的变更按 *JSONObject 的键顺序排列，map 则按键的字典序
+func Compare(a, b interface{}, opts ...DiffOption) *DiffResult {
+	d := &differ{}
+	for _, opt := range opts {
+		opt(&d.diffOptions)
+	}
+	d.diff(nil, a, b)
+	return &DiffResult{Changes: d.changes}
+}
+
+// CompareJSON 比较两个 JSON 字符串，返回结构化差异，变更按键在文档中的顺序排列
+func CompareJSON(json1, json2 string, opts ...DiffOption) (*DiffResult, error) {
+	a, err := DecodeOrdered([]byte(json1))
+	if err != nil {
+		return nil, err
+	}
+	b, err := DecodeOrdered([]byte(json2))
+	if err != nil {
+		return nil, err
+	}
+	return Compare(a, b, opts...), nil
+}
+
+// differ 保存比较过程中的状态
+type differ struct {
+	diffOptions
+	changes []Change
+}
+
+// appendKey 返回追加了键的新键序列
+func appendKey(keys []interface{}, key interface{}) []interface{} {
+	result := make([]interface{}, len(keys)+1)
+	copy(result, keys)
+	result[len(keys)] = key
+	return result
+}
+
+// pointerFromKeys 将键序列转换为 JSON Pointer
+func pointerFromKeys(keys []interface{}) JSONPointer {
+	p := make(JSONPointer, len(keys))
+	for i, k := range keys {
+		switch key := k.(type) {
+		case int:
+			p[i] = strconv.Itoa(key)
+		case string:
+			p[i] = key
+		}
+	}
+	return p
+}
+
+// dottedPath 将键序列转换为 Diff 使用的点号路径，如 a.b[0].c
+func dottedPath(keys []interface{}) string {
+	var sb strings.Builder
+	for _, k := range keys {
+		switch key := k.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", key)
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(key)
+		}
+	}
+	return sb.String()
+}
+
+// emit 记录一处变更
+func (d *differ) emit(typ ChangeType, keys []interface{}, from []interface{}, oldValue, newValue interface{}) {
+	c := Change{Type: typ, Path: pointerFromKeys(keys), OldValue: oldValue, NewValue: newValue, keys: keys}
+	if from != nil {
+		c.From = pointerFromKeys(from)
+	}
+	d.changes = append(d.changes, c)
+}
+
+// ignored 判断路径是否被忽略
+func (d *differ) ignored(keys []interface{}) bool {
+	for _, pattern := range d.ignore {
+		if len(pattern) != len(keys) {
+			continue
+		}
+		match := true
+		for i, token := range pattern {
+			if token == "*" {
+				continue
+			}
+			switch key := keys[i].(type) {
+			case int:
+				match = token == strconv.Itoa(key)
+			case string:
+				match = token == key
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// equal 按比较选项判断两个值是否相等
+func (d *differ) equal(keys []interface{}, a, b interface{}) bool {
+	if len(d.ignore) > 0 && d.ignored(keys) {
+		return true
+	}
+	if d.tolerance == 0 && len(d.ignore) == 0 {
+		return valuesEqual(a, b)
+	}
+	if isNumber(a) && isNumber(b) {
+		fa, _ := toFloat64(a)
+		fb, _ := toFloat64(b)
+		return math.Abs(fa-fb) <= d.tolerance
+	}
+	switch va := a.(type) {
+	case map[string]interface{}, *JSONObject:
+		if !isObject(b) {
+			return false
+		}
+		for _, k := range objectKeys(va) {
+			v, _ := objectGet(va, k)
+			w, ok := objectGet(b, k)
+			if !ok {
+				if !d.ignored(appendKey(keys, k)) {
+					return false
+				}
+				continue
+			}
+			if !d.equal(appendKey(keys, k), v, w) {
+				return false
+			}
+		}
+		for _, k := range objectKeys(b) {
+			if _, ok := objectGet(va, k); !ok && !d.ignored(appendKey(keys, k)) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !d.equal(appendKey(keys, i), va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return valuesEqual(a, b)
+}
+
+// diff 递归比较两个值
+func (d *differ) diff(keys []interface{}, a, b interface{}) {
+	if len(d.ignore) > 0 && d.ignored(keys) {
+		return
+	}
+	switch va := a.(type) {
+	case map[string]interface{}, *JSONObject:
+		if !isObject(b) {
+			break
+		}
+		for _, k := range objectKeys(va) {
+			if _, ok := objectGet(b, k); !ok {
+				child := appendKey(keys, k)
+				if len(d.ignore) == 0 || !d.ignored(child) {
+					v, _ := objectGet(va, k)
+					d.emit(ChangeRemove, child, nil, v, nil)
+				}
+			}
+		}
+		for _, k := range objectKeys(b) {
+			child := appendKey(keys, k)
+			w, _ := objectGet(b, k)
+			if v, ok := objectGet(va, k); ok {
+				d.diff(child, v, w)
+			} else if len(d.ignore) == 0 || !d.ignored(child) {
+				d.emit(ChangeAdd, child, nil, nil, w)
+			}
+		}
+		return
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		d.diffArray(keys, va, vb)
+		return
+	}
+	if !d.equal(keys, a, b) {
+		d.emit(ChangeReplace, keys, nil, a, b)
+	}
+}
+
+// arrayPair 表示新旧数组中相互对应的两个元素
+type arrayPair struct {
+	a, b int
+}
+
+// diffArray 比较两个数组，按删除、移动、插入和修改的顺序生成变更
+func (d *differ) diffArray(keys []interface{}, a, b []interface{}) {
+	pairs := d.matchArray(keys, a, b)
+	src := make([]int, len(b))
+	for i := range src {
+		src[i] = -1
+	}
+	referenced := make([]bool, len(a))
+	for _, p := range pairs {
+		src[p.b] = p.a
+		referenced[p.a] = true
+	}
+
+	// 从后往前删除未被引用的元素，使下标保持为旧数组中的位置
+	for i := len(a) - 1; i >= 0; i-- {
+		if !referenced[i] {
+			d.emit(ChangeRemove, appendKey(keys, i), nil, a[i], nil)
+		}
+	}
+
+	// 保留下来的元素中，位于最长递增子序列上的保持不动，其余的移动到其前驱之后
+	var working, target, targetIndex []int
+	for i := range a {
+		if referenced[i] {
+			working = append(working, i)
+		}
+	}
+	for j, ai := range src {
+		if ai >= 0 {
+			target = append(target, ai)
+			targetIndex = append(targetIndex, j)
+		}
+	}
+	stable := longestIncreasing(target)
+	for t, ai := range target {
+		if stable[ai] {
+			continue
+		}
+		from := indexOfInt(working, ai)
+		working = append(working[:from], working[from+1:]...)
+		to := 0
+		if t > 0 {
+			to = indexOfInt(working, target[t-1]) + 1
+		}
+		working = append(working[:to], append([]int{ai}, working[to:]...)...)
+		if from != to {
+			d.emit(ChangeMove, appendKey(keys, to), appendKey(keys, from), a[ai], a[ai])
+			c := &d.changes[len(d.changes)-1]
+			c.keys, c.source = appendKey(keys, targetIndex[t]), appendKey(keys, ai)
+		}
+	}
+
+	// 此时保留的元素已按新顺序排列，依次插入新元素并比较对应元素
+	for j, ai := range src {
+		if ai < 0 {
+			d.emit(ChangeAdd, appendKey(keys, j), nil, nil, b[j])
+			continue
+		}
+		d.diff(appendKey(keys, j), a[ai], b[j])
+	}
+}
+
+// matchArray 为新旧数组的元素建立对应关系
+// 依次使用标识字段、LCS、相等元素（移动）以及同一区间内按位置配对（修改）
+func (d *differ) matchArray(keys []interface{}, a, b []interface{}) []arrayPair {
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	var pairs []arrayPair
+	pair := func(i, j int) {
+		matchedA[i], matchedB[j] = true, true
+		pairs = append(pairs, arrayPair{i, j})
+	}
+
+	hasKey := func(v interface{}) (string, bool) {
+		if d.identityKey == "" {
+			return "", false
+		}
+		id, ok := objectGet(v, d.identityKey)
+		if !ok {
+			return "", false
+		}
+		s, err := json.Marshal(id)
+		if err != nil {
+			return "", false
+		}
+		// 按精确值比较数字，1 与 1.0 相同，而超过 2^53 的不同 ID 不会混淆
+		if n, ok := toDecimal(id); ok {
+			s = []byte(n.normalize().String())
+		} else if f, ok := toFloat64(id); ok {
+			s = []byte(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return string(s), true
+	}
+	if d.identityKey != "" {
+		byKey := make(map[string][]int)
+		for i, v := range a {
+			if k, ok := hasKey(v); ok {
+				byKey[k] = append(byKey[k], i)
+			}
+		}
+		for j, v := range b {
+			if k, ok := hasKey(v); ok && len(byKey[k]) > 0 {
+				pair(byKey[k][0], j)
+				byKey[k] = byKey[k][1:]
+			}
+		}
+	}
+
+	var ra, rb []int
+	for i := range a {
+		if !matchedA[i] {
+			ra = append(ra, i)
+		}
+	}
+	for j := range b {
+		if !matchedB[j] {
+			rb = append(rb, j)
+		}
+	}
+	eq := func(i, j int) bool {
+		return d.equal(appendKey(keys, j), a[i], b[j])
+	}
+	anchors := lcsPairs(ra, rb, eq)
+	for _, p := range anchors {
+		pair(p.a, p.b)
+	}
+
+	// 值相等但位置不同的元素视为移动
+	for _, j := range rb {
+		if matchedB[j] {
+			continue
+		}
+		for _, i := range ra {
+			if !matchedA[i] && eq(i, j) {
+				pair(i, j)
+				break
+			}
+		}
+	}
+
+	// 相邻锚点之间剩余的元素按位置配对，视为修改；带标识字段的元素不参与配对
+	bounds := append(append([]arrayPair{{-1, -1}}, anchors...), arrayPair{len(a), len(b)})
+	for g := 1; g < len(bounds); g++ {
+		var ga, gb []int
+		for i := bounds[g-1].a + 1; i < bounds[g].a; i++ {
+			if _, keyed := hasKey(a[i]); !matchedA[i] && !keyed {
+				ga = append(ga, i)
+			}
+		}
+		for j := bounds[g-1].b + 1; j < bounds[g].b; j++ {
+			if _, keyed := hasKey(b[j]); !matchedB[j] && !keyed {
+				gb = append(gb, j)
+			}
+		}
+		for k := 0; k < len(ga) && k < len(gb); k++ {
+			pair(ga[k], gb[k])
+		}
+	}
+	return pairs
+}
+
+// lcsPairs 计算两个下标序列在 eq 意义下的最长公共子序列
+func lcsPairs(ra, rb []int, eq func(i, j int) bool) []arrayPair {
+	var head, tail []arrayPair
+	for len(ra) > 0 && len(rb) > 0 && eq(ra[0], rb[0]) {
+		head = append(head, arrayPair{ra[0], rb[0]})
+		ra, rb = ra[1:], rb[1:]
+	}
+	for len(ra) > 0 && len(rb) > 0 && eq(ra[len(ra)-1], rb[len(rb)-1]) {
+		tail = append(tail, arrayPair{ra[len(ra)-1], rb[len(rb)-1]})
+		ra, rb = ra[:len(ra)-1], rb[:len(rb)-1]
+	}
+	n, m := len(ra), len(rb)
+	var middle []arrayPair
+	if n > 0 && m > 0 && (n+1)*(m+1) <= maxLCSCells {
+		table := make([]int32, (n+1)*(m+1))
+		at := func(i, j int) *int32 { return &table[i*(m+1)+j] }
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				switch {
+				case eq(ra[i], rb[j]):
+					*at(i, j) = *at(i+1, j+1) + 1
+				case *at(i+1, j) >= *at(i, j+1):
+					*at(i, j) = *at(i+1, j)
+				default:
+					*at(i, j) = *at(i, j+1)
+				}
+			}
+		}
+		for i, j := 0, 0; i < n && j < m; {
+			switch {
+			case eq(ra[i], rb[j]):
+				middle = append(middle, arrayPair{ra[i], rb[j]})
+				i++
+				j++
+			case *at(i+1, j) >= *at(i, j+1):
+				i++
+			default:
+				j++
+			}
+		}
+	}
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	return append(append(head, middle...), tail...)
+}
+
+// longestIncreasing 返回序列的最长递增子序列中包含的值
+func longestIncreasing(seq []int) map[int]bool {
+	var tails []int // tails[k] 是长度为 k+1 的递增子序列末尾元素在 seq 中的下标
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	result := make(map[int]bool, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		result[seq[i]] = true
+	}
+	return result
+}
+
+// indexOfInt 返回 v 在切片中的下标
+func indexOfInt(s []int, v int) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
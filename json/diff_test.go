@@ -0,0 +1,152 @@
+package jsonutil
+
+import (
+	"strings"
+	"testing"
+)
+
+// jsonEqual 判断两个 JSON 文本是否表示相同的值
+func jsonEqual(a, b string) bool {
+	va, err := decodeAny([]byte(a))
+	if err != nil {
+		return false
+	}
+	vb, err := decodeAny([]byte(b))
+	if err != nil {
+		return false
+	}
+	return valuesEqual(va, vb)
+}
+
+func TestCompareJSONPatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		opts []DiffOption
+	}{
+		{name: "equal", a: `{"a":[1,2],"b":{"c":null}}`, b: `{"b":{"c":null},"a":[1,2]}`},
+		{name: "object members", a: `{"a":1,"b":2,"c":{"d":3}}`, b: `{"a":1,"c":{"d":4,"e":5},"f":6}`},
+		{name: "type change", a: `{"a":[1]}`, b: `{"a":{"0":1}}`},
+		{name: "array insert and remove", a: `[1,2,3,4]`, b: `[0,1,3,4,5]`},
+		{name: "array move", a: `["a","b","c","d"]`, b: `["d","b","x","a"]`},
+		{name: "array reverse", a: `[1,2,3,4,5]`, b: `[5,4,3,2,1]`},
+		{name: "nested arrays", a: `{"m":[[1,2],[3]]}`, b: `{"m":[[3],[1,2,9]]}`},
+		{name: "remove and change at same index", a: `{"items":[{"id":1},{"id":2,"v":0}]}`, b: `{"items":[{"id":2,"v":1}]}`},
+		{
+			name: "identity key",
+			a:    `{"items":[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":3,"v":"c"}]}`,
+			b:    `{"items":[{"id":3,"v":"c"},{"id":1,"v":"z"},{"id":4,"v":"d"}]}`,
+			opts: []DiffOption{WithArrayIdentityKey("id")},
+		},
+		{name: "root replace", a: `1`, b: `"x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareJSON(tt.a, tt.b, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.HasChanges() == jsonEqual(tt.a, tt.b) {
+				t.Errorf("HasChanges() = %v", result.HasChanges())
+			}
+			got, err := result.Patch().ApplyJSON(tt.a)
+			if err != nil {
+				t.Fatalf("Patch().ApplyJSON() error = %v", err)
+			}
+			if !jsonEqual(got, tt.b) {
+				t.Errorf("patched = %s, want %s\nchanges:\n%s", got, tt.b, result)
+			}
+		})
+	}
+}
+
+func TestCompareJSONChanges(t *testing.T) {
+	result, err := CompareJSON(`{"a":[1,2,3],"b":1,"c":true}`, `{"a":[1,3,4],"b":2,"d":null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range result.Changes {
+		s := string(c.Type) + " " + c.Path.String()
+		if c.Type == ChangeMove {
+			s += " from " + c.From.String()
+		}
+		got = append(got, s)
+	}
+	want := []string{"remove /c", "remove /a/1", "add /a/2", "replace /b", "add /d"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+func TestCompareJSONOptions(t *testing.T) {
+	result, err := CompareJSON(
+		`{"items":[{"id":1,"updatedAt":"x","price":1.0}],"meta":{"ts":1}}`,
+		`{"items":[{"id":1,"updatedAt":"y","price":1.004}],"meta":{"ts":2}}`,
+		WithIgnorePaths("items.*.updatedAt", "/meta/ts"),
+		WithNumericTolerance(0.01),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.HasChanges() {
+		t.Errorf("HasChanges() = true, changes:\n%s", result)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		opts []DiffOption
+		want string
+	}{
+		{
+			name: "object members",
+			a:    `{"a":1,"b":{"c":2},"d":[1]}`,
+			b:    `{"a":1,"b":{"c":3},"e":"x"}`,
+			want: `{"b.c":{"new":3,"old":2},"d":{"new":null,"old":[1]},"e":{"new":"x","old":null}}`,
+		},
+		{
+			name: "remove before changed element",
+			a:    `{"items":[{"id":1},{"id":2,"v":0}]}`,
+			b:    `{"items":[{"id":2,"v":1}]}`,
+			opts: []DiffOption{WithArrayIdentityKey("id")},
+			want: `{"items[0]":{"new":null,"old":{"id":1}},"items[0].v":{"new":1,"old":0}}`,
+		},
+		{
+			name: "remove and add at same index",
+			a:    `{"items":[{"id":1},{"id":2}]}`,
+			b:    `{"items":[{"id":3}]}`,
+			opts: []DiffOption{WithArrayIdentityKey("id")},
+			want: `{"items[0]":{"new":{"id":3},"old":{"id":1}},"items[1]":{"new":null,"old":{"id":2}}}`,
+		},
+		{
+			name: "remove and add in scalar array",
+			a:    `[1,2,3]`,
+			b:    `[3,4]`,
+			want: `{"[0]":{"new":null,"old":1},"[1]":{"new":4,"old":2}}`,
+		},
+		{
+			name: "move uses original positions",
+			a:    `["a","b","c","d"]`,
+			b:    `["d","b","x","a"]`,
+			want: `{"[0]":{"from":"[3]","new":"d","old":"d"},"[2]":{"new":"x","old":"c"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := Diff(tt.a, tt.b, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ToJSON(diff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Diff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
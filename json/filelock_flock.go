@@ -0,0 +1,45 @@
+//go:build darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd
+
+package jsonutil
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockFile 打开 filename.lock 并用 flock 加锁
+func lockFile(filename string, exclusive bool) (*FileLock, error) {
+	path := filename + ".lock"
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, &os.PathError{Op: "flock", Path: path, Err: err}
+	}
+	return &FileLock{path: path, f: f, unlock: func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}}, nil
+}
+
+// syncDir 对目录执行 fsync，使重命名操作持久化
+func syncDir(dir string) error {
+	d, err := os.Open(filepath.Clean(dir))
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,38 @@
+//go:build !(darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd)
+
+package jsonutil
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileLocks 按锁文件的绝对路径保存进程内的读写锁
+var fileLocks sync.Map
+
+// lockFile 在不支持 flock 的平台上使用进程内的读写锁，并创建 filename.lock 以保持行为一致
+func lockFile(filename string, exclusive bool) (*FileLock, error) {
+	path := filename + ".lock"
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := fileLocks.LoadOrStore(abs, &sync.RWMutex{})
+	mu := v.(*sync.RWMutex)
+	if exclusive {
+		mu.Lock()
+		return &FileLock{path: path, f: f, unlock: mu.Unlock}, nil
+	}
+	mu.RLock()
+	return &FileLock{path: path, f: f, unlock: mu.RUnlock}, nil
+}
+
+// syncDir 在该平台上目录无法 fsync，直接返回
+func syncDir(dir string) error {
+	return nil
+}
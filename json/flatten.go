@@ -0,0 +1,295 @@
+package jsonutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxUnflattenIndex 是 Unflatten 允许的最大数组下标，防止恶意的键导致分配过大的数组
+const maxUnflattenIndex = 1 << 20
+
+// flattenOptions 保存展开和还原的选项
+type flattenOptions struct {
+	separator   string
+	dottedIndex bool
+}
+
+// FlattenOption 是设置展开和还原选项的函数类型
+type FlattenOption func(*flattenOptions)
+
+// WithFlattenSeparator 设置对象键之间的分隔符，默认为点号
+func WithFlattenSeparator(sep string) FlattenOption {
+	return func(o *flattenOptions) {
+		o.separator = sep
+	}
+}
+
+// WithDottedArrayIndex 数组下标也使用分隔符连接，如 a.b.0.c；还原时全为数字的段按数组下标处理
+func WithDottedArrayIndex() FlattenOption {
+	return func(o *flattenOptions) {
+		o.dottedIndex = true
+	}
+}
+
+// newFlattenOptions 创建默认选项并应用自定义选项
+func newFlattenOptions(opts []FlattenOption) flattenOptions {
+	o := flattenOptions{separator: "."}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.separator == "" {
+		o.separator = "."
+	}
+	return o
+}
+
+// Flatten 将嵌套的对象或数组展开为单层对象，键为 a.b[0].c 形式的路径，键按深度优先遍历的顺序排列
+// 空对象和空数组作为叶子值保留，以便 Unflatten 还原；键本身包含分隔符或方括号时无法还原
+func Flatten(v interface{}, opts ...FlattenOption) (*JSONObject, error) {
+	tree, err := toJSONTree(v)
+	if err != nil {
+		return nil, err
+	}
+	return flattenTree(tree, newFlattenOptions(opts))
+}
+
+// FlattenJSON 展开 JSON 字符串，见 Flatten
+func FlattenJSON(jsonStr string, opts ...FlattenOption) (string, error) {
+	tree, err := decodeOrderedBytes([]byte(jsonStr), true)
+	if err != nil {
+		return "", err
+	}
+	flat, err := flattenTree(tree, newFlattenOptions(opts))
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(flat)
+}
+
+// Unflatten 将 Flatten 展开的单层对象（*JSONObject 或 map[string]interface{}）还原为嵌套结构
+// 第一个键以数组下标开头时返回 []interface{}，否则返回 *JSONObject；数组中缺失的下标填充为 nil
+func Unflatten(flat interface{}, opts ...FlattenOption) (interface{}, error) {
+	obj, ok := asJSONObject(flat)
+	if !ok {
+		return nil, fmt.Errorf("unflatten: expected object, got %s", jsonTypeName(flat))
+	}
+	o := newFlattenOptions(opts)
+	var root interface{}
+	for _, key := range obj.keys {
+		tokens, err := parseFlatKey(key, o)
+		if err != nil {
+			return nil, err
+		}
+		if root, err = unflattenSet(root, tokens, obj.values[key], key); err != nil {
+			return nil, err
+		}
+	}
+	if root == nil {
+		return NewJSONObject(), nil
+	}
+	return root, nil
+}
+
+// UnflattenJSON 还原展开后的 JSON 字符串，见 Unflatten
+func UnflattenJSON(jsonStr string, opts ...FlattenOption) (string, error) {
+	flat, err := decodeOrderedBytes([]byte(jsonStr), true)
+	if err != nil {
+		return "", err
+	}
+	v, err := Unflatten(flat, opts...)
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(v)
+}
+
+// toJSONTree 将值转换为 *JSONObject、[]interface{} 组成的树，其他类型先编码为 JSON 再解析
+func toJSONTree(v interface{}) (interface{}, error) {
+	switch c := v.(type) {
+	case *JSONObject, map[string]interface{}, []interface{}:
+		return c, nil
+	case JSONArray:
+		return []interface{}(c), nil
+	case string:
+		return decodeOrderedBytes([]byte(c), true)
+	case []byte:
+		return decodeOrderedBytes(c, true)
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeOrderedBytes(data, true)
+}
+
+// flattenTree 展开已解析的树，顶层必须是对象或数组
+func flattenTree(tree interface{}, o flattenOptions) (*JSONObject, error) {
+	out := NewJSONObject()
+	switch tree.(type) {
+	case *JSONObject, map[string]interface{}, []interface{}, JSONArray:
+	default:
+		return nil, fmt.Errorf("flatten: expected object or array, got %s", jsonTypeName(tree))
+	}
+	flattenValue("", tree, o, out)
+	return out, nil
+}
+
+// flattenValue 将 v 展开写入 out，prefix 为 v 对应的键
+func flattenValue(prefix string, v interface{}, o flattenOptions, out *JSONObject) {
+	child := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + o.separator + key
+	}
+	switch c := v.(type) {
+	case *JSONObject:
+		if c.Len() == 0 && prefix != "" {
+			out.Set(prefix, NewJSONObject())
+		}
+		for _, k := range c.keys {
+			flattenValue(child(k), c.values[k], o, out)
+		}
+	case map[string]interface{}:
+		if len(c) == 0 && prefix != "" {
+			out.Set(prefix, NewJSONObject())
+		}
+		for _, k := range sortedKeys(c) {
+			flattenValue(child(k), c[k], o, out)
+		}
+	case []interface{}, JSONArray:
+		arr, _ := asJSONArray(c)
+		if len(arr) == 0 && prefix != "" {
+			out.Set(prefix, []interface{}{})
+		}
+		for i, item := range arr {
+			index := strconv.Itoa(i)
+			if o.dottedIndex {
+				flattenValue(child(index), item, o, out)
+			} else {
+				flattenValue(prefix+"["+index+"]", item, o, out)
+			}
+		}
+	default:
+		out.Set(prefix, v)
+	}
+}
+
+// parseFlatKey 将展开后的键拆分为路径段
+func parseFlatKey(key string, o flattenOptions) ([]objectPathToken, error) {
+	if key == "" {
+		return nil, fmt.Errorf("unflatten: empty key")
+	}
+	var tokens []objectPathToken
+	for _, part := range strings.Split(key, o.separator) {
+		name, rest := part, ""
+		if !o.dottedIndex {
+			if i := strings.IndexByte(part, '['); i >= 0 {
+				name, rest = part[:i], part[i:]
+			}
+		}
+		switch {
+		case name == "" && (rest == "" || len(tokens) > 0):
+			return nil, fmt.Errorf("unflatten: invalid key %q", key)
+		case name != "":
+			tokens = append(tokens, objectPathToken{name: name, index: o.dottedIndex && isDigits(name)})
+		}
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 || !isDigits(rest[1:end]) {
+				return nil, fmt.Errorf("unflatten: invalid key %q", key)
+			}
+			tokens = append(tokens, objectPathToken{name: rest[1:end], index: true})
+			rest = rest[end+1:]
+		}
+	}
+	return tokens, nil
+}
+
+// isDigits 判断字符串是否非空且只包含十进制数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// unflattenSet 在 cur 中按路径设置值并返回新的 cur，路径与已有的值冲突时返回错误
+func unflattenSet(cur interface{}, tokens []objectPathToken, value interface{}, key string) (interface{}, error) {
+	conflict := func() error {
+		return fmt.Errorf("unflatten: key %q conflicts with another key", key)
+	}
+	if len(tokens) == 0 {
+		if cur == nil {
+			return newLeafValue(value), nil
+		}
+		// null 不覆盖已有的值，空对象或空数组与同类型的已有值合并
+		if value == nil || (jsonTypeName(cur) == jsonTypeName(value) && isEmptyContainer(value)) {
+			return cur, nil
+		}
+		return nil, conflict()
+	}
+	tok := tokens[0]
+	if tok.index {
+		arr, ok := cur.([]interface{})
+		if cur != nil && !ok {
+			return nil, conflict()
+		}
+		i, err := strconv.Atoi(tok.name)
+		if err != nil || i > maxUnflattenIndex {
+			return nil, fmt.Errorf("unflatten: array index %s out of range in key %q", tok.name, key)
+		}
+		for len(arr) <= i {
+			arr = append(arr, nil)
+		}
+		if arr[i], err = unflattenSet(arr[i], tokens[1:], value, key); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	obj, ok := cur.(*JSONObject)
+	if cur == nil {
+		obj, ok = NewJSONObject(), true
+	}
+	if !ok {
+		return nil, conflict()
+	}
+	child, err := unflattenSet(obj.values[tok.name], tokens[1:], value, key)
+	if err != nil {
+		return nil, err
+	}
+	obj.Set(tok.name, child)
+	return obj, nil
+}
+
+// newLeafValue 复制叶子值，空对象和空数组替换为新的 *JSONObject 和 []interface{}，以便之后的键向其中写入
+func newLeafValue(v interface{}) interface{} {
+	if isEmptyContainer(v) {
+		if isObject(v) {
+			return NewJSONObject()
+		}
+		return []interface{}{}
+	}
+	return cloneValue(v)
+}
+
+// isEmptyContainer 判断值是否为空对象或空数组
+func isEmptyContainer(v interface{}) bool {
+	switch c := v.(type) {
+	case *JSONObject:
+		return c.Len() == 0
+	case map[string]interface{}:
+		return len(c) == 0
+	case []interface{}:
+		return len(c) == 0
+	case JSONArray:
+		return len(c) == 0
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format 表示配置文件的格式
+type Format int
+
+const (
+	// UnknownFormat 无法识别的格式
+	UnknownFormat Format = iota
+	// JSONFormat 标准 JSON（.json）
+	JSONFormat
+	// JSON5Format JSON5 或 JSONC（.json5、.jsonc）
+	JSON5Format
+	// YAMLFormat YAML 1.2（.yaml、.yml）
+	YAMLFormat
+	// TOMLFormat TOML 1.0（.toml）
+	TOMLFormat
+)
+
+// String 返回格式名称
+func (f Format) String() string {
+	switch f {
+	case JSONFormat:
+		return "json"
+	case JSON5Format:
+		return "json5"
+	case YAMLFormat:
+		return "yaml"
+	case TOMLFormat:
+		return "toml"
+	}
+	return "unknown"
+}
+
+// DetectFormat 根据文件扩展名（不区分大小写）识别格式，无法识别时返回 UnknownFormat
+func DetectFormat(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return JSONFormat
+	case ".json5", ".jsonc":
+		return JSON5Format
+	case ".yaml", ".yml":
+		return YAMLFormat
+	case ".toml":
+		return TOMLFormat
+	}
+	return UnknownFormat
+}
+
+// ParseFormat 按指定格式解析文本，返回与 FromJSON 解码到 interface{} 相同结构的值，数字为 float64
+func ParseFormat(data []byte, format Format) (interface{}, error) {
+	return parseFormat(data, format, false)
+}
+
+// ParseFormatExact 与 ParseFormat 相同，但数字为 json.Number，超过 2^53 的整数和高精度小数不会丢失精度
+func ParseFormatExact(data []byte, format Format) (interface{}, error) {
+	return parseFormat(data, format, true)
+}
+
+// parseFormat 按指定格式解析文本，exact 为 true 时数字保持为 json.Number
+func parseFormat(data []byte, format Format, exact bool) (interface{}, error) {
+	switch format {
+	case JSONFormat:
+		var v interface{}
+		decode := Unmarshal
+		if exact {
+			decode = UnmarshalExact
+		}
+		if err := decode(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case JSON5Format:
+		return parseJSON5Value(data, exact)
+	case YAMLFormat:
+		return parseYAMLValue(data, exact)
+	case TOMLFormat:
+		root, err := parseTOMLValue(data, exact)
+		if err != nil {
+			return nil, err
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// UnmarshalFormat 按指定格式将文本解析到 v 中，字段映射规则与 Unmarshal 相同（使用 json 标签）
+func UnmarshalFormat(data []byte, format Format, v interface{}) error {
+	switch format {
+	case JSONFormat:
+		return Unmarshal(data, v)
+	case JSON5Format:
+		return UnmarshalJSON5(data, v)
+	case YAMLFormat:
+		return UnmarshalYAML(data, v)
+	case TOMLFormat:
+		return UnmarshalTOML(data, v)
+	}
+	return fmt.Errorf("unsupported format: %s", format)
+}
+
+// UnmarshalFormatExact 与 UnmarshalFormat 相同，但 interface{} 中的数字解码为 json.Number 而不是 float64
+func UnmarshalFormatExact(data []byte, format Format, v interface{}) error {
+	switch format {
+	case JSONFormat:
+		return UnmarshalExact(data, v)
+	case JSON5Format, YAMLFormat, TOMLFormat:
+		if p, ok := v.(*interface{}); ok {
+			value, err := parseFormat(data, format, true)
+			if err != nil {
+				return err
+			}
+			*p = value
+			return nil
+		}
+		js, err := ToJSONFrom(data, format)
+		if err != nil {
+			return err
+		}
+		return unmarshalConverted(js, v, true)
+	}
+	return fmt.Errorf("unsupported format: %s", format)
+}
+
+// MarshalFormat 将对象编码为指定格式的文本，JSON 和 JSON5 输出两个空格缩进的 JSON
+func MarshalFormat(v interface{}, format Format) ([]byte, error) {
+	switch format {
+	case JSONFormat, JSON5Format:
+		return MarshalIndent(v, "", "  ")
+	case YAMLFormat:
+		return MarshalYAML(v)
+	case TOMLFormat:
+		return MarshalTOML(v)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// ToJSONFrom 将指定格式的文本转换为 JSON，之后可直接用于 MergeJSON、Diff、GetValueByPath 等函数
+func ToJSONFrom(data []byte, format Format) ([]byte, error) {
+	switch format {
+	case JSONFormat:
+		if _, err := decodeAny(data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	case JSON5Format:
+		return JSON5ToJSON(data)
+	case YAMLFormat:
+		return YAMLToJSON(data)
+	case TOMLFormat:
+		return TOMLToJSON(data)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// ConvertFormat 在两种格式之间转换，对象的键保持原有顺序
+func ConvertFormat(data []byte, from, to Format) ([]byte, error) {
+	js, err := ToJSONFrom(data, from)
+	if err != nil {
+		return nil, err
+	}
+	switch to {
+	case JSONFormat, JSON5Format:
+		v, err := decodeOrderedBytes(js, true)
+		if err != nil {
+			return nil, err
+		}
+		return MarshalIndent(v, "", "  ")
+	case YAMLFormat:
+		return JSONToYAML(js)
+	case TOMLFormat:
+		return JSONToTOML(js)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", to)
+}
+
+// FromFile 读取文件并解析到 v 中，格式由扩展名决定
+func FromFile(filename string, v interface{}) error {
+	format := DetectFormat(filename)
+	if format == UnknownFormat {
+		return fmt.Errorf("unsupported file format: %s", filename)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	err = UnmarshalFormat(data, format, v)
+	if format == JSONFormat {
+		return annotateJSONError(data, filename, err)
+	}
+	return err
+}
+
+// ToFile 将对象原子地保存为文件，格式由扩展名决定，写入方式和选项见 WriteFileAtomic
+func ToFile(v interface{}, filename string, opts ...WriteOption) error {
+	format := DetectFormat(filename)
+	if format == UnknownFormat {
+		return fmt.Errorf("unsupported file format: %s", filename)
+	}
+	data, err := MarshalFormat(v, format)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filename, data, opts...)
+}
+
+// toPlainTree 将解析得到的树转换为与 FromJSON 相同的结构：*JSONObject 转为 map，
+// exact 为 false 时 json.Number 转为 float64，为 true 时保持为 json.Number
+func toPlainTree(v interface{}, exact bool) interface{} {
+	switch c := v.(type) {
+	case *JSONObject:
+		m := make(map[string]interface{}, c.Len())
+		for _, k := range c.keys {
+			m[k] = toPlainTree(c.values[k], exact)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, item := range c {
+			m[k] = toPlainTree(item, exact)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(c))
+		for i, item := range c {
+			arr[i] = toPlainTree(item, exact)
+		}
+		return arr
+	case json.Number:
+		if !exact {
+			f, _ := c.Float64()
+			return f
+		}
+	case yamlNonFinite:
+		return c.value
+	}
+	return v
+}
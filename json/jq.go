@@ -0,0 +1,1576 @@
+package jsonutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JQSyntaxError 表示 jq 表达式的语法错误
+type JQSyntaxError struct {
+	Expr string // 原始表达式
+	Pos  int    // 出错位置（字节偏移）
+	Msg  string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *JQSyntaxError) Error() string {
+	return fmt.Sprintf("invalid jq expression %q at position %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// JQError 表示 jq 表达式运行时的错误，如类型不匹配或 error 函数抛出的值，可被 try 和 ? 捕获
+type JQError struct {
+	Value interface{} // 错误值，内置函数产生的错误为描述字符串
+}
+
+// Error 实现 error 接口
+func (e *JQError) Error() string {
+	if s, ok := e.Value.(string); ok {
+		return s
+	}
+	text, err := jqEncode(e.Value)
+	if err != nil {
+		return fmt.Sprint(e.Value)
+	}
+	return text + " (not a string)"
+}
+
+// jqErrorf 创建描述为格式化字符串的运行时错误
+func jqErrorf(format string, args ...interface{}) error {
+	return &JQError{Value: fmt.Sprintf(format, args...)}
+}
+
+// JQ 是编译后的 jq 表达式，可在多个文档上重复执行，并发使用是安全的
+// 支持 jq 语言的常用子集：管道、逗号、路径和切片、可选运算符 ?、对象和数组构造、字符串插值、
+// 算术和比较运算、and/or/not、//、if、try/catch、reduce、foreach、as 变量绑定以及常用内置函数；
+// 不支持赋值运算符、def 自定义函数、路径函数（path、del、getpath 等）和 @format 字符串
+type JQ struct {
+	expr string
+	root jqExpr
+}
+
+// jqOptions 保存执行 jq 表达式的选项
+type jqOptions struct {
+	vars *jqEnv
+}
+
+// JQOption 是设置 jq 执行选项的函数类型
+type JQOption func(*jqOptions)
+
+// WithJQVar 定义可在表达式中以 $name 引用的变量，value 为解码后的 JSON 值
+func WithJQVar(name string, value interface{}) JQOption {
+	return func(o *jqOptions) {
+		o.vars = o.vars.bind(strings.TrimPrefix(name, "$"), value)
+	}
+}
+
+// CompileJQ 编译 jq 表达式
+func CompileJQ(expr string) (*JQ, error) {
+	p := &jqParser{expr: expr}
+	root, err := p.parseProgram()
+	if err != nil {
+		return nil, err
+	}
+	return &JQ{expr: expr, root: root}, nil
+}
+
+// MustCompileJQ 编译 jq 表达式，失败时 panic
+func MustCompileJQ(expr string) *JQ {
+	q, err := CompileJQ(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String 返回原始表达式
+func (q *JQ) String() string {
+	return q.expr
+}
+
+// Run 以 data 为输入执行表达式，返回全部输出
+// data 应为解码后的 JSON 值（如 DecodeOrdered 的结果），其他 Go 值会先按 Marshal 的规则转换；
+// 构造的对象为保持键顺序的 *JSONObject，数字为 json.Number，运行时错误为 *JQError
+func (q *JQ) Run(data interface{}, opts ...JQOption) ([]interface{}, error) {
+	var o jqOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	input, err := jqInput(data)
+	if err != nil {
+		return nil, err
+	}
+	results := []interface{}{}
+	err = q.root.eval(o.vars, input, func(v interface{}) error {
+		results = append(results, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RunJSON 在 JSON 字符串上执行表达式
+func (q *JQ) RunJSON(jsonStr string, opts ...JQOption) ([]interface{}, error) {
+	data, err := DecodeOrdered([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	return q.Run(data, opts...)
+}
+
+// First 返回第一个输出，没有输出时返回 false；得到第一个输出后即停止求值
+func (q *JQ) First(data interface{}, opts ...JQOption) (interface{}, bool, error) {
+	var o jqOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	input, err := jqInput(data)
+	if err != nil {
+		return nil, false, err
+	}
+	var (
+		first interface{}
+		found bool
+	)
+	stop := &jqBreak{}
+	err = q.root.eval(o.vars, input, func(v interface{}) error {
+		first, found = v, true
+		return stop
+	})
+	if err != nil && err != stop {
+		return nil, false, err
+	}
+	return first, found, nil
+}
+
+// QueryJQ 使用 jq 表达式处理已解析的文档，返回全部输出
+func QueryJQ(data interface{}, expr string, opts ...JQOption) ([]interface{}, error) {
+	q, err := CompileJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.Run(data, opts...)
+}
+
+// QueryJQString 使用 jq 表达式处理 JSON 字符串，返回全部输出
+func QueryJQString(jsonStr, expr string, opts ...JQOption) ([]interface{}, error) {
+	q, err := CompileJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.RunJSON(jsonStr, opts...)
+}
+
+// jqInput 将输入转换为求值使用的 JSON 值
+func jqInput(v interface{}) (interface{}, error) {
+	switch c := v.(type) {
+	case nil, bool, string, *JSONObject, map[string]interface{}, []interface{}:
+		return v, nil
+	case JSONArray:
+		return []interface{}(c), nil
+	}
+	if isNumber(v) {
+		return v, nil
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeOrderedBytes(data, true)
+}
+
+// jqBreak 用于提前结束生成器（如 limit、first），不会被 try 捕获
+type jqBreak struct{}
+
+func (*jqBreak) Error() string {
+	return "jq: break"
+}
+
+// jqEnv 是变量绑定组成的链表，内层绑定遮蔽外层的同名变量
+type jqEnv struct {
+	name   string
+	value  interface{}
+	parent *jqEnv
+}
+
+func (e *jqEnv) bind(name string, value interface{}) *jqEnv {
+	return &jqEnv{name: name, value: value, parent: e}
+}
+
+func (e *jqEnv) lookup(name string) (interface{}, bool) {
+	for ; e != nil; e = e.parent {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	return nil, false
+}
+
+// jqExpr 是 jq 表达式的语法树节点，eval 对输入求值并依次将每个输出传给 emit
+type jqExpr interface {
+	eval(env *jqEnv, in interface{}, emit func(interface{}) error) error
+}
+
+// jqIdentity 表示 .
+type jqIdentity struct{}
+
+func (jqIdentity) eval(_ *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return emit(in)
+}
+
+// jqRecurse 表示 ..，先序输出输入及其所有后代
+type jqRecurse struct{}
+
+func (jqRecurse) eval(_ *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return jqWalk(in, emit)
+}
+
+// jqWalk 先序遍历值及其所有后代
+func jqWalk(v interface{}, emit func(interface{}) error) error {
+	if err := emit(v); err != nil {
+		return err
+	}
+	switch c := v.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if err := jqWalk(item, emit); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}, *JSONObject:
+		for _, k := range objectKeys(c) {
+			item, _ := objectGet(c, k)
+			if err := jqWalk(item, emit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jqLiteral 表示常量
+type jqLiteral struct {
+	value interface{}
+}
+
+func (e jqLiteral) eval(_ *jqEnv, _ interface{}, emit func(interface{}) error) error {
+	return emit(e.value)
+}
+
+// jqVar 表示变量引用 $name
+type jqVar struct {
+	name string
+}
+
+func (e jqVar) eval(env *jqEnv, _ interface{}, emit func(interface{}) error) error {
+	v, ok := env.lookup(e.name)
+	if !ok {
+		return jqErrorf("$%s is not defined", e.name)
+	}
+	return emit(v)
+}
+
+// jqIndex 表示 .foo、.[expr] 等取值，index 以原始输入求值
+type jqIndex struct {
+	target jqExpr
+	index  jqExpr
+}
+
+func (e *jqIndex) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.target.eval(env, in, func(t interface{}) error {
+		return e.index.eval(env, in, func(i interface{}) error {
+			v, err := jqIndexValue(t, i)
+			if err != nil {
+				return err
+			}
+			return emit(v)
+		})
+	})
+}
+
+// jqSlice 表示 .[from:to]，省略的边界为 nil
+type jqSlice struct {
+	target   jqExpr
+	from, to jqExpr
+}
+
+func (e *jqSlice) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	bound := func(b jqExpr, fn func(interface{}) error) error {
+		if b == nil {
+			return fn(nil)
+		}
+		return b.eval(env, in, fn)
+	}
+	return e.target.eval(env, in, func(t interface{}) error {
+		return bound(e.to, func(to interface{}) error {
+			return bound(e.from, func(from interface{}) error {
+				v, err := jqSliceValue(t, from, to)
+				if err != nil {
+					return err
+				}
+				return emit(v)
+			})
+		})
+	})
+}
+
+// jqIterate 表示 .[]
+type jqIterate struct {
+	target jqExpr
+}
+
+func (e *jqIterate) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.target.eval(env, in, func(t interface{}) error {
+		return jqEach(t, emit)
+	})
+}
+
+// jqEach 依次输出数组的元素或对象的值
+func jqEach(v interface{}, emit func(interface{}) error) error {
+	switch c := v.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}, *JSONObject:
+		for _, k := range objectKeys(c) {
+			item, _ := objectGet(c, k)
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return jqErrorf("cannot iterate over %s", jqDescribe(v))
+}
+
+// jqTry 表示 try body catch handler，handler 为 nil 时忽略错误（即后缀 ?）
+type jqTry struct {
+	body    jqExpr
+	handler jqExpr
+}
+
+func (e *jqTry) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	// 区分 body 自身的错误和下游通过 emit 返回的错误，后者不应被捕获
+	var downstream error
+	err := e.body.eval(env, in, func(v interface{}) error {
+		if err := emit(v); err != nil {
+			downstream = err
+			return err
+		}
+		return nil
+	})
+	if err == nil || err == downstream {
+		return err
+	}
+	var jqErr *JQError
+	if !errors.As(err, &jqErr) {
+		return err
+	}
+	if e.handler == nil {
+		return nil
+	}
+	return e.handler.eval(env, jqErr.Value, emit)
+}
+
+// jqPipe 表示 left | right
+type jqPipe struct {
+	left, right jqExpr
+}
+
+func (e *jqPipe) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.left.eval(env, in, func(v interface{}) error {
+		return e.right.eval(env, v, emit)
+	})
+}
+
+// jqComma 表示 left, right
+type jqComma struct {
+	left, right jqExpr
+}
+
+func (e *jqComma) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	if err := e.left.eval(env, in, emit); err != nil {
+		return err
+	}
+	return e.right.eval(env, in, emit)
+}
+
+// jqNeg 表示一元负号
+type jqNeg struct {
+	body jqExpr
+}
+
+func (e *jqNeg) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.body.eval(env, in, func(v interface{}) error {
+		if !isNumber(v) {
+			return jqErrorf("%s cannot be negated", jqDescribe(v))
+		}
+		r, err := jqArith("-", jqNumberZero, v)
+		if err != nil {
+			return err
+		}
+		return emit(r)
+	})
+}
+
+// jqBinary 表示算术和比较运算，与 jq 相同，右操作数的输出在外层循环
+type jqBinary struct {
+	op          string
+	left, right jqExpr
+}
+
+func (e *jqBinary) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.right.eval(env, in, func(r interface{}) error {
+		return e.left.eval(env, in, func(l interface{}) error {
+			v, err := jqApply(e.op, l, r)
+			if err != nil {
+				return err
+			}
+			return emit(v)
+		})
+	})
+}
+
+// jqApply 计算二元运算
+func jqApply(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return jqCompare(l, r) == 0, nil
+	case "!=":
+		return jqCompare(l, r) != 0, nil
+	case "<":
+		return jqCompare(l, r) < 0, nil
+	case "<=":
+		return jqCompare(l, r) <= 0, nil
+	case ">":
+		return jqCompare(l, r) > 0, nil
+	case ">=":
+		return jqCompare(l, r) >= 0, nil
+	}
+	return jqArith(op, l, r)
+}
+
+// jqLogic 表示 and 和 or，右操作数只在需要时求值
+type jqLogic struct {
+	and         bool
+	left, right jqExpr
+}
+
+func (e *jqLogic) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.left.eval(env, in, func(l interface{}) error {
+		if jqTruthy(l) != e.and {
+			return emit(!e.and)
+		}
+		return e.right.eval(env, in, func(r interface{}) error {
+			return emit(jqTruthy(r))
+		})
+	})
+}
+
+// jqAlternative 表示 left // right：输出 left 中不为 false 和 null 的值，没有这样的值时输出 right
+type jqAlternative struct {
+	left, right jqExpr
+}
+
+func (e *jqAlternative) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	var values []interface{}
+	err := e.left.eval(env, in, func(v interface{}) error {
+		if jqTruthy(v) {
+			values = append(values, v)
+		}
+		return nil
+	})
+	var jqErr *JQError
+	if err != nil && !errors.As(err, &jqErr) {
+		return err
+	}
+	if len(values) == 0 {
+		return e.right.eval(env, in, emit)
+	}
+	for _, v := range values {
+		if err := emit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jqArray 表示 [body]，收集 body 的全部输出
+type jqArray struct {
+	body jqExpr // nil 表示 []
+}
+
+func (e *jqArray) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	arr := []interface{}{}
+	if e.body != nil {
+		if err := e.body.eval(env, in, func(v interface{}) error {
+			arr = append(arr, v)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return emit(arr)
+}
+
+// jqObjectEntry 是对象构造中的一个成员
+type jqObjectEntry struct {
+	key, value jqExpr
+}
+
+// jqObject 表示 {...}，键或值有多个输出时生成它们的笛卡尔积
+type jqObject struct {
+	entries []jqObjectEntry
+}
+
+func (e *jqObject) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.build(env, in, 0, NewJSONObject(), emit)
+}
+
+// build 依次求值第 i 个及之后的成员，obj 为已构造的部分
+func (e *jqObject) build(env *jqEnv, in interface{}, i int, obj *JSONObject, emit func(interface{}) error) error {
+	if i == len(e.entries) {
+		return emit(obj)
+	}
+	entry := e.entries[i]
+	return entry.key.eval(env, in, func(k interface{}) error {
+		key, ok := k.(string)
+		if !ok {
+			return jqErrorf("object keys must be strings, got %s", jqDescribe(k))
+		}
+		return entry.value.eval(env, in, func(v interface{}) error {
+			next := &JSONObject{keys: append([]string(nil), obj.keys...), values: make(map[string]interface{}, len(obj.values)+1)}
+			for k, item := range obj.values {
+				next.values[k] = item
+			}
+			next.Set(key, v)
+			return e.build(env, in, i+1, next, emit)
+		})
+	})
+}
+
+// jqStringPart 是字符串插值中的一段，expr 为 nil 时为字面文本
+type jqStringPart struct {
+	text string
+	expr jqExpr
+}
+
+// jqFormat 表示带 \(expr) 插值的字符串
+type jqFormat struct {
+	parts []jqStringPart
+}
+
+func (e *jqFormat) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.build(env, in, 0, "", emit)
+}
+
+func (e *jqFormat) build(env *jqEnv, in interface{}, i int, prefix string, emit func(interface{}) error) error {
+	if i == len(e.parts) {
+		return emit(prefix)
+	}
+	part := e.parts[i]
+	if part.expr == nil {
+		return e.build(env, in, i+1, prefix+part.text, emit)
+	}
+	return part.expr.eval(env, in, func(v interface{}) error {
+		s, err := jqToString(v)
+		if err != nil {
+			return err
+		}
+		return e.build(env, in, i+1, prefix+s, emit)
+	})
+}
+
+// jqBind 表示 source as $name | body
+type jqBind struct {
+	source jqExpr
+	name   string
+	body   jqExpr
+}
+
+func (e *jqBind) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.source.eval(env, in, func(v interface{}) error {
+		return e.body.eval(env.bind(e.name, v), in, emit)
+	})
+}
+
+// jqReduce 表示 reduce source as $name (init; update)
+type jqReduce struct {
+	source       jqExpr
+	name         string
+	init, update jqExpr
+}
+
+func (e *jqReduce) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.init.eval(env, in, func(acc interface{}) error {
+		err := e.source.eval(env, in, func(v interface{}) error {
+			// 与 jq 相同，update 有多个输出时取最后一个，没有输出时结果为 null
+			var last interface{}
+			err := e.update.eval(env.bind(e.name, v), acc, func(r interface{}) error {
+				last = r
+				return nil
+			})
+			acc = last
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return emit(acc)
+	})
+}
+
+// jqForeach 表示 foreach source as $name (init; update; extract)
+type jqForeach struct {
+	source                jqExpr
+	name                  string
+	init, update, extract jqExpr // extract 为 nil 时输出每次更新后的状态
+}
+
+func (e *jqForeach) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.init.eval(env, in, func(acc interface{}) error {
+		return e.source.eval(env, in, func(v interface{}) error {
+			scope := env.bind(e.name, v)
+			return e.update.eval(scope, acc, func(r interface{}) error {
+				acc = r
+				if e.extract == nil {
+					return emit(r)
+				}
+				return e.extract.eval(scope, r, emit)
+			})
+		})
+	})
+}
+
+// jqIf 表示 if cond then body else other end，elif 展开为嵌套的 jqIf
+type jqIf struct {
+	cond, body, other jqExpr // other 为 nil 时输出输入本身
+}
+
+func (e *jqIf) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.cond.eval(env, in, func(c interface{}) error {
+		if jqTruthy(c) {
+			return e.body.eval(env, in, emit)
+		}
+		if e.other == nil {
+			return emit(in)
+		}
+		return e.other.eval(env, in, emit)
+	})
+}
+
+// jqCall 表示内置函数调用
+type jqCall struct {
+	fn   jqFunc
+	args []jqExpr
+}
+
+func (e *jqCall) eval(env *jqEnv, in interface{}, emit func(interface{}) error) error {
+	return e.fn(env, in, e.args, emit)
+}
+
+// jqKeywords 是不能用作函数名的关键字
+var jqKeywords = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "end": true, "as": true,
+	"reduce": true, "foreach": true, "try": true, "catch": true, "def": true,
+	"and": true, "or": true, "label": true, "import": true, "include": true,
+}
+
+// jqParser 是 jq 表达式的递归下降解析器
+type jqParser struct {
+	expr    string
+	pos     int
+	noComma bool // 解析对象成员的值时，逗号分隔成员而不是作为运算符
+}
+
+func (p *jqParser) errorf(format string, args ...interface{}) error {
+	return &JQSyntaxError{Expr: p.expr, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jqParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *jqParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *jqParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], s)
+}
+
+// skipBlank 跳过空白和 # 注释
+func (p *jqParser) skipBlank() {
+	for !p.eof() {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			for !p.eof() && p.expr[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// expect 跳过空白后要求下一个字符为 c
+func (p *jqParser) expect(c byte) error {
+	p.skipBlank()
+	if p.peek() != c {
+		if p.eof() {
+			return p.errorf("expected %q, got end of input", c)
+		}
+		return p.errorf("expected %q, got %q", c, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// isJQIdentStart 判断字节能否作为标识符的首字符
+func isJQIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isJQIdentChar 判断字节能否出现在标识符中
+func isJQIdentChar(c byte) bool {
+	return isJQIdentStart(c) || isDigit(c)
+}
+
+// peekIdent 返回当前位置的标识符，不移动位置
+func (p *jqParser) peekIdent() string {
+	if p.eof() || !isJQIdentStart(p.peek()) {
+		return ""
+	}
+	end := p.pos + 1
+	for end < len(p.expr) && isJQIdentChar(p.expr[end]) {
+		end++
+	}
+	return p.expr[p.pos:end]
+}
+
+// parseIdent 解析标识符
+func (p *jqParser) parseIdent() string {
+	name := p.peekIdent()
+	p.pos += len(name)
+	return name
+}
+
+// keyword 跳过空白后判断下一个词是否为 word，是则消耗它
+func (p *jqParser) keyword(word string) bool {
+	p.skipBlank()
+	if p.peekIdent() != word {
+		return false
+	}
+	p.pos += len(word)
+	return true
+}
+
+// expectKeyword 要求下一个词为 word
+func (p *jqParser) expectKeyword(word string) error {
+	if !p.keyword(word) {
+		return p.errorf("expected %q", word)
+	}
+	return nil
+}
+
+// parseProgram 解析完整的表达式
+func (p *jqParser) parseProgram() (jqExpr, error) {
+	p.skipBlank()
+	if p.eof() {
+		return jqIdentity{}, nil
+	}
+	e, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if !p.eof() {
+		return nil, p.errorf("unexpected character %q", p.peek())
+	}
+	return e, nil
+}
+
+// parseNested 解析括号等定界符内的完整表达式，其中的逗号总是运算符
+func (p *jqParser) parseNested() (jqExpr, error) {
+	saved := p.noComma
+	p.noComma = false
+	e, err := p.parsePipe()
+	p.noComma = saved
+	return e, err
+}
+
+// parsePipe 解析 a | b，优先级最低，右结合
+func (p *jqParser) parsePipe() (jqExpr, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.peek() == '|' {
+		if p.hasPrefix("|=") {
+			return nil, p.errorf("assignment operators are not supported")
+		}
+		p.pos++
+		right, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &jqPipe{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+// parseComma 解析 a, b
+func (p *jqParser) parseComma() (jqExpr, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipBlank()
+		if p.noComma || p.peek() != ',' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqComma{left: left, right: right}
+	}
+}
+
+// parseAlternative 解析 a // b，右结合
+func (p *jqParser) parseAlternative() (jqExpr, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.hasPrefix("//") {
+		if p.hasPrefix("//=") {
+			return nil, p.errorf("assignment operators are not supported")
+		}
+		p.pos += 2
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		return &jqAlternative{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+// parseOr 解析 a or b
+func (p *jqParser) parseOr() (jqExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqLogic{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd 解析 a and b
+func (p *jqParser) parseAnd() (jqExpr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqLogic{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// comparisonOp 返回当前位置的比较运算符
+func (p *jqParser) comparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// parseComparison 解析比较运算，比较运算不能连用
+func (p *jqParser) parseComparison() (jqExpr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	op := p.comparisonOp()
+	if op == "" {
+		if p.peek() == '=' {
+			return nil, p.errorf("assignment operators are not supported")
+		}
+		return left, nil
+	}
+	p.pos += len(op)
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.comparisonOp() != "" {
+		return nil, p.errorf("comparison operators cannot be chained")
+	}
+	return &jqBinary{op: op, left: left, right: right}, nil
+}
+
+// arithmeticOp 返回当前位置属于 ops 的运算符，遇到赋值运算符时返回错误
+func (p *jqParser) arithmeticOp(ops string) (string, error) {
+	c := p.peek()
+	if c == 0 || !strings.ContainsRune(ops, rune(c)) || p.hasPrefix("//") {
+		return "", nil
+	}
+	if p.pos+1 < len(p.expr) && p.expr[p.pos+1] == '=' {
+		return "", p.errorf("assignment operators are not supported")
+	}
+	return string(c), nil
+}
+
+// parseAdditive 解析 + 和 -
+func (p *jqParser) parseAdditive() (jqExpr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipBlank()
+		op, err := p.arithmeticOp("+-")
+		if err != nil {
+			return nil, err
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseMultiplicative 解析 *、/ 和 %
+func (p *jqParser) parseMultiplicative() (jqExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipBlank()
+		op, err := p.arithmeticOp("*/%")
+		if err != nil {
+			return nil, err
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &jqBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseUnary 解析一元负号
+func (p *jqParser) parseUnary() (jqExpr, error) {
+	p.skipBlank()
+	if p.peek() == '-' {
+		p.pos++
+		body, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &jqNeg{body: body}, nil
+	}
+	return p.parsePostfix(true)
+}
+
+// parsePostfix 解析项及其后的 .foo、[...]、? 后缀，allowBind 为 true 时处理 as $name | body
+func (p *jqParser) parsePostfix(allowBind bool) (jqExpr, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipBlank()
+		switch {
+		case p.peek() == '[':
+			if term, err = p.parseBracketSuffix(term); err != nil {
+				return nil, err
+			}
+		case p.peek() == '?':
+			p.pos++
+			term = &jqTry{body: term}
+		case p.peek() == '.' && p.pos+1 < len(p.expr) && p.expr[p.pos+1] == '[':
+			p.pos++
+			if term, err = p.parseBracketSuffix(term); err != nil {
+				return nil, err
+			}
+		case p.peek() == '.' && p.pos+1 < len(p.expr) && (isJQIdentStart(p.expr[p.pos+1]) || p.expr[p.pos+1] == '"'):
+			p.pos++
+			if term, err = p.parseField(term); err != nil {
+				return nil, err
+			}
+		default:
+			if allowBind && p.keyword("as") {
+				return p.parseBind(term)
+			}
+			return term, nil
+		}
+	}
+}
+
+// parseBind 解析 as $name | body，body 延伸到所在管道的末尾
+func (p *jqParser) parseBind(source jqExpr) (jqExpr, error) {
+	name, err := p.parseVarName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect('|'); err != nil {
+		return nil, err
+	}
+	body, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return &jqBind{source: source, name: name, body: body}, nil
+}
+
+// parseVarName 解析 $name 并返回不含 $ 的名称
+func (p *jqParser) parseVarName() (string, error) {
+	if err := p.expect('$'); err != nil {
+		return "", err
+	}
+	name := p.parseIdent()
+	if name == "" {
+		return "", p.errorf("expected variable name after '$'")
+	}
+	return name, nil
+}
+
+// parseField 解析 . 之后的字段名或带引号的字段名
+func (p *jqParser) parseField(target jqExpr) (jqExpr, error) {
+	if p.peek() == '"' {
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jqIndex{target: target, index: key}, nil
+	}
+	return &jqIndex{target: target, index: jqLiteral{value: p.parseIdent()}}, nil
+}
+
+// parseBracketSuffix 解析 []、[expr] 和 [from:to]
+func (p *jqParser) parseBracketSuffix(target jqExpr) (jqExpr, error) {
+	p.pos++ // '['
+	p.skipBlank()
+	if p.peek() == ']' {
+		p.pos++
+		return &jqIterate{target: target}, nil
+	}
+	var from jqExpr
+	if p.peek() != ':' {
+		e, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		from = e
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return &jqIndex{target: target, index: from}, nil
+		}
+	}
+	if err := p.expect(':'); err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	var to jqExpr
+	if p.peek() != ']' {
+		e, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		to = e
+	} else if from == nil {
+		return nil, p.errorf("slice needs at least one bound")
+	}
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	return &jqSlice{target: target, from: from, to: to}, nil
+}
+
+// parseTerm 解析不带后缀的项
+func (p *jqParser) parseTerm() (jqExpr, error) {
+	p.skipBlank()
+	if p.eof() {
+		return nil, p.errorf("unexpected end of input")
+	}
+	c := p.peek()
+	switch {
+	case p.hasPrefix(".."):
+		p.pos += 2
+		return jqRecurse{}, nil
+	case c == '.' && p.pos+1 < len(p.expr) && isDigit(p.expr[p.pos+1]):
+		return p.parseNumber()
+	case c == '.':
+		p.pos++
+		if p.peek() == '"' || isJQIdentStart(p.peek()) {
+			return p.parseField(jqIdentity{})
+		}
+		return jqIdentity{}, nil
+	case isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '(':
+		p.pos++
+		e, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case c == '[':
+		p.pos++
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return &jqArray{}, nil
+		}
+		body, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+		return &jqArray{body: body}, nil
+	case c == '{':
+		return p.parseObject()
+	case c == '$':
+		name, err := p.parseVarName()
+		if err != nil {
+			return nil, err
+		}
+		return jqVar{name: name}, nil
+	case c == '@':
+		return nil, p.errorf("format strings are not supported")
+	case isJQIdentStart(c):
+		return p.parseWord()
+	}
+	return nil, p.errorf("unexpected character %q", c)
+}
+
+// parseWord 解析以标识符开头的项：字面量、关键字结构或函数调用
+func (p *jqParser) parseWord() (jqExpr, error) {
+	start := p.pos
+	name := p.parseIdent()
+	switch name {
+	case "true":
+		return jqLiteral{value: true}, nil
+	case "false":
+		return jqLiteral{value: false}, nil
+	case "null":
+		return jqLiteral{value: nil}, nil
+	case "if":
+		return p.parseIf()
+	case "try":
+		return p.parseTry()
+	case "reduce", "foreach":
+		return p.parseFold(name == "foreach")
+	case "def":
+		p.pos = start
+		return nil, p.errorf("function definitions are not supported")
+	}
+	if jqKeywords[name] {
+		p.pos = start
+		return nil, p.errorf("unexpected keyword %q", name)
+	}
+	var args []jqExpr
+	p.skipBlank()
+	if p.peek() == '(' {
+		p.pos++
+		for {
+			arg, err := p.parseNested()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			p.skipBlank()
+			if p.peek() == ';' {
+				p.pos++
+				continue
+			}
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	fn, ok := jqBuiltins[name+"/"+strconv.Itoa(len(args))]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %s/%d", name, len(args))
+	}
+	return &jqCall{fn: fn, args: args}, nil
+}
+
+// parseIf 解析 if 之后的部分
+func (p *jqParser) parseIf() (jqExpr, error) {
+	cond, err := p.parseNested()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("then"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseNested()
+	if err != nil {
+		return nil, err
+	}
+	e := &jqIf{cond: cond, body: body}
+	switch {
+	case p.keyword("elif"):
+		if e.other, err = p.parseIf(); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case p.keyword("else"):
+		if e.other, err = p.parseNested(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("end"); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseTry 解析 try 之后的部分
+func (p *jqParser) parseTry() (jqExpr, error) {
+	body, err := p.parsePostfix(false)
+	if err != nil {
+		return nil, err
+	}
+	// 没有 catch 时忽略错误，与 ? 相同
+	e := &jqTry{body: body}
+	if p.keyword("catch") {
+		if e.handler, err = p.parsePostfix(false); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// parseFold 解析 reduce 或 foreach 之后的部分
+func (p *jqParser) parseFold(foreach bool) (jqExpr, error) {
+	source, err := p.parsePostfix(false)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("as"); err != nil {
+		return nil, err
+	}
+	name, err := p.parseVarName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var parts []jqExpr
+	for {
+		e, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, e)
+		p.skipBlank()
+		if p.peek() != ';' {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	if !foreach {
+		if len(parts) != 2 {
+			return nil, p.errorf("reduce expects 2 arguments, got %d", len(parts))
+		}
+		return &jqReduce{source: source, name: name, init: parts[0], update: parts[1]}, nil
+	}
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, p.errorf("foreach expects 2 or 3 arguments, got %d", len(parts))
+	}
+	e := &jqForeach{source: source, name: name, init: parts[0], update: parts[1]}
+	if len(parts) == 3 {
+		e.extract = parts[2]
+	}
+	return e, nil
+}
+
+// parseObject 解析对象构造
+func (p *jqParser) parseObject() (jqExpr, error) {
+	p.pos++ // '{'
+	obj := &jqObject{}
+	p.skipBlank()
+	if p.peek() == '}' {
+		p.pos++
+		return obj, nil
+	}
+	for {
+		entry, err := p.parseObjectEntry()
+		if err != nil {
+			return nil, err
+		}
+		obj.entries = append(obj.entries, entry)
+		p.skipBlank()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+}
+
+// parseObjectEntry 解析对象构造中的一个成员，支持 {a}、{$x}、{"a": v}、{(expr): v} 等形式
+func (p *jqParser) parseObjectEntry() (jqObjectEntry, error) {
+	p.skipBlank()
+	var entry jqObjectEntry
+	c := p.peek()
+	switch {
+	case c == '$':
+		name, err := p.parseVarName()
+		if err != nil {
+			return entry, err
+		}
+		entry.key, entry.value = jqLiteral{value: name}, jqVar{name: name}
+	case c == '"':
+		key, err := p.parseString()
+		if err != nil {
+			return entry, err
+		}
+		entry.key, entry.value = key, &jqIndex{target: jqIdentity{}, index: key}
+	case c == '(':
+		p.pos++
+		key, err := p.parseNested()
+		if err != nil {
+			return entry, err
+		}
+		if err := p.expect(')'); err != nil {
+			return entry, err
+		}
+		entry.key = key
+	case isJQIdentStart(c):
+		name := p.parseIdent()
+		entry.key, entry.value = jqLiteral{value: name}, &jqIndex{target: jqIdentity{}, index: jqLiteral{value: name}}
+	default:
+		if p.eof() {
+			return entry, p.errorf("unexpected end of input in object")
+		}
+		return entry, p.errorf("unexpected character %q in object key", c)
+	}
+	p.skipBlank()
+	if p.peek() != ':' {
+		if entry.value == nil {
+			return entry, p.errorf("expected ':' after computed object key")
+		}
+		return entry, nil
+	}
+	p.pos++
+	saved := p.noComma
+	p.noComma = true
+	value, err := p.parsePipe()
+	p.noComma = saved
+	if err != nil {
+		return entry, err
+	}
+	entry.value = value
+	return entry, nil
+}
+
+// parseNumber 解析数字字面量，如 12、1.5、.5、1e3
+func (p *jqParser) parseNumber() (jqExpr, error) {
+	start := p.pos
+	for !p.eof() && isDigit(p.peek()) {
+		p.pos++
+	}
+	if p.peek() == '.' {
+		p.pos++
+		for !p.eof() && isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("invalid number %q", p.expr[start:p.pos])
+		}
+		for !p.eof() && isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	d, err := ParseDecimal(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("%v", err)
+	}
+	return jqLiteral{value: jqDecimal(d)}, nil
+}
+
+// parseString 解析字符串字面量，包含 \(expr) 插值时返回 *jqFormat
+func (p *jqParser) parseString() (jqExpr, error) {
+	start := p.pos
+	p.pos++ // '"'
+	var (
+		parts []jqStringPart
+		sb    strings.Builder
+	)
+	for {
+		if p.eof() {
+			p.pos = start
+			return nil, p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case c == '"':
+			p.pos++
+			if len(parts) == 0 {
+				return jqLiteral{value: sb.String()}, nil
+			}
+			if sb.Len() > 0 {
+				parts = append(parts, jqStringPart{text: sb.String()})
+			}
+			return &jqFormat{parts: parts}, nil
+		case c == '\\':
+			if p.pos+1 < len(p.expr) && p.expr[p.pos+1] == '(' {
+				p.pos += 2
+				e, err := p.parseNested()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(')'); err != nil {
+					return nil, err
+				}
+				if sb.Len() > 0 {
+					parts = append(parts, jqStringPart{text: sb.String()})
+					sb.Reset()
+				}
+				parts = append(parts, jqStringPart{expr: e})
+				continue
+			}
+			r, err := p.parseEscape()
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteRune(r)
+		case c < 0x20:
+			return nil, p.errorf("control character in string")
+		default:
+			r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// parseEscape 解析 \ 开头的转义序列，\u 代理对合并为一个字符
+func (p *jqParser) parseEscape() (rune, error) {
+	p.pos++ // '\\'
+	if p.eof() {
+		return 0, p.errorf("unterminated escape sequence")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+		if utf16.IsSurrogate(r) && p.hasPrefix(`\u`) {
+			save := p.pos
+			p.pos += 2
+			low, err := p.parseHex4()
+			if err == nil {
+				if combined := utf16.DecodeRune(r, low); combined != utf8.RuneError {
+					return combined, nil
+				}
+			}
+			p.pos = save
+		}
+		return r, nil
+	}
+	p.pos -= 2
+	return 0, p.errorf("invalid escape sequence \\%c", c)
+}
+
+// parseHex4 解析四位十六进制数
+func (p *jqParser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.expr) {
+		return 0, p.errorf("invalid \\u escape")
+	}
+	for i := 0; i < 4; i++ {
+		if !isHexDigit(p.expr[p.pos+i]) {
+			return 0, p.errorf("invalid \\u escape")
+		}
+	}
+	n, _ := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+	p.pos += 4
+	return rune(n), nil
+}
@@ -0,0 +1,1475 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// jqMaxCoefBits 是算术结果保持精确十进制的最大系数位数，超出时退化为 float64，避免结果无限增长
+const jqMaxCoefBits = 256
+
+// jqNumberZero 是数字 0
+var jqNumberZero = json.Number("0")
+
+// jqFunc 是内置函数的实现，args 为未求值的参数表达式
+type jqFunc func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error
+
+// jqBuiltins 是以 名称/参数个数 为键的内置函数表
+var jqBuiltins = map[string]jqFunc{
+	"empty/0": func(_ *jqEnv, _ interface{}, _ []jqExpr, _ func(interface{}) error) error {
+		return nil
+	},
+	"not/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return !jqTruthy(in), nil
+	}),
+	"error/0": func(_ *jqEnv, in interface{}, _ []jqExpr, _ func(interface{}) error) error {
+		return &JQError{Value: in}
+	},
+	"error/1": func(env *jqEnv, in interface{}, args []jqExpr, _ func(interface{}) error) error {
+		return args[0].eval(env, in, func(msg interface{}) error {
+			return &JQError{Value: msg}
+		})
+	},
+	"length/0":         jqValueFunc(jqLength),
+	"utf8bytelength/0": jqValueFunc(jqUTF8ByteLength),
+	"type/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jsonTypeName(in), nil
+	}),
+	"keys/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqKeys(in, true)
+	}),
+	"keys_unsorted/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqKeys(in, false)
+	}),
+	"has/1":      jqValueFunc1(jqHas),
+	"contains/1": jqValueFunc1(jqContainsChecked),
+	"inside/1": jqValueFunc1(func(in, container interface{}) (interface{}, error) {
+		return jqContainsChecked(container, in)
+	}),
+	"add/0": jqValueFunc(jqAdd),
+	"any/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqAnyAll(nil, in, nil, true)
+	}),
+	"all/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqAnyAll(nil, in, nil, false)
+	}),
+	"any/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqAnyAll(env, in, args[0], true)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"all/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqAnyAll(env, in, args[0], false)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"range/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[0].eval(env, in, func(upto interface{}) error {
+			return jqRange(jqNumberZero, upto, emit)
+		})
+	},
+	"range/2": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[0].eval(env, in, func(from interface{}) error {
+			return args[1].eval(env, in, func(upto interface{}) error {
+				return jqRange(from, upto, emit)
+			})
+		})
+	},
+	"floor/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqRound(in, "floor")
+	}),
+	"ceil/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqRound(in, "ceil")
+	}),
+	"round/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqRound(in, "round")
+	}),
+	"abs/0": jqValueFunc(jqAbs),
+	"sqrt/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		f, ok := toFloat64(in)
+		if !ok {
+			return nil, jqErrorf("%s number required", jqDescribe(in))
+		}
+		return jqFloat(math.Sqrt(f)), nil
+	}),
+	"tostring/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqToString(in)
+	}),
+	"tojson/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqEncode(in)
+	}),
+	"fromjson/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		s, ok := in.(string)
+		if !ok {
+			return nil, jqErrorf("%s cannot be parsed as JSON, only strings can", jqDescribe(in))
+		}
+		v, err := decodeOrderedBytes([]byte(s), true)
+		if err != nil {
+			return nil, jqErrorf("%s (while parsing %q)", err, s)
+		}
+		return v, nil
+	}),
+	"tonumber/0":       jqValueFunc(jqToNumber),
+	"ascii_downcase/0": jqValueFunc(jqASCIICase(unicode.ToLower, "ascii_downcase")),
+	"ascii_upcase/0":   jqValueFunc(jqASCIICase(unicode.ToUpper, "ascii_upcase")),
+	"trim/0":           jqValueFunc(jqTrim(strings.TrimSpace, "trim")),
+	"ltrim/0": jqValueFunc(jqTrim(func(s string) string {
+		return strings.TrimLeftFunc(s, unicode.IsSpace)
+	}, "ltrim")),
+	"rtrim/0": jqValueFunc(jqTrim(func(s string) string {
+		return strings.TrimRightFunc(s, unicode.IsSpace)
+	}, "rtrim")),
+	"ltrimstr/1": jqValueFunc1(func(in, prefix interface{}) (interface{}, error) {
+		s, ok1 := in.(string)
+		p, ok2 := prefix.(string)
+		if !ok1 || !ok2 {
+			return in, nil
+		}
+		return strings.TrimPrefix(s, p), nil
+	}),
+	"rtrimstr/1": jqValueFunc1(func(in, suffix interface{}) (interface{}, error) {
+		s, ok1 := in.(string)
+		p, ok2 := suffix.(string)
+		if !ok1 || !ok2 {
+			return in, nil
+		}
+		return strings.TrimSuffix(s, p), nil
+	}),
+	"startswith/1": jqValueFunc1(func(in, prefix interface{}) (interface{}, error) {
+		s, ok1 := in.(string)
+		p, ok2 := prefix.(string)
+		if !ok1 || !ok2 {
+			return nil, jqErrorf("startswith() requires string inputs")
+		}
+		return strings.HasPrefix(s, p), nil
+	}),
+	"endswith/1": jqValueFunc1(func(in, suffix interface{}) (interface{}, error) {
+		s, ok1 := in.(string)
+		p, ok2 := suffix.(string)
+		if !ok1 || !ok2 {
+			return nil, jqErrorf("endswith() requires string inputs")
+		}
+		return strings.HasSuffix(s, p), nil
+	}),
+	"split/1": jqValueFunc1(jqSplit),
+	"join/1":  jqValueFunc1(jqJoin),
+	"test/1": jqValueFunc1(func(in, re interface{}) (interface{}, error) {
+		return jqTest(in, re, nil)
+	}),
+	"test/2": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[1].eval(env, in, func(flags interface{}) error {
+			return args[0].eval(env, in, func(re interface{}) error {
+				v, err := jqTest(in, re, flags)
+				if err != nil {
+					return err
+				}
+				return emit(v)
+			})
+		})
+	},
+	"sort/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqSortBy(nil, in, nil)
+	}),
+	"sort_by/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqSortBy(env, in, args[0])
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"group_by/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		groups, err := jqGroupBy(env, in, args[0])
+		if err != nil {
+			return err
+		}
+		out := make([]interface{}, len(groups))
+		for i, g := range groups {
+			out[i] = g
+		}
+		return emit(out)
+	},
+	"unique/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqUniqueBy(nil, in, nil)
+	}),
+	"unique_by/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqUniqueBy(env, in, args[0])
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"min/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqExtremeBy(nil, in, nil, false)
+	}),
+	"max/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqExtremeBy(nil, in, nil, true)
+	}),
+	"min_by/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqExtremeBy(env, in, args[0], false)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"max_by/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqExtremeBy(env, in, args[0], true)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"reverse/0": jqValueFunc(jqReverse),
+	"flatten/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqFlatten(in, json.Number("1e9"))
+	}),
+	"flatten/1":      jqValueFunc1(jqFlatten),
+	"to_entries/0":   jqValueFunc(jqToEntries),
+	"from_entries/0": jqValueFunc(jqFromEntries),
+	"with_entries/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		entries, err := jqToEntries(in)
+		if err != nil {
+			return err
+		}
+		mapped, err := jqMap(env, entries, args[0])
+		if err != nil {
+			return err
+		}
+		obj, err := jqFromEntries(mapped)
+		if err != nil {
+			return err
+		}
+		return emit(obj)
+	},
+	"map/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqMap(env, in, args[0])
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"map_values/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		v, err := jqMapValues(env, in, args[0])
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	},
+	"select/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[0].eval(env, in, func(c interface{}) error {
+			if jqTruthy(c) {
+				return emit(in)
+			}
+			return nil
+		})
+	},
+	"recurse/0": func(_ *jqEnv, in interface{}, _ []jqExpr, emit func(interface{}) error) error {
+		return jqWalk(in, emit)
+	},
+	"recurse/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		var rec func(v interface{}) error
+		rec = func(v interface{}) error {
+			if err := emit(v); err != nil {
+				return err
+			}
+			return args[0].eval(env, v, rec)
+		}
+		return rec(in)
+	},
+	"first/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqIndexValue(in, jqNumberZero)
+	}),
+	"last/0": jqValueFunc(func(in interface{}) (interface{}, error) {
+		return jqIndexValue(in, json.Number("-1"))
+	}),
+	"first/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return jqLimit(env, in, args[0], 1, emit)
+	},
+	"last/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		var (
+			last  interface{}
+			found bool
+		)
+		if err := args[0].eval(env, in, func(v interface{}) error {
+			last, found = v, true
+			return nil
+		}); err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		return emit(last)
+	},
+	"limit/2": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[0].eval(env, in, func(n interface{}) error {
+			f, ok := toFloat64(n)
+			if !ok {
+				return jqErrorf("invalid limit %s, a number is required", jqDescribe(n))
+			}
+			return jqLimit(env, in, args[1], int(f), emit)
+		})
+	},
+	"isempty/1": func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		empty := true
+		stop := &jqBreak{}
+		err := args[0].eval(env, in, func(interface{}) error {
+			empty = false
+			return stop
+		})
+		if err != nil && err != stop {
+			return err
+		}
+		return emit(empty)
+	},
+	"values/0": jqTypeFilter(func(v interface{}) bool { return v != nil }),
+	"nulls/0":  jqTypeFilter(func(v interface{}) bool { return v == nil }),
+	"booleans/0": jqTypeFilter(func(v interface{}) bool {
+		_, ok := v.(bool)
+		return ok
+	}),
+	"numbers/0": jqTypeFilter(isNumber),
+	"strings/0": jqTypeFilter(func(v interface{}) bool {
+		_, ok := v.(string)
+		return ok
+	}),
+	"arrays/0": jqTypeFilter(func(v interface{}) bool {
+		_, ok := v.([]interface{})
+		return ok
+	}),
+	"objects/0": jqTypeFilter(isObject),
+	"iterables/0": jqTypeFilter(func(v interface{}) bool {
+		_, ok := v.([]interface{})
+		return ok || isObject(v)
+	}),
+	"scalars/0": jqTypeFilter(func(v interface{}) bool {
+		_, ok := v.([]interface{})
+		return !ok && !isObject(v)
+	}),
+}
+
+// jqValueFunc 将一元值函数包装为无参数的内置函数
+func jqValueFunc(fn func(in interface{}) (interface{}, error)) jqFunc {
+	return func(_ *jqEnv, in interface{}, _ []jqExpr, emit func(interface{}) error) error {
+		v, err := fn(in)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	}
+}
+
+// jqValueFunc1 将二元值函数包装为单参数的内置函数，参数的每个输出都调用一次
+func jqValueFunc1(fn func(in, arg interface{}) (interface{}, error)) jqFunc {
+	return func(env *jqEnv, in interface{}, args []jqExpr, emit func(interface{}) error) error {
+		return args[0].eval(env, in, func(arg interface{}) error {
+			v, err := fn(in, arg)
+			if err != nil {
+				return err
+			}
+			return emit(v)
+		})
+	}
+}
+
+// jqTypeFilter 创建只输出满足条件的输入的内置函数，如 numbers、strings
+func jqTypeFilter(keep func(interface{}) bool) jqFunc {
+	return func(_ *jqEnv, in interface{}, _ []jqExpr, emit func(interface{}) error) error {
+		if keep(in) {
+			return emit(in)
+		}
+		return nil
+	}
+}
+
+// jqCollect 返回表达式的全部输出
+func jqCollect(env *jqEnv, in interface{}, e jqExpr) ([]interface{}, error) {
+	var out []interface{}
+	err := e.eval(env, in, func(v interface{}) error {
+		out = append(out, v)
+		return nil
+	})
+	return out, err
+}
+
+// jqTruthy 判断值在条件中是否为真，只有 false 和 null 为假
+func jqTruthy(v interface{}) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return v != nil
+}
+
+// jqDescribe 返回用于错误信息的值描述，如 number (5)
+func jqDescribe(v interface{}) string {
+	text, err := jqEncode(v)
+	if err != nil {
+		return jsonTypeName(v)
+	}
+	if utf8.RuneCountInString(text) > 20 {
+		text = string([]rune(text)[:17]) + "..."
+	}
+	return jsonTypeName(v) + " (" + text + ")"
+}
+
+// jqEncode 将值编码为紧凑的 JSON 文本，不转义 HTML 字符
+func jqEncode(v interface{}) (string, error) {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		// 与 jq 相同，NaN 输出为 null，无穷大输出为最大的有限值
+		switch {
+		case math.IsNaN(f):
+			return "null", nil
+		case f > 0:
+			return "1.7976931348623157e+308", nil
+		default:
+			return "-1.7976931348623157e+308", nil
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", &JQError{Value: err.Error()}
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jqToString 返回字符串本身，其他值编码为 JSON 文本
+func jqToString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return jqEncode(v)
+}
+
+// jqDecimal 将精确运算的结果转换为 json.Number，系数过大或指数超出范围时退化为 float64
+func jqDecimal(d Decimal) interface{} {
+	n := d.normalize()
+	if n.coefficient().BitLen() > jqMaxCoefBits || n.exp > maxDecimalExponent || n.exp < -maxDecimalExponent {
+		return jqFloat(n.Float64())
+	}
+	return n.Number()
+}
+
+// jqFloat 将浮点运算的结果转换为 json.Number，NaN 和无穷大保持为 float64
+func jqFloat(f float64) interface{} {
+	s, err := formatESNumber(f)
+	if err != nil {
+		return f
+	}
+	return json.Number(s)
+}
+
+// jqTypeRank 返回 jq 排序中类型的次序：null < false < true < 数字 < 字符串 < 数组 < 对象
+func jqTypeRank(v interface{}) int {
+	switch c := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if c {
+			return 2
+		}
+		return 1
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	if isNumber(v) {
+		return 3
+	}
+	if isObject(v) {
+		return 6
+	}
+	return 7
+}
+
+// jqCompare 按 jq 的全序比较两个值，返回 -1、0 或 1
+func jqCompare(a, b interface{}) int {
+	ra, rb := jqTypeRank(a), jqTypeRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch ra {
+	case 3:
+		c, _ := compareNumbers(a, b)
+		return c
+	case 4:
+		return strings.Compare(a.(string), b.(string))
+	case 5:
+		va, vb := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			if c := jqCompare(va[i], vb[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(va), len(vb))
+	case 6:
+		ka, kb := jqSortedKeys(a), jqSortedKeys(b)
+		for i := 0; i < len(ka) && i < len(kb); i++ {
+			if c := strings.Compare(ka[i], kb[i]); c != 0 {
+				return c
+			}
+		}
+		if c := compareInts(len(ka), len(kb)); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			va, _ := objectGet(a, k)
+			vb, _ := objectGet(b, k)
+			if c := jqCompare(va, vb); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// compareInts 比较两个整数，返回 -1、0 或 1
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// jqSortedKeys 返回对象按字典序排列的键
+func jqSortedKeys(v interface{}) []string {
+	keys := append([]string(nil), objectKeys(v)...)
+	sort.Strings(keys)
+	return keys
+}
+
+// jqCopyObject 浅拷贝对象，保持键的顺序
+func jqCopyObject(v interface{}) *JSONObject {
+	obj := NewJSONObject()
+	for _, k := range objectKeys(v) {
+		item, _ := objectGet(v, k)
+		obj.Set(k, item)
+	}
+	return obj
+}
+
+// jqMergeObjects 递归合并两个对象，b 中的值优先
+func jqMergeObjects(a, b interface{}) *JSONObject {
+	out := jqCopyObject(a)
+	for _, k := range objectKeys(b) {
+		bv, _ := objectGet(b, k)
+		if av, ok := out.Lookup(k); ok && isObject(av) && isObject(bv) {
+			out.Set(k, jqMergeObjects(av, bv))
+			continue
+		}
+		out.Set(k, bv)
+	}
+	return out
+}
+
+// jqArithVerbs 是各算术运算在错误信息中的描述
+var jqArithVerbs = map[string]string{
+	"+": "added", "-": "subtracted", "*": "multiplied", "/": "divided", "%": "divided",
+}
+
+// jqArith 计算算术运算；数字尽量按十进制精确计算，字符串、数组和对象按 jq 的规则处理
+func jqArith(op string, l, r interface{}) (interface{}, error) {
+	if isNumber(l) && isNumber(r) {
+		return jqNumberArith(op, l, r)
+	}
+	switch op {
+	case "+":
+		if l == nil {
+			return r, nil
+		}
+		if r == nil {
+			return l, nil
+		}
+		switch lv := l.(type) {
+		case string:
+			if rv, ok := r.(string); ok {
+				return lv + rv, nil
+			}
+		case []interface{}:
+			if rv, ok := r.([]interface{}); ok {
+				out := make([]interface{}, 0, len(lv)+len(rv))
+				return append(append(out, lv...), rv...), nil
+			}
+		}
+		if isObject(l) && isObject(r) {
+			out := jqCopyObject(l)
+			for _, k := range objectKeys(r) {
+				item, _ := objectGet(r, k)
+				out.Set(k, item)
+			}
+			return out, nil
+		}
+	case "-":
+		lv, ok1 := l.([]interface{})
+		rv, ok2 := r.([]interface{})
+		if ok1 && ok2 {
+			out := []interface{}{}
+			for _, item := range lv {
+				keep := true
+				for _, del := range rv {
+					if jqCompare(item, del) == 0 {
+						keep = false
+						break
+					}
+				}
+				if keep {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}
+	case "*":
+		if isObject(l) && isObject(r) {
+			return jqMergeObjects(l, r), nil
+		}
+		s, ok := l.(string)
+		n := r
+		if !ok {
+			s, ok = r.(string)
+			n = l
+		}
+		if f, isNum := toFloat64(n); ok && isNum {
+			if f < 1 {
+				return nil, nil
+			}
+			return strings.Repeat(s, int(f)), nil
+		}
+	case "/":
+		lv, ok1 := l.(string)
+		rv, ok2 := r.(string)
+		if ok1 && ok2 {
+			return jqSplit(lv, rv)
+		}
+	}
+	return nil, jqErrorf("%s and %s cannot be %s", jqDescribe(l), jqDescribe(r), jqArithVerbs[op])
+}
+
+// jqNumberArith 计算数字的算术运算：加减乘和整除的结果是精确的，其余除法按 float64 计算
+func jqNumberArith(op string, l, r interface{}) (interface{}, error) {
+	dl, okL := toDecimal(l)
+	dr, okR := toDecimal(r)
+	if (op == "/" || op == "%") && ((okR && dr.IsZero()) || (!okR && isZeroFloat(r))) {
+		return nil, jqErrorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(l), jqDescribe(r))
+	}
+	if okL && okR {
+		switch op {
+		case "+":
+			return jqDecimal(dl.Add(dr)), nil
+		case "-":
+			return jqDecimal(dl.Sub(dr)), nil
+		case "*":
+			return jqDecimal(dl.Mul(dr)), nil
+		case "/":
+			if q := new(big.Rat).Quo(dl.Rat(), dr.Rat()); q.IsInt() {
+				return jqDecimal(Decimal{coef: new(big.Int).Set(q.Num())}), nil
+			}
+		case "%":
+			a, b := jqTruncate(dl), jqTruncate(dr)
+			if b.Sign() == 0 {
+				return nil, jqErrorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(l), jqDescribe(r))
+			}
+			return jqDecimal(Decimal{coef: new(big.Int).Rem(a, b)}), nil
+		}
+	}
+	fl, _ := toFloat64(l)
+	fr, _ := toFloat64(r)
+	switch op {
+	case "+":
+		return jqFloat(fl + fr), nil
+	case "-":
+		return jqFloat(fl - fr), nil
+	case "*":
+		return jqFloat(fl * fr), nil
+	case "/":
+		return jqFloat(fl / fr), nil
+	}
+	a, b := math.Trunc(fl), math.Trunc(fr)
+	if b == 0 {
+		return nil, jqErrorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(l), jqDescribe(r))
+	}
+	return jqFloat(math.Mod(a, b)), nil
+}
+
+// isZeroFloat 判断数值转换为 float64 后是否为 0
+func isZeroFloat(v interface{}) bool {
+	f, ok := toFloat64(v)
+	return ok && f == 0
+}
+
+// jqTruncate 返回向零取整后的整数
+func jqTruncate(d Decimal) *big.Int {
+	if d.exp >= 0 {
+		return d.rescale(0)
+	}
+	return new(big.Int).Quo(d.coefficient(), pow10(-d.exp))
+}
+
+// jqRound 按 mode（floor、ceil 或 round）取整
+func jqRound(v interface{}, mode string) (interface{}, error) {
+	d, ok := toDecimal(v)
+	if !ok {
+		f, isNum := toFloat64(v)
+		if !isNum {
+			return nil, jqErrorf("%s number required", jqDescribe(v))
+		}
+		switch mode {
+		case "floor":
+			return jqFloat(math.Floor(f)), nil
+		case "ceil":
+			return jqFloat(math.Ceil(f)), nil
+		}
+		return jqFloat(math.Round(f)), nil
+	}
+	if d.exp >= 0 {
+		return jqDecimal(d), nil
+	}
+	switch mode {
+	case "floor":
+		// divisor 为正数时 big.Int.Div 是向下取整
+		return jqDecimal(Decimal{coef: new(big.Int).Div(d.coefficient(), pow10(-d.exp))}), nil
+	case "ceil":
+		q := new(big.Int).Div(new(big.Int).Neg(d.coefficient()), pow10(-d.exp))
+		return jqDecimal(Decimal{coef: q.Neg(q)}), nil
+	}
+	return jqDecimal(d.Round(0)), nil
+}
+
+// jqAbs 返回数字的绝对值
+func jqAbs(v interface{}) (interface{}, error) {
+	if d, ok := toDecimal(v); ok {
+		return jqDecimal(d.Abs()), nil
+	}
+	f, ok := toFloat64(v)
+	if !ok {
+		return nil, jqErrorf("%s has no absolute value", jqDescribe(v))
+	}
+	return jqFloat(math.Abs(f)), nil
+}
+
+// jqToNumber 将字符串解析为数字，数字原样返回
+func jqToNumber(v interface{}) (interface{}, error) {
+	if isNumber(v) {
+		return v, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, jqErrorf("%s cannot be parsed as a number", jqDescribe(v))
+	}
+	d, err := ParseDecimal(s)
+	if err != nil || !isJSONNumberText(s) {
+		return nil, jqErrorf("cannot parse %q as a number", s)
+	}
+	return jqDecimal(d), nil
+}
+
+// jqLength 返回字符串的字符数、数组和对象的成员数、数字的绝对值，null 的长度为 0
+func jqLength(v interface{}) (interface{}, error) {
+	switch c := v.(type) {
+	case nil:
+		return jqNumberZero, nil
+	case string:
+		return jqInt(utf8.RuneCountInString(c)), nil
+	case []interface{}:
+		return jqInt(len(c)), nil
+	}
+	if isObject(v) {
+		return jqInt(objectLen(v)), nil
+	}
+	if isNumber(v) {
+		return jqAbs(v)
+	}
+	return nil, jqErrorf("%s has no length", jqDescribe(v))
+}
+
+// jqUTF8ByteLength 返回字符串的 UTF-8 字节数
+func jqUTF8ByteLength(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, jqErrorf("%s only strings have UTF-8 byte length", jqDescribe(v))
+	}
+	return jqInt(len(s)), nil
+}
+
+// jqInt 将整数转换为 json.Number
+func jqInt(n int) json.Number {
+	return json.Number(big.NewInt(int64(n)).String())
+}
+
+// jqKeys 返回对象的键或数组的下标，sorted 为 false 时对象的键保持原有顺序
+func jqKeys(v interface{}, sorted bool) (interface{}, error) {
+	if arr, ok := v.([]interface{}); ok {
+		out := make([]interface{}, len(arr))
+		for i := range arr {
+			out[i] = jqInt(i)
+		}
+		return out, nil
+	}
+	if !isObject(v) {
+		return nil, jqErrorf("%s has no keys", jqDescribe(v))
+	}
+	keys := objectKeys(v)
+	if sorted {
+		keys = jqSortedKeys(v)
+	}
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out, nil
+}
+
+// jqHas 判断对象是否包含键或数组是否包含下标
+func jqHas(v, key interface{}) (interface{}, error) {
+	if isObject(v) {
+		if k, ok := key.(string); ok {
+			_, found := objectGet(v, k)
+			return found, nil
+		}
+	}
+	if arr, ok := v.([]interface{}); ok {
+		if f, isNum := toFloat64(key); isNum {
+			return f >= 0 && f < float64(len(arr)), nil
+		}
+	}
+	return nil, jqErrorf("cannot check whether %s has a %s key", jsonTypeName(v), jsonTypeName(key))
+}
+
+// jqContainsChecked 实现 contains，两个值的类型必须相同
+func jqContainsChecked(a, b interface{}) (interface{}, error) {
+	if jsonTypeName(a) != jsonTypeName(b) {
+		return nil, jqErrorf("%s and %s cannot have their containment checked", jqDescribe(a), jqDescribe(b))
+	}
+	return jqContains(a, b), nil
+}
+
+// jqContains 判断 a 是否包含 b：字符串为子串，数组的每个元素都被 a 的某个元素包含，对象按键递归判断
+func jqContains(a, b interface{}) bool {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && strings.Contains(av, bv)
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, want := range bv {
+			found := false
+			for _, have := range av {
+				if jsonTypeName(have) == jsonTypeName(want) && jqContains(have, want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	if isObject(a) {
+		if !isObject(b) {
+			return false
+		}
+		for _, k := range objectKeys(b) {
+			want, _ := objectGet(b, k)
+			have, ok := objectGet(a, k)
+			if !ok || jsonTypeName(have) != jsonTypeName(want) || !jqContains(have, want) {
+				return false
+			}
+		}
+		return true
+	}
+	return jqCompare(a, b) == 0
+}
+
+// jqAdd 将数组的元素（或对象的值）依次相加，没有元素时返回 null
+func jqAdd(v interface{}) (interface{}, error) {
+	var acc interface{}
+	err := jqEach(v, func(item interface{}) error {
+		sum, err := jqArith("+", acc, item)
+		acc = sum
+		return err
+	})
+	return acc, err
+}
+
+// jqAnyAll 判断是否有（wantAny 为 true）或所有（wantAny 为 false）元素满足条件，f 为 nil 时判断元素本身
+func jqAnyAll(env *jqEnv, in interface{}, f jqExpr, wantAny bool) (interface{}, error) {
+	result := !wantAny
+	stop := &jqBreak{}
+	err := jqEach(in, func(item interface{}) error {
+		check := func(c interface{}) error {
+			if jqTruthy(c) == wantAny {
+				result = wantAny
+				return stop
+			}
+			return nil
+		}
+		if f == nil {
+			return check(item)
+		}
+		return f.eval(env, item, check)
+	})
+	if err != nil && err != stop {
+		return nil, err
+	}
+	return result, nil
+}
+
+// jqRange 输出 [from, upto) 中步长为 1 的数字
+func jqRange(from, upto interface{}, emit func(interface{}) error) error {
+	start, ok1 := toDecimal(from)
+	end, ok2 := toDecimal(upto)
+	if !ok1 || !ok2 {
+		return jqErrorf("range bounds must be numbers")
+	}
+	one := NewDecimalFromInt(1)
+	for d := start; d.Cmp(end) < 0; d = d.Add(one) {
+		if err := emit(jqDecimal(d)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jqLimit 最多输出 f 的前 n 个值
+func jqLimit(env *jqEnv, in interface{}, f jqExpr, n int, emit func(interface{}) error) error {
+	if n <= 0 {
+		return nil
+	}
+	count := 0
+	stop := &jqBreak{}
+	err := f.eval(env, in, func(v interface{}) error {
+		if err := emit(v); err != nil {
+			return err
+		}
+		count++
+		if count >= n {
+			return stop
+		}
+		return nil
+	})
+	if err == stop {
+		return nil
+	}
+	return err
+}
+
+// jqASCIICase 创建转换字符串中 ASCII 字母大小写的函数
+func jqASCIICase(convert func(rune) rune, name string) func(interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, jqErrorf("%s cannot be %s, only strings can", jqDescribe(v), name)
+		}
+		return strings.Map(func(r rune) rune {
+			if r < utf8.RuneSelf {
+				return convert(r)
+			}
+			return r
+		}, s), nil
+	}
+}
+
+// jqTrim 创建去除字符串空白的函数
+func jqTrim(trim func(string) string, name string) func(interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, jqErrorf("%s cannot be trimmed with %s, only strings can", jqDescribe(v), name)
+		}
+		return trim(s), nil
+	}
+}
+
+// jqSplit 按分隔符拆分字符串
+func jqSplit(v, sep interface{}) (interface{}, error) {
+	s, ok1 := v.(string)
+	p, ok2 := sep.(string)
+	if !ok1 || !ok2 {
+		return nil, jqErrorf("split input and separator must be strings")
+	}
+	out := []interface{}{}
+	if s == "" {
+		return out, nil
+	}
+	for _, part := range strings.Split(s, p) {
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+// jqJoin 以分隔符连接数组元素，null 视为空字符串，数字和布尔值转换为文本
+func jqJoin(v, sep interface{}) (interface{}, error) {
+	p, ok := sep.(string)
+	if !ok {
+		return nil, jqErrorf("join separator must be a string, got %s", jqDescribe(sep))
+	}
+	var sb strings.Builder
+	first := true
+	err := jqEach(v, func(item interface{}) error {
+		if !first {
+			sb.WriteString(p)
+		}
+		first = false
+		switch c := item.(type) {
+		case nil:
+		case string:
+			sb.WriteString(c)
+		case bool:
+			text, _ := jqEncode(c)
+			sb.WriteString(text)
+		default:
+			if !isNumber(item) {
+				return jqErrorf("cannot join with %s", jqDescribe(item))
+			}
+			text, err := jqEncode(item)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(text)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sb.String(), nil
+}
+
+// jqRegexpCache 缓存 test 使用的已编译正则表达式
+var jqRegexpCache sync.Map
+
+// jqTest 判断字符串是否匹配正则表达式（RE2 语法），flags 支持 g、i、s、n 和 l 中与 RE2 兼容的部分
+func jqTest(v, re, flags interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, jqErrorf("%s cannot be matched, as it is not a string", jqDescribe(v))
+	}
+	pattern, ok := re.(string)
+	if !ok {
+		return nil, jqErrorf("%s cannot be used as a regular expression", jqDescribe(re))
+	}
+	prefix := ""
+	if flags != nil {
+		f, ok := flags.(string)
+		if !ok {
+			return nil, jqErrorf("%s is not a string", jqDescribe(flags))
+		}
+		for _, c := range f {
+			switch c {
+			case 'g', 'n', 'l':
+				// 只判断是否匹配，全局匹配、忽略空匹配和最长匹配不影响结果
+			case 'i':
+				prefix += "i"
+			case 's':
+				prefix += "s"
+			default:
+				return nil, jqErrorf("%s is not a valid modifier string", f)
+			}
+		}
+	}
+	if prefix != "" {
+		pattern = "(?" + prefix + ")" + pattern
+	}
+	compiled, ok := jqRegexpCache.Load(pattern)
+	if !ok {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, jqErrorf("%s (at offset 0) is not a valid regex: %v", pattern, err)
+		}
+		compiled, _ = jqRegexpCache.LoadOrStore(pattern, r)
+	}
+	return compiled.(*regexp.Regexp).MatchString(s), nil
+}
+
+// jqArrayInput 要求输入为数组
+func jqArrayInput(v interface{}, what string) ([]interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, jqErrorf("%s cannot be %s, as it is not an array", jqDescribe(v), what)
+	}
+	return arr, nil
+}
+
+// jqKeyed 是带排序键的数组元素
+type jqKeyed struct {
+	key, value interface{}
+}
+
+// jqSortKeys 计算每个元素的排序键 [f]，f 为 nil 时使用元素本身，并按键稳定排序
+func jqSortKeys(env *jqEnv, in interface{}, f jqExpr, what string) ([]jqKeyed, error) {
+	arr, err := jqArrayInput(in, what)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]jqKeyed, len(arr))
+	for i, item := range arr {
+		items[i] = jqKeyed{key: item, value: item}
+		if f != nil {
+			key, err := jqCollect(env, item, f)
+			if err != nil {
+				return nil, err
+			}
+			if key == nil {
+				key = []interface{}{}
+			}
+			items[i].key = key
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return jqCompare(items[i].key, items[j].key) < 0
+	})
+	return items, nil
+}
+
+// jqSortBy 按 f 的结果稳定排序数组
+func jqSortBy(env *jqEnv, in interface{}, f jqExpr) (interface{}, error) {
+	items, err := jqSortKeys(env, in, f, "sorted")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		out[i] = item.value
+	}
+	return out, nil
+}
+
+// jqGroupBy 按 f 的结果分组，组按键的顺序排列
+func jqGroupBy(env *jqEnv, in interface{}, f jqExpr) ([][]interface{}, error) {
+	items, err := jqSortKeys(env, in, f, "grouped")
+	if err != nil {
+		return nil, err
+	}
+	groups := [][]interface{}{}
+	for i, item := range items {
+		if i == 0 || jqCompare(items[i-1].key, item.key) != 0 {
+			groups = append(groups, []interface{}{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], item.value)
+	}
+	return groups, nil
+}
+
+// jqUniqueBy 按 f 的结果去重，每组保留第一个元素
+func jqUniqueBy(env *jqEnv, in interface{}, f jqExpr) (interface{}, error) {
+	groups, err := jqGroupBy(env, in, f)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(groups))
+	for i, g := range groups {
+		out[i] = g[0]
+	}
+	return out, nil
+}
+
+// jqExtremeBy 返回 f 的结果最小（largest 为 false）或最大的元素，空数组返回 null；
+// 与 jq 相同，有多个最小值时取第一个，有多个最大值时取最后一个
+func jqExtremeBy(env *jqEnv, in interface{}, f jqExpr, largest bool) (interface{}, error) {
+	arr, err := jqArrayInput(in, "compared")
+	if err != nil {
+		return nil, err
+	}
+	var (
+		best    interface{}
+		bestKey interface{}
+	)
+	for i, item := range arr {
+		key := item
+		if f != nil {
+			values, err := jqCollect(env, item, f)
+			if err != nil {
+				return nil, err
+			}
+			key = values
+		}
+		c := jqCompare(key, bestKey)
+		if i == 0 || (largest && c >= 0) || (!largest && c < 0) {
+			best, bestKey = item, key
+		}
+	}
+	return best, nil
+}
+
+// jqReverse 反转数组或字符串，null 返回空数组
+func jqReverse(v interface{}) (interface{}, error) {
+	switch c := v.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case string:
+		runes := []rune(c)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	case []interface{}:
+		out := make([]interface{}, len(c))
+		for i, item := range c {
+			out[len(c)-1-i] = item
+		}
+		return out, nil
+	}
+	return nil, jqErrorf("cannot reverse %s", jqDescribe(v))
+}
+
+// jqFlatten 将嵌套数组展开 depth 层
+func jqFlatten(v, depth interface{}) (interface{}, error) {
+	arr, err := jqArrayInput(v, "flattened")
+	if err != nil {
+		return nil, err
+	}
+	d, ok := toFloat64(depth)
+	if !ok {
+		return nil, jqErrorf("flatten depth must be a number")
+	}
+	if d < 0 {
+		return nil, jqErrorf("flatten depth must not be negative")
+	}
+	return jqFlattenDepth(arr, d), nil
+}
+
+func jqFlattenDepth(arr []interface{}, depth float64) []interface{} {
+	out := []interface{}{}
+	for _, item := range arr {
+		if inner, ok := item.([]interface{}); ok && depth > 0 {
+			out = append(out, jqFlattenDepth(inner, depth-1)...)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// jqToEntries 将对象转换为 {key, value} 数组，数组的键为下标
+func jqToEntries(v interface{}) (interface{}, error) {
+	keys, err := jqKeys(v, false)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, k := range keys.([]interface{}) {
+		item, _ := jqIndexValue(v, k)
+		entry := NewJSONObject()
+		entry.Set("key", k)
+		entry.Set("value", item)
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// jqEntryKeyNames 和 jqEntryValueNames 是 from_entries 识别的键名和值名
+var (
+	jqEntryKeyNames   = []string{"key", "k", "name", "Name", "K", "Key"}
+	jqEntryValueNames = []string{"value", "v", "Value", "V"}
+)
+
+// jqFromEntries 将 {key, value} 数组转换为对象，也接受 k/v、name/value 等写法
+func jqFromEntries(v interface{}) (interface{}, error) {
+	obj := NewJSONObject()
+	err := jqEach(v, func(entry interface{}) error {
+		if !isObject(entry) {
+			return jqErrorf("cannot use %s as an object entry", jqDescribe(entry))
+		}
+		var key interface{}
+		for _, name := range jqEntryKeyNames {
+			if k, ok := objectGet(entry, name); ok && k != nil {
+				key = k
+				break
+			}
+		}
+		var value interface{}
+		for _, name := range jqEntryValueNames {
+			if item, ok := objectGet(entry, name); ok {
+				value = item
+				break
+			}
+		}
+		switch k := key.(type) {
+		case nil:
+			return jqErrorf("cannot use null as object key")
+		case string:
+			obj.Set(k, value)
+		default:
+			text, err := jqEncode(k)
+			if err != nil {
+				return err
+			}
+			obj.Set(text, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// jqMap 对数组的每个元素（或对象的每个值）应用 f，收集全部输出
+func jqMap(env *jqEnv, in interface{}, f jqExpr) (interface{}, error) {
+	out := []interface{}{}
+	err := jqEach(in, func(item interface{}) error {
+		return f.eval(env, item, func(v interface{}) error {
+			out = append(out, v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// jqMapValues 将对象或数组的每个值替换为 f 的第一个输出，没有输出的成员被删除
+func jqMapValues(env *jqEnv, in interface{}, f jqExpr) (interface{}, error) {
+	first := func(item interface{}) (interface{}, bool, error) {
+		var (
+			result interface{}
+			found  bool
+		)
+		stop := &jqBreak{}
+		err := f.eval(env, item, func(v interface{}) error {
+			result, found = v, true
+			return stop
+		})
+		if err != nil && err != stop {
+			return nil, false, err
+		}
+		return result, found, nil
+	}
+	if arr, ok := in.([]interface{}); ok {
+		out := []interface{}{}
+		for _, item := range arr {
+			v, found, err := first(item)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	}
+	if !isObject(in) {
+		return nil, jqErrorf("cannot iterate over %s", jqDescribe(in))
+	}
+	out := NewJSONObject()
+	for _, k := range objectKeys(in) {
+		item, _ := objectGet(in, k)
+		v, found, err := first(item)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			out.Set(k, v)
+		}
+	}
+	return out, nil
+}
+
+// jqIndexDesc 返回下标在错误信息中的描述
+func jqIndexDesc(i interface{}) string {
+	if s, ok := i.(string); ok {
+		return quoteJSONString(s)
+	}
+	return jsonTypeName(i)
+}
+
+// jqIndexValue 计算 t[i]：对象按键取值，数组按下标取值（负数从末尾计数），越界和 null 输入返回 null
+func jqIndexValue(t, i interface{}) (interface{}, error) {
+	switch c := t.(type) {
+	case nil:
+		if _, ok := i.(string); ok || i == nil || isNumber(i) {
+			return nil, nil
+		}
+	case []interface{}:
+		if f, ok := toFloat64(i); ok {
+			idx := math.Floor(f)
+			if idx < 0 {
+				idx += float64(len(c))
+			}
+			if idx < 0 || idx >= float64(len(c)) {
+				return nil, nil
+			}
+			return c[int(idx)], nil
+		}
+	default:
+		if k, ok := i.(string); ok && isObject(t) {
+			v, _ := objectGet(t, k)
+			return v, nil
+		}
+	}
+	return nil, jqErrorf("cannot index %s with %s", jsonTypeName(t), jqIndexDesc(i))
+}
+
+// jqSliceValue 计算数组或字符串（按字符）的切片，null 输入返回 null
+func jqSliceValue(t, from, to interface{}) (interface{}, error) {
+	if t == nil {
+		return nil, nil
+	}
+	var length int
+	switch c := t.(type) {
+	case string:
+		length = utf8.RuneCountInString(c)
+	case []interface{}:
+		length = len(c)
+	default:
+		return nil, jqErrorf("cannot index %s with object", jsonTypeName(t))
+	}
+	bound := func(b interface{}, def int, round func(float64) float64) (int, error) {
+		if b == nil {
+			return def, nil
+		}
+		f, ok := toFloat64(b)
+		if !ok {
+			return 0, jqErrorf("start and end indices of an array slice must be numbers")
+		}
+		f = round(f)
+		if f < 0 {
+			f += float64(length)
+		}
+		switch {
+		case f < 0:
+			return 0, nil
+		case f > float64(length):
+			return length, nil
+		}
+		return int(f), nil
+	}
+	start, err := bound(from, 0, math.Floor)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, length, math.Ceil)
+	if err != nil {
+		return nil, err
+	}
+	if end < start {
+		end = start
+	}
+	if s, ok := t.(string); ok {
+		return string([]rune(s)[start:end]), nil
+	}
+	return append([]interface{}{}, t.([]interface{})[start:end]...), nil
+}
@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -22,8 +22,12 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 }
 
 // Unmarshal 将 JSON 字节切片解析为对象
+// 语法错误和类型不匹配返回 *JSONError，其中包含行列号、出错字段的路径和源文本片段
 func Unmarshal(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
+	if err := json.Unmarshal(data, v); err != nil {
+		return annotateJSONError(data, "", err)
+	}
+	return nil
 }
 
 // ToJSON 将对象转换为 JSON 字符串
@@ -40,22 +44,43 @@ func FromJSON(jsonStr string, v interface{}) error {
 	return Unmarshal([]byte(jsonStr), v)
 }
 
-// ToJSONFile 将对象保存为 JSON 文件
-func ToJSONFile(v interface{}, filename string) error {
+// UnmarshalExact 与 Unmarshal 相同，但 interface{} 中的数字解码为 json.Number 而不是 float64，
+// 超过 2^53 的整数和高精度小数不会丢失精度
+func UnmarshalExact(data []byte, v interface{}) error {
+	// 先完整校验一次，使语法错误和尾随内容的报告与 Unmarshal 一致
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return annotateJSONError(data, "", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return annotateJSONError(data, "", err)
+	}
+	return nil
+}
+
+// FromJSONExact 将 JSON 字符串解析为对象，数字规则与 UnmarshalExact 相同
+func FromJSONExact(jsonStr string, v interface{}) error {
+	return UnmarshalExact([]byte(jsonStr), v)
+}
+
+// ToJSONFile 将对象原子地保存为 JSON 文件，写入方式和选项见 WriteFileAtomic
+func ToJSONFile(v interface{}, filename string, opts ...WriteOption) error {
 	data, err := MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, data, 0644)
+	return WriteFileAtomic(filename, data, opts...)
 }
 
-// FromJSONFile 从 JSON 文件读取并解析为对象
+// FromJSONFile 从 JSON 文件读取并解析为对象，解析错误为包含文件名的 *JSONError
 func FromJSONFile(filename string, v interface{}) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	return Unmarshal(data, v)
+	return annotateJSONError(data, filename, Unmarshal(data, v))
 }
 
 // IsValidJSON 检查字符串是否为有效的 JSON
@@ -64,9 +89,20 @@ func IsValidJSON(str string) bool {
 	return json.Unmarshal([]byte(str), &js) == nil
 }
 
-// PrettyPrint 返回格式化的 JSON 字符串
-func PrettyPrint(v interface{}) (string, error) {
-	b, err := MarshalIndent(v, "", "  ")
+// PrettyPrint 返回格式化的 JSON 字符串，默认两个空格缩进，可通过 PrettyOption 调整，见 FormatJSON
+func PrettyPrint(v interface{}, opts ...PrettyOption) (string, error) {
+	if len(opts) == 0 {
+		b, err := MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	b, err := FormatJSON(data, opts...)
 	if err != nil {
 		return "", err
 	}
@@ -86,8 +122,9 @@ func Compact(v interface{}) (string, error) {
 	return buf.String(), nil
 }
 
-// DeepCopy 深拷贝 JSON 对象
-func DeepCopy(src, dst interface{}) error {
+// DeepCopyByJSON 通过 JSON 编解码将 src 深拷贝到 dst
+// 只会复制可序列化的导出字段，interface{} 中的数字会变为 float64；一般情况请使用 DeepCopy
+func DeepCopyByJSON(src, dst interface{}) error {
 	data, err := Marshal(src)
 	if err != nil {
 		return err
@@ -95,50 +132,77 @@ func DeepCopy(src, dst interface{}) error {
 	return Unmarshal(data, dst)
 }
 
-// MergeJSON 合并两个 JSON 对象
-func MergeJSON(json1, json2 string) (string, error) {
-	var m1, m2, result map[string]interface{}
-
-	if err := FromJSON(json1, &m1); err != nil {
-		return "", err
-	}
-	if err := FromJSON(json2, &m2); err != nil {
-		return "", err
+// DeepCopyByJSONExact 与 DeepCopyByJSON 相同，但 interface{} 中的数字会变为 json.Number
+func DeepCopyByJSONExact(src, dst interface{}) error {
+	data, err := Marshal(src)
+	if err != nil {
+		return err
 	}
+	return UnmarshalExact(data, dst)
+}
 
-	result = mergeMap(m1, m2)
-	return ToJSON(result)
+// MergeJSON 深度合并两个 JSON 值，json2 中的值优先
+// 默认 null 覆盖原值、数组整体替换，可通过 MergeOption 启用 RFC 7396 删除语义或其他数组合并策略；
+// 结果保留 json1 中键的顺序，新增的键按 json2 中的顺序追加；数字经过 float64 转换
+func MergeJSON(json1, json2 string, opts ...MergeOption) (string, error) {
+	return mergeJSON(json1, json2, false, opts)
 }
 
-// mergeMap 递归合并两个 map
-func mergeMap(m1, m2 map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
+// MergeJSONExact 与 MergeJSON 相同，但数字保持原文，不会丢失精度
+func MergeJSONExact(json1, json2 string, opts ...MergeOption) (string, error) {
+	return mergeJSON(json1, json2, true, opts)
+}
 
-	for k, v := range m1 {
-		result[k] = v
+// mergeJSON 实现 MergeJSON，exact 为 true 时数字解码为 json.Number
+func mergeJSON(json1, json2 string, exact bool, opts []MergeOption) (string, error) {
+	v1, err := decodeOrderedBytes([]byte(json1), exact)
+	if err != nil {
+		return "", err
 	}
-
-	for k, v := range m2 {
-		if v2, ok := result[k]; ok {
-			if vMap, ok := v.(map[string]interface{}); ok {
-				if v2Map, ok := v2.(map[string]interface{}); ok {
-					result[k] = mergeMap(v2Map, vMap)
-					continue
-				}
-			}
-		}
-		result[k] = v
+	v2, err := decodeOrderedBytes([]byte(json2), exact)
+	if err != nil {
+		return "", err
 	}
 
-	return result
+	return ToJSON(DeepMerge(v1, v2, opts...))
 }
 
-// GetValueByPath 通过路径获取 JSON 中的值
+// GetValueByPath 通过路径获取 JSON 中的值，数字为 float64
+// 路径以 $ 开头时按 JSONPath（RFC 9535）解析，匹配多个节点时返回切片；
+// 否则按点号分隔，数组可使用下标或 * 访问，如 items.0.name
 func GetValueByPath(jsonStr, path string) (interface{}, error) {
 	var data interface{}
 	if err := FromJSON(jsonStr, &data); err != nil {
 		return nil, err
 	}
+	return getValueByPath(data, path)
+}
+
+// GetValueByPathExact 与 GetValueByPath 相同，但数字为 json.Number
+func GetValueByPathExact(jsonStr, path string) (interface{}, error) {
+	data, err := decodeAny([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	return getValueByPath(data, path)
+}
+
+// getValueByPath 在已解析的值中按路径取值
+func getValueByPath(data interface{}, path string) (interface{}, error) {
+	if strings.HasPrefix(path, "$") {
+		p, err := CompileJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		values := p.Query(data)
+		if p.IsSingular() {
+			if len(values) == 0 {
+				return nil, fmt.Errorf("path not found: %s", path)
+			}
+			return values[0], nil
+		}
+		return values, nil
+	}
 
 	keys := strings.Split(path, ".")
 	return getValueByKeys(data, keys)
@@ -167,77 +231,71 @@ func getValueByKeys(data interface{}, keys []string) (interface{}, error) {
 			}
 			return results, nil
 		}
-		return nil, fmt.Errorf("invalid key for array: %s", keys[0])
+		index, err := strconv.Atoi(keys[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for array: %s", keys[0])
+		}
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return nil, fmt.Errorf("index out of range: %s", keys[0])
+		}
+		return getValueByKeys(v[index], keys[1:])
 	default:
 		return nil, errors.New("invalid JSON structure")
 	}
 }
 
-// Diff 比较两个 JSON 对象，返回差异
-func Diff(json1, json2 string) (map[string]interface{}, error) {
-	var obj1, obj2 interface{}
-	if err := FromJSON(json1, &obj1); err != nil {
-		return nil, err
-	}
-	if err := FromJSON(json2, &obj2); err != nil {
+// Diff 比较两个 JSON 对象，返回以点号路径为键、{old, new} 为值的差异
+// 数组按元素比较，删除的元素以旧数组中的下标为键、new 为 nil，插入的元素以新数组中的下标为键、old 为 nil；
+// 同一路径上既有删除又有插入时合并为一项，移动的元素额外包含 from，即其在旧数组中的路径；
+// 值的结构与 FromJSON 的结果相同，对象为 map[string]interface{}，数字为 float64
+func Diff(json1, json2 string, opts ...DiffOption) (map[string]interface{}, error) {
+	return diffJSON(json1, json2, false, opts)
+}
+
+// DiffExact 与 Diff 相同，但值中的数字为 json.Number
+func DiffExact(json1, json2 string, opts ...DiffOption) (map[string]interface{}, error) {
+	return diffJSON(json1, json2, true, opts)
+}
+
+// diffJSON 实现 Diff，比较总是按精确的数值进行，exact 只决定返回值中数字的类型
+func diffJSON(json1, json2 string, exact bool, opts []DiffOption) (map[string]interface{}, error) {
+	result, err := CompareJSON(json1, json2, opts...)
+	if err != nil {
 		return nil, err
 	}
 
 	diff := make(map[string]interface{})
-	diffObjects("", obj1, obj2, diff)
-	return diff, nil
-}
-
-// diffObjects 递归比较两个对象
-func diffObjects(prefix string, obj1, obj2 interface{}, diff map[string]interface{}) {
-	switch v1 := obj1.(type) {
-	case map[string]interface{}:
-		v2, ok := obj2.(map[string]interface{})
-		if !ok {
-			diff[prefix] = map[string]interface{}{"old": obj1, "new": obj2}
-			return
+	for _, c := range result.Changes {
+		key := dottedPath(c.keys)
+		entry, exists := diff[key].(map[string]interface{})
+		if !exists && c.Type == ChangeMove && dottedPath(c.source) == key {
+			// 其他元素移动后该元素在新旧文档中的下标相同
+			continue
 		}
-		for k, v := range v1 {
-			newPrefix := prefix
-			if newPrefix != "" {
-				newPrefix += "."
-			}
-			newPrefix += k
-			if v2v, ok := v2[k]; ok {
-				diffObjects(newPrefix, v, v2v, diff)
-			} else {
-				diff[newPrefix] = map[string]interface{}{"old": v, "new": nil}
-			}
+		if !exists {
+			entry = map[string]interface{}{"old": nil, "new": nil}
+			diff[key] = entry
 		}
-		for k, v := range v2 {
-			if _, ok := v1[k]; !ok {
-				newPrefix := prefix
-				if newPrefix != "" {
-					newPrefix += "."
-				}
-				newPrefix += k
-				diff[newPrefix] = map[string]interface{}{"old": nil, "new": v}
+		oldValue, newValue := toPlainTree(c.OldValue, exact), toPlainTree(c.NewValue, exact)
+		switch c.Type {
+		case ChangeRemove:
+			entry["old"] = oldValue
+		case ChangeAdd:
+			entry["new"] = newValue
+		case ChangeReplace:
+			entry["old"], entry["new"] = oldValue, newValue
+		case ChangeMove:
+			if !exists {
+				entry["old"] = oldValue
 			}
-		}
-	case []interface{}:
-		v2, ok := obj2.([]interface{})
-		if !ok {
-			diff[prefix] = map[string]interface{}{"old": obj1, "new": obj2}
-			return
-		}
-		if len(v1) != len(v2) {
-			diff[prefix] = map[string]interface{}{"old": obj1, "new": obj2}
-			return
-		}
-		for i := range v1 {
-			newPrefix := fmt.Sprintf("%s[%d]", prefix, i)
-			diffObjects(newPrefix, v1[i], v2[i], diff)
-		}
-	default:
-		if !reflect.DeepEqual(obj1, obj2) {
-			diff[prefix] = map[string]interface{}{"old": obj1, "new": obj2}
+			entry["new"] = newValue
+			entry["from"] = dottedPath(c.source)
 		}
 	}
+	return diff, nil
 }
 
 // StreamingDecode 流式解码大型 JSON 文件
@@ -0,0 +1,980 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSON5SyntaxError 表示 JSON5/JSONC 文本的语法错误
+type JSON5SyntaxError struct {
+	Line   int    // 行号，从 1 开始
+	Column int    // 列号（按字符计），从 1 开始
+	Msg    string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *JSON5SyntaxError) Error() string {
+	return fmt.Sprintf("json5 syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseJSON5 解析 JSON5 或 JSONC 文本，返回与 FromJSON 解码到 interface{} 相同结构的值，需要保留数字精度时使用 ParseFormatExact
+// 支持注释、尾随逗号、单引号字符串、不带引号的键、十六进制数、Infinity 和 NaN；JSONC 是其子集
+func ParseJSON5(data []byte) (interface{}, error) {
+	return parseJSON5Value(data, false)
+}
+
+// parseJSON5Value 解析 JSON5 文本，exact 为 true 时数字保持为 json.Number
+func parseJSON5Value(data []byte, exact bool) (interface{}, error) {
+	doc, err := parseJSON5Document(data)
+	if err != nil {
+		return nil, err
+	}
+	return toPlainTree(doc.root.toValue(), exact), nil
+}
+
+// JSON5ToJSON 将 JSON5 或 JSONC 文本转换为标准 JSON，注释会被丢弃
+// 标准 JSON 无法表示 Infinity 和 NaN，遇到它们时返回错误
+func JSON5ToJSON(data []byte) ([]byte, error) {
+	doc, err := parseJSON5Document(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := doc.root.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON5 将 JSON5 或 JSONC 文本解析到 v 中，规则与 Unmarshal 相同
+func UnmarshalJSON5(data []byte, v interface{}) error {
+	if p, ok := v.(*interface{}); ok {
+		value, err := ParseJSON5(data)
+		if err != nil {
+			return err
+		}
+		*p = value
+		return nil
+	}
+	js, err := JSON5ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return unmarshalConverted(js, v, false)
+}
+
+// FromJSON5 将 JSON5 或 JSONC 字符串解析到 v 中
+func FromJSON5(str string, v interface{}) error {
+	return UnmarshalJSON5([]byte(str), v)
+}
+
+// FromJSON5File 读取 JSON5 或 JSONC 文件并解析到 v 中
+func FromJSON5File(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return UnmarshalJSON5(data, v)
+}
+
+// json5FormatOptions 保存 JSON5 格式化的选项
+type json5FormatOptions struct {
+	indent         string
+	trailingCommas bool
+	unquotedKeys   bool
+}
+
+// JSON5FormatOption 是设置 JSON5 格式化选项的函数类型
+type JSON5FormatOption func(*json5FormatOptions)
+
+// WithJSON5Indent 设置缩进字符串，默认为两个空格
+func WithJSON5Indent(indent string) JSON5FormatOption {
+	return func(o *json5FormatOptions) {
+		o.indent = indent
+	}
+}
+
+// WithTrailingCommas 在多行对象和数组的最后一个元素后输出逗号
+func WithTrailingCommas() JSON5FormatOption {
+	return func(o *json5FormatOptions) {
+		o.trailingCommas = true
+	}
+}
+
+// WithUnquotedKeys 对合法标识符形式的键不加引号（JSON5 风格）
+func WithUnquotedKeys() JSON5FormatOption {
+	return func(o *json5FormatOptions) {
+		o.unquotedKeys = true
+	}
+}
+
+// FormatJSON5 重新格式化 JSON5 或 JSONC 文本，保留注释、空行分组和数字的原始写法
+// 字符串统一输出为双引号形式
+func FormatJSON5(data []byte, opts ...JSON5FormatOption) ([]byte, error) {
+	o := &json5FormatOptions{indent: "  "}
+	for _, opt := range opts {
+		opt(o)
+	}
+	doc, err := parseJSON5Document(data)
+	if err != nil {
+		return nil, err
+	}
+	f := &json5Formatter{opts: o}
+	f.writeComments(doc.root.leading, 0)
+	f.writeNode(doc.root, 0)
+	f.writeTrailing(doc.root.trailing)
+	f.buf.WriteByte('\n')
+	for _, c := range doc.after {
+		if c.newlines >= 2 {
+			f.buf.WriteByte('\n')
+		}
+		f.buf.WriteString(c.text)
+		f.buf.WriteByte('\n')
+	}
+	return f.buf.Bytes(), nil
+}
+
+// json5Kind 表示 JSON5 节点的类型
+type json5Kind int
+
+const (
+	json5Null json5Kind = iota
+	json5Bool
+	json5Number
+	json5String
+	json5Object
+	json5Array
+)
+
+// json5Comment 表示一条注释
+type json5Comment struct {
+	text     string // 注释原文，包括 // 或 /* */
+	newlines int    // 注释之前的换行数
+}
+
+// json5Node 是保留注释的 JSON5 语法树节点
+type json5Node struct {
+	kind     json5Kind
+	text     string // 字符串值、数字原文或 true/false/null
+	key      string // 作为对象成员时的键
+	children []*json5Node
+	leading  []json5Comment // 位于节点之前的注释
+	trailing []json5Comment // 与节点同一行、位于其后的注释
+	inner    []json5Comment // 位于容器最后一个元素之后的注释
+	blank    bool           // 节点之前是否有空行
+}
+
+// json5Document 是完整的 JSON5 文档
+type json5Document struct {
+	root  *json5Node
+	after []json5Comment // 根值之后的注释
+}
+
+// json5Parser 是 JSON5 的递归下降解析器
+type json5Parser struct {
+	data     []byte
+	pos      int
+	pending  []json5Comment
+	newlines int // 上一个词法单元之后、下一个注释或词法单元之前的换行数
+	depth    int
+}
+
+// maxJSON5Depth 是允许的最大嵌套深度
+const maxJSON5Depth = 10000
+
+// parseJSON5Document 解析完整的 JSON5 文档
+func parseJSON5Document(data []byte) (*json5Document, error) {
+	p := &json5Parser{data: data}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	leading := p.takeComments()
+	root, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	root.leading = append(leading, root.leading...)
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	root.trailing = p.takeTrailing()
+	if p.pos < len(p.data) {
+		return nil, p.errorf("unexpected %s after top-level value", p.describe())
+	}
+	return &json5Document{root: root, after: p.takeComments()}, nil
+}
+
+// errorf 创建带有当前位置的语法错误
+func (p *json5Parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+// errorAt 创建带有指定位置的语法错误
+func (p *json5Parser) errorAt(pos int, format string, args ...interface{}) error {
+	line, col := 1, 1
+	for _, r := range string(p.data[:pos]) {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &JSON5SyntaxError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe 描述当前位置的字符，用于错误信息
+func (p *json5Parser) describe() string {
+	if p.pos >= len(p.data) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return strconv.QuoteRune(r)
+}
+
+// isJSON5Space 判断字符是否为 JSON5 空白
+func isJSON5Space(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', 0xA0, 0x2028, 0x2029, 0xFEFF:
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// isJSON5LineTerminator 判断字符是否为换行符
+func isJSON5LineTerminator(r rune) bool {
+	return r == '\n' || r == '\r' || r == 0x2028 || r == 0x2029
+}
+
+// skip 跳过空白并收集注释
+func (p *json5Parser) skip() error {
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		switch {
+		case r == '\r':
+			if p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+				size = 2
+			}
+			p.newlines++
+			p.pos += size
+		case isJSON5LineTerminator(r):
+			p.newlines++
+			p.pos += size
+		case isJSON5Space(r):
+			p.pos += size
+		case r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			start := p.pos
+			for p.pos < len(p.data) {
+				r, size := utf8.DecodeRune(p.data[p.pos:])
+				if isJSON5LineTerminator(r) {
+					break
+				}
+				p.pos += size
+			}
+			p.addComment(string(p.data[start:p.pos]))
+		case r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			start := p.pos
+			end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				return p.errorf("unterminated block comment")
+			}
+			p.pos += end + 4
+			p.addComment(string(p.data[start:p.pos]))
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// addComment 记录一条注释
+func (p *json5Parser) addComment(text string) {
+	p.pending = append(p.pending, json5Comment{text: text, newlines: p.newlines})
+	p.newlines = 0
+}
+
+// takeComments 取出所有待处理的注释
+func (p *json5Parser) takeComments() []json5Comment {
+	comments := p.pending
+	p.pending = nil
+	return comments
+}
+
+// takeTrailing 取出与上一个词法单元位于同一行的注释
+func (p *json5Parser) takeTrailing() []json5Comment {
+	n := 0
+	for n < len(p.pending) && p.pending[n].newlines == 0 {
+		n++
+	}
+	trailing := p.pending[:n:n]
+	p.pending = p.pending[n:]
+	if len(trailing) == 0 {
+		return nil
+	}
+	return trailing
+}
+
+// blankBefore 判断下一个元素（包括其前置注释）之前是否有空行
+func (p *json5Parser) blankBefore() bool {
+	if len(p.pending) > 0 {
+		return p.pending[0].newlines >= 2
+	}
+	return p.newlines >= 2
+}
+
+// token 标记一个词法单元的开始，重置换行计数
+func (p *json5Parser) token() {
+	p.newlines = 0
+}
+
+// parseValue 解析一个值
+func (p *json5Parser) parseValue() (*json5Node, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	p.token()
+	c := p.data[p.pos]
+	switch {
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"' || c == '\'':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &json5Node{kind: json5String, text: s}, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	}
+	word := p.scanIdentifierChars()
+	switch word {
+	case "true", "false":
+		return &json5Node{kind: json5Bool, text: word}, nil
+	case "null":
+		return &json5Node{kind: json5Null, text: word}, nil
+	case "Infinity", "NaN":
+		return &json5Node{kind: json5Number, text: word}, nil
+	case "":
+		return nil, p.errorf("unexpected %s, expected a value", p.describe())
+	}
+	return nil, p.errorAt(p.pos-len(word), "unexpected identifier %q, expected a value", word)
+}
+
+// scanIdentifierChars 读取连续的 ASCII 标识符字符
+func (p *json5Parser) scanIdentifierChars() string {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return string(p.data[start:p.pos])
+}
+
+// enter 增加嵌套深度
+func (p *json5Parser) enter() error {
+	p.depth++
+	if p.depth > maxJSON5Depth {
+		return p.errorf("exceeded max depth of %d", maxJSON5Depth)
+	}
+	return nil
+}
+
+// parseObject 解析对象
+func (p *json5Parser) parseObject() (*json5Node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	node := &json5Node{kind: json5Object}
+	p.pos++ // {
+	for {
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == '}' {
+			node.inner = p.takeComments()
+			p.pos++
+			p.token()
+			return node, nil
+		}
+		blank := len(node.children) > 0 && p.blankBefore()
+		leading := p.takeComments()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unexpected end of input, expected '}'")
+		}
+		p.token()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorf("unexpected %s, expected ':' after object key", p.describe())
+		}
+		p.pos++
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		// 键与值之间的注释归入成员之前
+		leading = append(leading, p.takeComments()...)
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		value.key = key
+		value.leading = leading
+		value.blank = blank
+		node.children = append(node.children, value)
+		more, err := p.afterElement(value, '}')
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if err := p.skip(); err != nil {
+				return nil, err
+			}
+			node.inner = p.takeComments()
+			if p.pos >= len(p.data) || p.data[p.pos] != '}' {
+				return nil, p.errorf("unexpected %s, expected ',' or '}'", p.describe())
+			}
+			p.pos++
+			p.token()
+			return node, nil
+		}
+	}
+}
+
+// parseArray 解析数组
+func (p *json5Parser) parseArray() (*json5Node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	node := &json5Node{kind: json5Array}
+	p.pos++ // [
+	for {
+		if err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == ']' {
+			node.inner = p.takeComments()
+			p.pos++
+			p.token()
+			return node, nil
+		}
+		blank := len(node.children) > 0 && p.blankBefore()
+		leading := p.takeComments()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		value.leading = leading
+		value.blank = blank
+		node.children = append(node.children, value)
+		more, err := p.afterElement(value, ']')
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if err := p.skip(); err != nil {
+				return nil, err
+			}
+			node.inner = p.takeComments()
+			if p.pos >= len(p.data) || p.data[p.pos] != ']' {
+				return nil, p.errorf("unexpected %s, expected ',' or ']'", p.describe())
+			}
+			p.pos++
+			p.token()
+			return node, nil
+		}
+	}
+}
+
+// afterElement 处理容器元素之后的逗号和同行注释，返回是否读到了逗号
+func (p *json5Parser) afterElement(value *json5Node, closer byte) (bool, error) {
+	if err := p.skip(); err != nil {
+		return false, err
+	}
+	value.trailing = p.takeTrailing()
+	if p.pos >= len(p.data) || p.data[p.pos] != ',' {
+		if len(p.pending) > 0 || p.pos >= len(p.data) || p.data[p.pos] == closer {
+			return false, nil
+		}
+		return false, p.errorf("unexpected %s, expected ',' or '%c'", p.describe(), closer)
+	}
+	p.pos++
+	p.token()
+	if err := p.skip(); err != nil {
+		return false, err
+	}
+	value.trailing = append(value.trailing, p.takeTrailing()...)
+	return true, nil
+}
+
+// parseKey 解析对象的键：字符串或标识符
+func (p *json5Parser) parseKey() (string, error) {
+	if c := p.data[p.pos]; c == '"' || c == '\'' {
+		return p.parseString()
+	}
+	start := p.pos
+	var sb strings.Builder
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r == '\\' {
+			if p.pos+1 >= len(p.data) || p.data[p.pos+1] != 'u' {
+				return "", p.errorf("invalid escape in identifier")
+			}
+			p.pos += 2
+			u, err := p.parseHex(4)
+			if err != nil {
+				return "", err
+			}
+			r, size = rune(u), 0
+			if !isIdentifierRune(r, sb.Len() == 0) {
+				return "", p.errorAt(start, "invalid identifier character %q", r)
+			}
+		} else if !isIdentifierRune(r, sb.Len() == 0) {
+			break
+		}
+		sb.WriteRune(r)
+		p.pos += size
+	}
+	if sb.Len() == 0 {
+		return "", p.errorf("unexpected %s, expected object key", p.describe())
+	}
+	return sb.String(), nil
+}
+
+// isIdentifierRune 判断字符能否出现在 ECMAScript 标识符中
+func isIdentifierRune(r rune, first bool) bool {
+	if r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) {
+		return true
+	}
+	if first {
+		return false
+	}
+	return unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) || r == 0x200C || r == 0x200D
+}
+
+// parseHex 读取 n 位十六进制数
+func (p *json5Parser) parseHex(n int) (int, error) {
+	if p.pos+n > len(p.data) {
+		return 0, p.errorf("incomplete hex escape")
+	}
+	v, err := strconv.ParseUint(string(p.data[p.pos:p.pos+n]), 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid hex escape %q", p.data[p.pos:p.pos+n])
+	}
+	p.pos += n
+	return int(v), nil
+}
+
+// parseString 解析单引号或双引号字符串
+func (p *json5Parser) parseString() (string, error) {
+	quote := p.data[p.pos]
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		switch {
+		case r == rune(quote):
+			p.pos++
+			return sb.String(), nil
+		case r == '\n' || r == '\r':
+			return "", p.errorf("unescaped line break in string")
+		case r == '\\':
+			p.pos++
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// parseEscape 解析字符串中的转义序列
+func (p *json5Parser) parseEscape(sb *strings.Builder) error {
+	if p.pos >= len(p.data) {
+		return p.errorf("unterminated string")
+	}
+	r, size := utf8.DecodeRune(p.data[p.pos:])
+	p.pos += size
+	switch r {
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'v':
+		sb.WriteByte('\v')
+	case '0':
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			return p.errorf("octal escape is not allowed")
+		}
+		sb.WriteByte(0)
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return p.errorAt(p.pos-1, "invalid escape \\%c", r)
+	case 'x':
+		v, err := p.parseHex(2)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(rune(v))
+	case 'u':
+		v, err := p.parseHex(4)
+		if err != nil {
+			return err
+		}
+		r := rune(v)
+		if utf16IsHighSurrogate(r) && p.pos+6 <= len(p.data) && p.data[p.pos] == '\\' && p.data[p.pos+1] == 'u' {
+			save := p.pos
+			p.pos += 2
+			low, err := p.parseHex(4)
+			if err == nil && utf16IsLowSurrogate(rune(low)) {
+				r = (r-0xD800)<<10 + (rune(low) - 0xDC00) + 0x10000
+			} else {
+				p.pos = save
+			}
+		}
+		sb.WriteRune(r)
+	case '\r':
+		// 行继续：跳过 \r\n 或 \r
+		if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+			p.pos++
+		}
+	case '\n', 0x2028, 0x2029:
+		// 行继续
+	default:
+		sb.WriteRune(r)
+	}
+	return nil
+}
+
+// utf16IsHighSurrogate 判断是否为 UTF-16 高位代理
+func utf16IsHighSurrogate(r rune) bool {
+	return r >= 0xD800 && r < 0xDC00
+}
+
+// utf16IsLowSurrogate 判断是否为 UTF-16 低位代理
+func utf16IsLowSurrogate(r rune) bool {
+	return r >= 0xDC00 && r < 0xE000
+}
+
+// parseNumber 解析数字，保留原文
+func (p *json5Parser) parseNumber() (*json5Node, error) {
+	start := p.pos
+	if c := p.data[p.pos]; c == '+' || c == '-' {
+		p.pos++
+	}
+	rest := p.data[p.pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("Infinity")), bytes.HasPrefix(rest, []byte("NaN")):
+		p.scanIdentifierChars()
+		word := string(p.data[start:p.pos])
+		if w := strings.TrimLeft(word, "+-"); w != "Infinity" && w != "NaN" {
+			return nil, p.errorAt(start, "invalid number %q", word)
+		}
+		return &json5Node{kind: json5Number, text: word}, nil
+	case len(rest) > 1 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X'):
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.data) && isHexDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == digits {
+			return nil, p.errorAt(start, "invalid hexadecimal number")
+		}
+	default:
+		intStart := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+		}
+		intDigits := p.pos - intStart
+		if intDigits > 1 && p.data[intStart] == '0' {
+			return nil, p.errorAt(start, "leading zeros are not allowed")
+		}
+		fracDigits := 0
+		if p.pos < len(p.data) && p.data[p.pos] == '.' {
+			p.pos++
+			for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+				p.pos++
+				fracDigits++
+			}
+		}
+		if intDigits == 0 && fracDigits == 0 {
+			return nil, p.errorAt(start, "invalid number")
+		}
+		if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+				p.pos++
+			}
+			expStart := p.pos
+			for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+				p.pos++
+			}
+			if p.pos == expStart {
+				return nil, p.errorAt(start, "invalid number exponent")
+			}
+		}
+	}
+	if p.pos < len(p.data) {
+		if r, _ := utf8.DecodeRune(p.data[p.pos:]); isIdentifierRune(r, false) {
+			return nil, p.errorf("unexpected %s after number", p.describe())
+		}
+	}
+	return &json5Node{kind: json5Number, text: string(p.data[start:p.pos])}, nil
+}
+
+// isHexDigit 判断是否为十六进制数字
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// jsonNumberText 将 JSON5 数字原文转换为标准 JSON 数字，非有限数返回 false
+func jsonNumberText(text string) (string, bool) {
+	sign := ""
+	if text[0] == '+' || text[0] == '-' {
+		if text[0] == '-' {
+			sign = "-"
+		}
+		text = text[1:]
+	}
+	if text == "Infinity" || text == "NaN" {
+		return "", false
+	}
+	if len(text) > 1 && (text[1] == 'x' || text[1] == 'X') {
+		n, _ := new(big.Int).SetString(text[2:], 16)
+		if n.Sign() == 0 {
+			sign = ""
+		}
+		return sign + n.String(), true
+	}
+	if strings.HasPrefix(text, ".") {
+		text = "0" + text
+	}
+	if i := strings.IndexByte(text, '.'); i >= 0 && (i+1 == len(text) || text[i+1] < '0' || text[i+1] > '9') {
+		text = text[:i] + text[i+1:]
+	}
+	return sign + text, true
+}
+
+// numberValue 返回数字节点的值，有限值为 json.Number，Infinity 和 NaN 为 float64
+func (n *json5Node) numberValue() interface{} {
+	text, ok := jsonNumberText(n.text)
+	if !ok {
+		switch n.text {
+		case "NaN", "+NaN", "-NaN":
+			return math.NaN()
+		case "-Infinity":
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+	return json.Number(text)
+}
+
+// toValue 将节点转换为 Go 值，重复的键以最后一个为准
+func (n *json5Node) toValue() interface{} {
+	switch n.kind {
+	case json5Bool:
+		return n.text == "true"
+	case json5Number:
+		return n.numberValue()
+	case json5String:
+		return n.text
+	case json5Object:
+		m := make(map[string]interface{}, len(n.children))
+		for _, c := range n.children {
+			m[c.key] = c.toValue()
+		}
+		return m
+	case json5Array:
+		arr := make([]interface{}, len(n.children))
+		for i, c := range n.children {
+			arr[i] = c.toValue()
+		}
+		return arr
+	}
+	return nil
+}
+
+// writeJSON 将节点写为紧凑的标准 JSON
+func (n *json5Node) writeJSON(buf *bytes.Buffer) error {
+	switch n.kind {
+	case json5Number:
+		text, ok := jsonNumberText(n.text)
+		if !ok {
+			return fmt.Errorf("json5: %s cannot be represented in JSON", n.text)
+		}
+		buf.WriteString(text)
+	case json5String:
+		buf.WriteString(quoteJSONString(n.text))
+	case json5Object:
+		buf.WriteByte('{')
+		for i, c := range n.children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(quoteJSONString(c.key))
+			buf.WriteByte(':')
+			if err := c.writeJSON(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case json5Array:
+		buf.WriteByte('[')
+		for i, c := range n.children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := c.writeJSON(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		buf.WriteString(n.text)
+	}
+	return nil
+}
+
+// quoteJSONString 将字符串编码为 JSON 字符串字面量，不转义 HTML 字符
+func quoteJSONString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// json5Formatter 输出保留注释的格式化文本
+type json5Formatter struct {
+	opts *json5FormatOptions
+	buf  bytes.Buffer
+}
+
+// newline 换行并缩进到指定层级
+func (f *json5Formatter) newline(depth int) {
+	f.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		f.buf.WriteString(f.opts.indent)
+	}
+}
+
+// writeComments 将前置注释逐行输出，每条注释之后换行
+func (f *json5Formatter) writeComments(comments []json5Comment, depth int) {
+	for _, c := range comments {
+		f.buf.WriteString(c.text)
+		f.newline(depth)
+	}
+}
+
+// writeTrailing 输出同一行的尾随注释
+func (f *json5Formatter) writeTrailing(comments []json5Comment) {
+	for _, c := range comments {
+		f.buf.WriteByte(' ')
+		f.buf.WriteString(c.text)
+	}
+}
+
+// writeKey 输出对象的键
+func (f *json5Formatter) writeKey(key string) {
+	if f.opts.unquotedKeys && isPlainIdentifier(key) {
+		f.buf.WriteString(key)
+	} else {
+		f.buf.WriteString(quoteJSONString(key))
+	}
+	f.buf.WriteString(": ")
+}
+
+// isPlainIdentifier 判断键是否为不需要引号的 ASCII 标识符，且不是保留字
+func isPlainIdentifier(s string) bool {
+	if s == "" || json5ReservedWords[s] {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// json5ReservedWords 是作为键时需要加引号以兼容 ES3 解析器的保留字
+var json5ReservedWords = map[string]bool{
+	"true": true, "false": true, "null": true, "Infinity": true, "NaN": true,
+}
+
+// writeNode 输出节点
+func (f *json5Formatter) writeNode(n *json5Node, depth int) {
+	switch n.kind {
+	case json5String:
+		f.buf.WriteString(quoteJSONString(n.text))
+		return
+	case json5Object, json5Array:
+	default:
+		f.buf.WriteString(n.text)
+		return
+	}
+	open, close := byte('['), byte(']')
+	if n.kind == json5Object {
+		open, close = '{', '}'
+	}
+	f.buf.WriteByte(open)
+	if len(n.children) == 0 && len(n.inner) == 0 {
+		f.buf.WriteByte(close)
+		return
+	}
+	for i, c := range n.children {
+		if c.blank {
+			f.buf.WriteByte('\n')
+		}
+		f.newline(depth + 1)
+		f.writeComments(c.leading, depth+1)
+		if n.kind == json5Object {
+			f.writeKey(c.key)
+		}
+		f.writeNode(c, depth+1)
+		if i < len(n.children)-1 || f.opts.trailingCommas {
+			f.buf.WriteByte(',')
+		}
+		f.writeTrailing(c.trailing)
+	}
+	for _, c := range n.inner {
+		f.newline(depth + 1)
+		f.buf.WriteString(c.text)
+	}
+	f.newline(depth)
+	f.buf.WriteByte(close)
+}
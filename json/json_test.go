@@ -0,0 +1,104 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGetValueByPath(t *testing.T) {
+	const doc = `{"store":{"book":[{"title":"a","price":8.95},{"title":"b","price":12}],"id":12345678901234567890}}`
+	tests := []struct {
+		path  string
+		want  interface{}
+		exact interface{}
+	}{
+		{path: "store.book.1.price", want: 12.0, exact: json.Number("12")},
+		{path: "store.book.*.title", want: []interface{}{"a", "b"}, exact: []interface{}{"a", "b"}},
+		{path: "$.store.book[0].price", want: 8.95, exact: json.Number("8.95")},
+		{path: "$..price", want: []interface{}{8.95, 12.0}, exact: []interface{}{json.Number("8.95"), json.Number("12")}},
+		{path: "store.id", want: 12345678901234567890.0, exact: json.Number("12345678901234567890")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := GetValueByPath(doc, tt.path)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetValueByPath() = %#v, %v, want %#v", got, err, tt.want)
+			}
+			got, err = GetValueByPathExact(doc, tt.path)
+			if err != nil || !reflect.DeepEqual(got, tt.exact) {
+				t.Errorf("GetValueByPathExact() = %#v, %v, want %#v", got, err, tt.exact)
+			}
+		})
+	}
+	for _, path := range []string{"store.missing", "store.book.5", "$.store.nope"} {
+		if _, err := GetValueByPath(doc, path); err == nil {
+			t.Errorf("GetValueByPath(%q) error = nil, want error", path)
+		}
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	got, err := MergeJSON(`{"b":1.50,"a":{"x":1},"n":12345678901234567890}`, `{"a":{"y":2},"c":[1]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"b":1.5,"a":{"x":1,"y":2},"n":12345678901234567000,"c":[1]}`; got != want {
+		t.Errorf("MergeJSON() = %s, want %s", got, want)
+	}
+	got, err = MergeJSONExact(`{"b":1.50,"a":{"x":1},"n":12345678901234567890}`, `{"a":{"y":2},"c":[1]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"b":1.50,"a":{"x":1,"y":2},"n":12345678901234567890,"c":[1]}`; got != want {
+		t.Errorf("MergeJSONExact() = %s, want %s", got, want)
+	}
+}
+
+func TestDiffNumberTypes(t *testing.T) {
+	a, b := `{"n":1,"o":{"k":2}}`, `{"n":3,"o":{"k":4,"z":[5]}}`
+	diff, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"n":   map[string]interface{}{"old": 1.0, "new": 3.0},
+		"o.k": map[string]interface{}{"old": 2.0, "new": 4.0},
+		"o.z": map[string]interface{}{"old": nil, "new": []interface{}{5.0}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("Diff() = %#v, want %#v", diff, want)
+	}
+	exact, err := DiffExact(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exact["n"].(map[string]interface{})["new"]; got != json.Number("3") {
+		t.Errorf("DiffExact() new = %#v, want json.Number", got)
+	}
+
+	diff, err = Diff(`{"o":{"k":1}}`, `{}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := diff["o"].(map[string]interface{})["old"].(map[string]interface{}); !ok {
+		t.Errorf("Diff() old = %#v, want map[string]interface{}", diff["o"])
+	}
+}
+
+func TestDeepCopyByJSON(t *testing.T) {
+	src := map[string]interface{}{"n": 1, "list": []int{1, 2}}
+	var dst, exact map[string]interface{}
+	if err := DeepCopyByJSON(src, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dst["n"].(float64); !ok {
+		t.Errorf("DeepCopyByJSON() n = %#v, want float64", dst["n"])
+	}
+	if err := DeepCopyByJSONExact(src, &exact); err != nil {
+		t.Fatal(err)
+	}
+	if exact["n"] != json.Number("1") {
+		t.Errorf("DeepCopyByJSONExact() n = %#v, want json.Number", exact["n"])
+	}
+}
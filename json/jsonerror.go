@@ -0,0 +1,237 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSnippetWidth 是错误片段中显示的最大字符数，超出时以出错位置为中心截取
+const maxSnippetWidth = 80
+
+// JSONError 描述 JSON 的语法错误或类型不匹配，包含文件名、行列号、出错字段的路径和源文本片段
+type JSONError struct {
+	File    string // 文件名，解析字符串时为空
+	Line    int    // 行号，从 1 开始
+	Column  int    // 列号（按字符计），从 1 开始
+	Offset  int64  // 出错位置的字节偏移
+	Path    string // 出错值的路径，如 server.ports[1]；根值为空
+	Msg     string // 错误描述
+	Snippet string // 出错的源文本行及其下方指向出错列的 ^ 标记
+	Err     error  // 底层的 *json.SyntaxError 或 *json.UnmarshalTypeError
+}
+
+// Error 实现 error 接口，只包含一行，源文本片段见 Snippet
+func (e *JSONError) Error() string {
+	var sb strings.Builder
+	if e.File != "" {
+		fmt.Fprintf(&sb, "%s:%d:%d: ", e.File, e.Line, e.Column)
+	} else {
+		fmt.Fprintf(&sb, "json error at line %d, column %d: ", e.Line, e.Column)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&sb, "%s: ", e.Path)
+	}
+	sb.WriteString(e.Msg)
+	return sb.String()
+}
+
+// Unwrap 返回底层错误
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// annotateJSONError 将 encoding/json 的语法错误和类型错误转换为带位置信息的 *JSONError，其他错误原样返回
+func annotateJSONError(data []byte, filename string, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		jsonErr   *JSONError
+		offset    int64
+		path      string
+	)
+	switch {
+	case errors.As(err, &jsonErr):
+		if jsonErr.File == "" {
+			jsonErr.File = filename
+		}
+		return err
+	case errors.As(err, &syntaxErr):
+		// Offset 为出错前已读取的字节数，出错的字符是最后读取的那一个
+		offset = syntaxErr.Offset - 1
+		if offset < 0 {
+			offset = 0
+		}
+		path, _ = locateJSONValue(data, -1)
+	case errors.As(err, &typeErr):
+		path, offset = locateJSONValue(data, typeErr.Offset)
+	default:
+		return err
+	}
+	line, col, snippet := sourceSnippet(data, offset)
+	return &JSONError{
+		File:    filename,
+		Line:    line,
+		Column:  col,
+		Offset:  offset,
+		Path:    path,
+		Msg:     strings.TrimPrefix(err.Error(), "json: "),
+		Snippet: snippet,
+		Err:     err,
+	}
+}
+
+// unmarshalConverted 解析由 YAML、TOML 等格式转换得到的 JSON，转换后的行列号对原文没有意义，
+// 因此类型错误只在前面加上出错值的路径；useNumber 为 true 时 interface{} 中的数字解码为 json.Number
+func unmarshalConverted(js []byte, v interface{}, useNumber bool) error {
+	dec := json.NewDecoder(bytes.NewReader(js))
+	if useNumber {
+		dec.UseNumber()
+	}
+	err := dec.Decode(v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if path, _ := locateJSONValue(js, typeErr.Offset); path != "" {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return err
+}
+
+// jsonFrame 是 locateJSONValue 遍历时的一层对象或数组
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+	index     int
+}
+
+// locateJSONValue 按词法单元遍历 data，返回结束位置不早于 end 的第一个值的路径和起始偏移；
+// end 为负数或遍历遇到语法错误时，返回出错处所在值的路径和已读取的偏移
+func locateJSONValue(data []byte, end int64) (string, int64) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []*jsonFrame
+	currentPath := func() string {
+		keys := make([]interface{}, 0, len(stack))
+		for i, f := range stack {
+			if f.object && f.expectKey && i == len(stack)-1 {
+				break
+			}
+			if f.object {
+				keys = append(keys, f.key)
+			} else {
+				keys = append(keys, f.index)
+			}
+		}
+		return dottedPath(keys)
+	}
+	// advance 在一个值结束后移动到父容器中的下一个位置
+	advance := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if f := stack[len(stack)-1]; f.object {
+			f.expectKey = true
+		} else {
+			f.index++
+		}
+	}
+	for {
+		prev := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				prev = int64(len(data))
+			}
+			return currentPath(), prev
+		}
+		start := skipJSONSeparators(data, prev)
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			advance()
+			continue
+		}
+		if len(stack) > 0 {
+			if f := stack[len(stack)-1]; f.object && f.expectKey {
+				f.key, _ = tok.(string)
+				f.expectKey = false
+				continue
+			}
+		}
+		// tok 是一个值的开始（容器）或完整的值（标量）
+		if end >= 0 && dec.InputOffset() >= end {
+			return currentPath(), start
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &jsonFrame{object: true, expectKey: true})
+		case json.Delim('['):
+			stack = append(stack, &jsonFrame{})
+		default:
+			advance()
+		}
+	}
+}
+
+// skipJSONSeparators 跳过空白、逗号和冒号，返回下一个词法单元的起始偏移
+func skipJSONSeparators(data []byte, pos int64) int64 {
+	for pos < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[pos]) >= 0 {
+		pos++
+	}
+	return pos
+}
+
+// sourceSnippet 计算偏移对应的行号和列号（按字符计），并生成带 ^ 标记的源文本片段
+func sourceSnippet(data []byte, offset int64) (line, col int, snippet string) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	lineEnd := bytes.IndexByte(data[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data)
+	} else {
+		lineEnd += int(offset)
+	}
+	line = bytes.Count(data[:offset], []byte{'\n'}) + 1
+	col = utf8.RuneCount(data[lineStart:offset]) + 1
+
+	runes := []rune(strings.TrimRight(string(data[lineStart:lineEnd]), "\r"))
+	from, to := 0, len(runes)
+	if to > maxSnippetWidth {
+		from = col - 1 - maxSnippetWidth/2
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxSnippetWidth
+		if to > len(runes) {
+			to, from = len(runes), len(runes)-maxSnippetWidth
+		}
+	}
+	var text, marker strings.Builder
+	if from > 0 {
+		text.WriteString("...")
+		marker.WriteString("   ")
+	}
+	for i := from; i < to; i++ {
+		text.WriteRune(runes[i])
+		if i < col-1 {
+			// 保留制表符，使 ^ 在终端中与出错的字符对齐
+			if runes[i] == '\t' {
+				marker.WriteByte('\t')
+			} else {
+				marker.WriteByte(' ')
+			}
+		}
+	}
+	if to < len(runes) {
+		text.WriteString("...")
+	}
+	marker.WriteByte('^')
+	return line, col, text.String() + "\n" + marker.String()
+}
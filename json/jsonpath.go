@@ -0,0 +1,1284 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSafeInteger 是 I-JSON 允许的最大整数（2^53-1）
+const maxSafeInteger = 1<<53 - 1
+
+// JSONPathError 表示 JSONPath 表达式的语法错误
+type JSONPathError struct {
+	Expr string // 原始表达式
+	Pos  int    // 出错位置（字节偏移）
+	Msg  string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("invalid JSONPath %q at position %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// JSONPath 是编译后的 JSONPath 查询（RFC 9535），可在多个文档上重复使用
+type JSONPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+// JSONPathNode 表示查询结果中的一个节点
+type JSONPathNode struct {
+	Location string      // 规范化路径，如 $['store']['book'][0]
+	Value    interface{} // 节点的值
+}
+
+// CompileJSONPath 编译 JSONPath 表达式
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &pathParser{expr: expr}
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{expr: expr, segments: segments}, nil
+}
+
+// MustCompileJSONPath 编译 JSONPath 表达式，失败时 panic
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String 返回原始表达式
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// IsSingular 判断查询是否最多只会返回一个节点
+func (p *JSONPath) IsSingular() bool {
+	return segmentsSingular(p.segments)
+}
+
+// Query 在已解析的文档上执行查询，返回所有匹配的值
+func (p *JSONPath) Query(data interface{}) []interface{} {
+	nodes := evalSegments(p.segments, data, &pathNode{value: data})
+	values := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.value
+	}
+	return values
+}
+
+// QueryNodes 在已解析的文档上执行查询，返回匹配的值及其规范化路径
+func (p *JSONPath) QueryNodes(data interface{}) []JSONPathNode {
+	nodes := evalSegments(p.segments, data, &pathNode{value: data})
+	result := make([]JSONPathNode, len(nodes))
+	for i, n := range nodes {
+		result[i] = JSONPathNode{Location: n.location(), Value: n.value}
+	}
+	return result
+}
+
+// QueryJSON 在 JSON 字符串上执行查询
+func (p *JSONPath) QueryJSON(jsonStr string) ([]interface{}, error) {
+	data, err := decodeAny([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(data), nil
+}
+
+// First 返回第一个匹配的值
+func (p *JSONPath) First(data interface{}) (interface{}, bool) {
+	values := p.Query(data)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// QueryJSONPath 使用 JSONPath 表达式查询已解析的文档
+func QueryJSONPath(data interface{}, expr string) ([]interface{}, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(data), nil
+}
+
+// QueryJSONPathString 使用 JSONPath 表达式查询 JSON 字符串
+func QueryJSONPathString(jsonStr, expr string) ([]interface{}, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryJSON(jsonStr)
+}
+
+// pathNode 表示求值过程中的节点，通过父指针记录其位置
+type pathNode struct {
+	value  interface{}
+	parent *pathNode
+	key    interface{} // string 表示对象成员，int 表示数组下标
+}
+
+// child 创建子节点
+func (n *pathNode) child(key interface{}, value interface{}) *pathNode {
+	return &pathNode{value: value, parent: n, key: key}
+}
+
+// keys 返回从根到当前节点的键序列
+func (n *pathNode) keys() []interface{} {
+	var keys []interface{}
+	for cur := n; cur.parent != nil; cur = cur.parent {
+		keys = append(keys, cur.key)
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+// location 返回节点的规范化路径
+func (n *pathNode) location() string {
+	return normalizedPath(n.keys())
+}
+
+// normalizedPath 将键序列格式化为 RFC 9535 规范化路径
+func normalizedPath(keys []interface{}) string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, k := range keys {
+		switch key := k.(type) {
+		case int:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(key))
+			sb.WriteByte(']')
+		case string:
+			sb.WriteString("['")
+			for _, r := range key {
+				switch r {
+				case '\'':
+					sb.WriteString(`\'`)
+				case '\\':
+					sb.WriteString(`\\`)
+				case '\b':
+					sb.WriteString(`\b`)
+				case '\f':
+					sb.WriteString(`\f`)
+				case '\n':
+					sb.WriteString(`\n`)
+				case '\r':
+					sb.WriteString(`\r`)
+				case '\t':
+					sb.WriteString(`\t`)
+				default:
+					if r < 0x20 {
+						fmt.Fprintf(&sb, `\u%04x`, r)
+					} else {
+						sb.WriteRune(r)
+					}
+				}
+			}
+			sb.WriteString("']")
+		}
+	}
+	return sb.String()
+}
+
+// pathSegment 表示一个子段或后代段
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+// pathSelector 从节点中选择子节点
+type pathSelector interface {
+	selectFrom(root interface{}, n *pathNode, out []*pathNode) []*pathNode
+}
+
+// evalSegments 依次对节点应用各个段
+func evalSegments(segments []pathSegment, root interface{}, start *pathNode) []*pathNode {
+	nodes := []*pathNode{start}
+	for _, seg := range segments {
+		var out []*pathNode
+		for _, n := range nodes {
+			if seg.descendant {
+				walkDescendants(n, func(d *pathNode) {
+					for _, sel := range seg.selectors {
+						out = sel.selectFrom(root, d, out)
+					}
+				})
+				continue
+			}
+			for _, sel := range seg.selectors {
+				out = sel.selectFrom(root, n, out)
+			}
+		}
+		nodes = out
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// walkDescendants 按文档顺序访问节点本身及其所有后代
+func walkDescendants(n *pathNode, visit func(*pathNode)) {
+	visit(n)
+	switch v := n.value.(type) {
+	case map[string]interface{}, *JSONObject:
+		for _, k := range objectKeys(v) {
+			item, _ := objectGet(v, k)
+			walkDescendants(n.child(k, item), visit)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkDescendants(n.child(i, item), visit)
+		}
+	}
+}
+
+// segmentsSingular 判断段序列是否为单值查询
+func segmentsSingular(segments []pathSegment) bool {
+	for _, seg := range segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// nameSelector 选择对象成员
+type nameSelector struct {
+	name string
+}
+
+func (s nameSelector) selectFrom(_ interface{}, n *pathNode, out []*pathNode) []*pathNode {
+	if v, ok := objectGet(n.value, s.name); ok {
+		out = append(out, n.child(s.name, v))
+	}
+	return out
+}
+
+// wildcardSelector 选择所有子节点
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectFrom(_ interface{}, n *pathNode, out []*pathNode) []*pathNode {
+	switch v := n.value.(type) {
+	case map[string]interface{}, *JSONObject:
+		for _, k := range objectKeys(v) {
+			item, _ := objectGet(v, k)
+			out = append(out, n.child(k, item))
+		}
+	case []interface{}:
+		for i, item := range v {
+			out = append(out, n.child(i, item))
+		}
+	}
+	return out
+}
+
+// indexSelector 选择数组元素，负数表示从末尾开始计数
+type indexSelector struct {
+	index int
+}
+
+func (s indexSelector) selectFrom(_ interface{}, n *pathNode, out []*pathNode) []*pathNode {
+	if arr, ok := n.value.([]interface{}); ok {
+		i := s.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i >= 0 && i < len(arr) {
+			out = append(out, n.child(i, arr[i]))
+		}
+	}
+	return out
+}
+
+// sliceSelector 选择数组切片 [start:end:step]
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) selectFrom(_ interface{}, n *pathNode, out []*pathNode) []*pathNode {
+	arr, ok := n.value.([]interface{})
+	if !ok || s.step == 0 {
+		return out
+	}
+	length := len(arr)
+	normalize := func(i int) int {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int) int {
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+	if s.step > 0 {
+		start, end := 0, length
+		if s.start != nil {
+			start = normalize(*s.start)
+		}
+		if s.end != nil {
+			end = normalize(*s.end)
+		}
+		lower, upper := clamp(start, 0, length), clamp(end, 0, length)
+		for i := lower; i < upper; i += s.step {
+			out = append(out, n.child(i, arr[i]))
+		}
+		return out
+	}
+	start, end := length-1, -length-1
+	if s.start != nil {
+		start = normalize(*s.start)
+	}
+	if s.end != nil {
+		end = normalize(*s.end)
+	}
+	upper, lower := clamp(start, -1, length-1), clamp(end, -1, length-1)
+	for i := upper; lower < i; i += s.step {
+		out = append(out, n.child(i, arr[i]))
+	}
+	return out
+}
+
+// filterSelector 选择满足过滤表达式的子节点
+type filterSelector struct {
+	expr logicalExpr
+}
+
+func (s filterSelector) selectFrom(root interface{}, n *pathNode, out []*pathNode) []*pathNode {
+	switch v := n.value.(type) {
+	case map[string]interface{}, *JSONObject:
+		for _, k := range objectKeys(v) {
+			item, _ := objectGet(v, k)
+			if s.expr.test(root, item) {
+				out = append(out, n.child(k, item))
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if s.expr.test(root, item) {
+				out = append(out, n.child(i, item))
+			}
+		}
+	}
+	return out
+}
+
+// nothingValue 表示过滤表达式中不存在的值（RFC 9535 中的 Nothing）
+type nothingValue struct{}
+
+var nothing = nothingValue{}
+
+// logicalExpr 是返回布尔结果的过滤表达式
+type logicalExpr interface {
+	test(root, current interface{}) bool
+}
+
+// orExpr 表示逻辑或
+type orExpr []logicalExpr
+
+func (e orExpr) test(root, current interface{}) bool {
+	for _, x := range e {
+		if x.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// andExpr 表示逻辑与
+type andExpr []logicalExpr
+
+func (e andExpr) test(root, current interface{}) bool {
+	for _, x := range e {
+		if !x.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// notExpr 表示逻辑非
+type notExpr struct {
+	expr logicalExpr
+}
+
+func (e notExpr) test(root, current interface{}) bool {
+	return !e.expr.test(root, current)
+}
+
+// existExpr 判断查询结果是否非空
+type existExpr struct {
+	query *filterQuery
+}
+
+func (e existExpr) test(root, current interface{}) bool {
+	return len(e.query.nodes(root, current)) > 0
+}
+
+// funcTestExpr 将返回逻辑值的函数用作测试表达式
+type funcTestExpr struct {
+	call *funcCall
+}
+
+func (e funcTestExpr) test(root, current interface{}) bool {
+	b, _ := e.call.eval(root, current).(bool)
+	return b
+}
+
+// compareExpr 表示比较表达式
+type compareExpr struct {
+	left, right comparableExpr
+	op          string
+}
+
+func (e compareExpr) test(root, current interface{}) bool {
+	l, r := e.left.value(root, current), e.right.value(root, current)
+	switch e.op {
+	case "==":
+		return filterEqual(l, r)
+	case "!=":
+		return !filterEqual(l, r)
+	case "<":
+		return filterLess(l, r)
+	case "<=":
+		return filterLess(l, r) || filterEqual(l, r)
+	case ">":
+		return filterLess(r, l)
+	case ">=":
+		return filterLess(r, l) || filterEqual(l, r)
+	}
+	return false
+}
+
+// filterEqual 按 RFC 9535 语义比较相等
+func filterEqual(a, b interface{}) bool {
+	_, na := a.(nothingValue)
+	_, nb := b.(nothingValue)
+	if na || nb {
+		return na && nb
+	}
+	return valuesEqual(a, b)
+}
+
+// filterLess 按 RFC 9535 语义比较大小，仅对数值和字符串有效
+func filterLess(a, b interface{}) bool {
+	if c, ok := compareNumbers(a, b); ok {
+		return c < 0
+	}
+	sa, ok := a.(string)
+	if !ok {
+		return false
+	}
+	sb, ok := b.(string)
+	return ok && sa < sb
+}
+
+// comparableExpr 是比较表达式的操作数
+type comparableExpr interface {
+	value(root, current interface{}) interface{}
+}
+
+// literalExpr 表示字面量
+type literalExpr struct {
+	v interface{}
+}
+
+func (e literalExpr) value(_, _ interface{}) interface{} {
+	return e.v
+}
+
+// filterQuery 表示过滤表达式中的查询，relative 为 true 时以 @ 开头
+type filterQuery struct {
+	relative bool
+	segments []pathSegment
+}
+
+func (q *filterQuery) nodes(root, current interface{}) []*pathNode {
+	start := root
+	if q.relative {
+		start = current
+	}
+	return evalSegments(q.segments, root, &pathNode{value: start})
+}
+
+// value 返回单值查询的结果，不存在时返回 nothing
+func (q *filterQuery) value(root, current interface{}) interface{} {
+	nodes := q.nodes(root, current)
+	if len(nodes) != 1 {
+		return nothing
+	}
+	return nodes[0].value
+}
+
+// funcCall 表示函数扩展调用
+type funcCall struct {
+	name  string
+	args  []interface{} // *filterQuery、literalExpr、*funcCall 或 logicalExpr
+	regex *regexp.Regexp
+}
+
+// funcResultKind 描述函数的返回类型
+type funcResultKind int
+
+const (
+	funcValueResult funcResultKind = iota
+	funcLogicalResult
+)
+
+// jsonPathFuncs 定义了 RFC 9535 内置函数的返回类型和参数个数
+var jsonPathFuncs = map[string]struct {
+	result funcResultKind
+	argc   int
+}{
+	"length": {funcValueResult, 1},
+	"count":  {funcValueResult, 1},
+	"value":  {funcValueResult, 1},
+	"match":  {funcLogicalResult, 2},
+	"search": {funcLogicalResult, 2},
+}
+
+func (c *funcCall) value(root, current interface{}) interface{} {
+	return c.eval(root, current)
+}
+
+// argValue 以 ValueType 语义求参数的值
+func (c *funcCall) argValue(i int, root, current interface{}) interface{} {
+	switch a := c.args[i].(type) {
+	case *filterQuery:
+		return a.value(root, current)
+	case comparableExpr:
+		return a.value(root, current)
+	}
+	return nothing
+}
+
+// argNodes 以 NodesType 语义求参数的值
+func (c *funcCall) argNodes(i int, root, current interface{}) []*pathNode {
+	if q, ok := c.args[i].(*filterQuery); ok {
+		return q.nodes(root, current)
+	}
+	return nil
+}
+
+func (c *funcCall) eval(root, current interface{}) interface{} {
+	switch c.name {
+	case "length":
+		switch v := c.argValue(0, root, current).(type) {
+		case string:
+			return utf8.RuneCountInString(v)
+		case []interface{}:
+			return len(v)
+		case map[string]interface{}, *JSONObject:
+			return objectLen(v)
+		}
+		return nothing
+	case "count":
+		return len(c.argNodes(0, root, current))
+	case "value":
+		nodes := c.argNodes(0, root, current)
+		if len(nodes) == 1 {
+			return nodes[0].value
+		}
+		return nothing
+	case "match", "search":
+		s, ok := c.argValue(0, root, current).(string)
+		if !ok {
+			return false
+		}
+		re := c.regex
+		if re == nil {
+			pattern, ok := c.argValue(1, root, current).(string)
+			if !ok {
+				return false
+			}
+			var err error
+			if re, err = compileIRegexp(pattern, c.name == "match"); err != nil {
+				return false
+			}
+		}
+		return re.MatchString(s)
+	}
+	return nothing
+}
+
+// compileIRegexp 编译 I-Regexp（RFC 9485），full 为 true 时要求整体匹配
+func compileIRegexp(pattern string, full bool) (*regexp.Regexp, error) {
+	if full {
+		pattern = `\A(?:` + pattern + `)\z`
+	}
+	return regexp.Compile(pattern)
+}
+
+// pathParser 是 JSONPath 表达式的递归下降解析器
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return &JSONPathError{Expr: p.expr, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *pathParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *pathParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *pathParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], s)
+}
+
+func (p *pathParser) skipBlank() {
+	for !p.eof() {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseQuery 解析完整的 JSONPath 查询
+func (p *pathParser) parseQuery() ([]pathSegment, error) {
+	if p.peek() != '$' {
+		return nil, p.errorf("query must start with '$'")
+	}
+	p.pos++
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected character %q", p.peek())
+	}
+	return segments, nil
+}
+
+// parseSegments 解析零个或多个段
+func (p *pathParser) parseSegments() ([]pathSegment, error) {
+	var segments []pathSegment
+	for {
+		save := p.pos
+		p.skipBlank()
+		if p.eof() || (p.peek() != '.' && p.peek() != '[') {
+			p.pos = save
+			return segments, nil
+		}
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+// parseSegment 解析单个子段或后代段
+func (p *pathParser) parseSegment() (pathSegment, error) {
+	var seg pathSegment
+	if p.hasPrefix("..") {
+		p.pos += 2
+		seg.descendant = true
+		if p.peek() == '[' {
+			sels, err := p.parseBracketed()
+			seg.selectors = sels
+			return seg, err
+		}
+		sel, err := p.parseShorthand()
+		seg.selectors = []pathSelector{sel}
+		return seg, err
+	}
+	if p.peek() == '.' {
+		p.pos++
+		sel, err := p.parseShorthand()
+		seg.selectors = []pathSelector{sel}
+		return seg, err
+	}
+	sels, err := p.parseBracketed()
+	seg.selectors = sels
+	return seg, err
+}
+
+// parseShorthand 解析点号后面的通配符或成员名
+func (p *pathParser) parseShorthand() (pathSelector, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return wildcardSelector{}, nil
+	}
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		isFirst := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r >= 0x80
+		if !isFirst && !(p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected member name or '*'")
+	}
+	return nameSelector{name: p.expr[start:p.pos]}, nil
+}
+
+// parseBracketed 解析方括号中的选择器列表
+func (p *pathParser) parseBracketed() ([]pathSelector, error) {
+	p.pos++ // '['
+	var sels []pathSelector
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return sels, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseSelector 解析方括号中的单个选择器
+func (p *pathParser) parseSelector() (pathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{name: s}, nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: expr}, nil
+	case c == ':' || c == '-' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.errorf("invalid selector")
+}
+
+// parseIndexOrSlice 解析下标选择器或切片选择器
+func (p *pathParser) parseIndexOrSlice() (pathSelector, error) {
+	var bounds [3]*int
+	n := 0
+	for {
+		p.skipBlank()
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			v, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			bounds[n] = &v
+		}
+		p.skipBlank()
+		if p.peek() != ':' || n == 2 {
+			break
+		}
+		p.pos++
+		n++
+	}
+	if n == 0 {
+		if bounds[0] == nil {
+			return nil, p.errorf("expected index")
+		}
+		return indexSelector{index: *bounds[0]}, nil
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	return sliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+// parseInt 解析 I-JSON 范围内的整数
+func (p *pathParser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	text := p.expr[start:p.pos]
+	if p.pos == digits || (p.expr[digits] == '0' && p.pos-digits > 1) || text == "-0" {
+		p.pos = start
+		return 0, p.errorf("invalid integer")
+	}
+	v, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || v > maxSafeInteger || v < -maxSafeInteger {
+		p.pos = start
+		return 0, p.errorf("integer out of range")
+	}
+	return int(v), nil
+}
+
+// parseString 解析单引号或双引号字符串字面量
+func (p *pathParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated escape")
+			}
+			e := p.expr[p.pos]
+			p.pos++
+			switch e {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '/', '\\':
+				sb.WriteByte(e)
+			case '\'', '"':
+				if e != quote {
+					return "", p.errorf("invalid escape %q", "\\"+string(e))
+				}
+				sb.WriteByte(e)
+			case 'u':
+				r, err := p.parseUnicodeEscape()
+				if err != nil {
+					return "", err
+				}
+				sb.WriteRune(r)
+			default:
+				return "", p.errorf("invalid escape %q", "\\"+string(e))
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseUnicodeEscape 解析 \uXXXX 转义（已消费 \u），支持代理对
+func (p *pathParser) parseUnicodeEscape() (rune, error) {
+	hex4 := func() (rune, error) {
+		if p.pos+4 > len(p.expr) {
+			return 0, p.errorf("invalid unicode escape")
+		}
+		v, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+		if err != nil {
+			return 0, p.errorf("invalid unicode escape")
+		}
+		p.pos += 4
+		return rune(v), nil
+	}
+	r, err := hex4()
+	if err != nil {
+		return 0, err
+	}
+	if r >= 0xD800 && r < 0xDC00 {
+		if !p.hasPrefix(`\u`) {
+			return 0, p.errorf("unpaired surrogate")
+		}
+		p.pos += 2
+		lo, err := hex4()
+		if err != nil {
+			return 0, err
+		}
+		if lo < 0xDC00 || lo > 0xDFFF {
+			return 0, p.errorf("unpaired surrogate")
+		}
+		return (r-0xD800)<<10 + (lo - 0xDC00) + 0x10000, nil
+	}
+	if r >= 0xDC00 && r <= 0xDFFF {
+		return 0, p.errorf("unpaired surrogate")
+	}
+	return r, nil
+}
+
+// parseLogicalOr 解析 || 连接的表达式
+func (p *pathParser) parseLogicalOr() (logicalExpr, error) {
+	var terms orExpr
+	for {
+		term, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		p.skipBlank()
+		if !p.hasPrefix("||") {
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// parseLogicalAnd 解析 && 连接的表达式
+func (p *pathParser) parseLogicalAnd() (logicalExpr, error) {
+	var terms andExpr
+	for {
+		term, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		p.skipBlank()
+		if !p.hasPrefix("&&") {
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+// parseBasic 解析括号表达式、比较表达式或测试表达式
+func (p *pathParser) parseBasic() (logicalExpr, error) {
+	negate := false
+	if p.peek() == '!' && !p.hasPrefix("!=") {
+		p.pos++
+		p.skipBlank()
+		negate = true
+	}
+	wrap := func(e logicalExpr) logicalExpr {
+		if negate {
+			return notExpr{expr: e}
+		}
+		return e
+	}
+	if p.peek() == '(' {
+		p.pos++
+		p.skipBlank()
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return wrap(expr), nil
+	}
+
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	save := p.pos
+	p.skipBlank()
+	op := p.parseCompareOp()
+	if op == "" {
+		p.pos = save
+		switch v := left.(type) {
+		case *filterQuery:
+			return wrap(existExpr{query: v}), nil
+		case *funcCall:
+			if jsonPathFuncs[v.name].result != funcLogicalResult {
+				p.pos = start
+				return nil, p.errorf("function %s() cannot be used as a test", v.name)
+			}
+			return wrap(funcTestExpr{call: v}), nil
+		}
+		p.pos = start
+		return nil, p.errorf("literal cannot be used as a test")
+	}
+	if negate {
+		p.pos = start
+		return nil, p.errorf("comparison cannot be negated without parentheses")
+	}
+	p.skipBlank()
+	rightStart := p.pos
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	l, err := p.comparable(left, start)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.comparable(right, rightStart)
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{left: l, right: r, op: op}, nil
+}
+
+// comparable 检查操作数能否用于比较
+func (p *pathParser) comparable(operand interface{}, pos int) (comparableExpr, error) {
+	switch v := operand.(type) {
+	case literalExpr:
+		return v, nil
+	case *filterQuery:
+		if !segmentsSingular(v.segments) {
+			p.pos = pos
+			return nil, p.errorf("non-singular query in comparison")
+		}
+		return v, nil
+	case *funcCall:
+		if jsonPathFuncs[v.name].result != funcValueResult {
+			p.pos = pos
+			return nil, p.errorf("function %s() cannot be used in comparison", v.name)
+		}
+		return v, nil
+	}
+	p.pos = pos
+	return nil, p.errorf("invalid comparison operand")
+}
+
+// parseCompareOp 解析比较运算符，不匹配时返回空字符串
+func (p *pathParser) parseCompareOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// parseOperand 解析查询、函数调用或字面量
+func (p *pathParser) parseOperand() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return &filterQuery{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalExpr{v: s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for !p.eof() {
+			c := p.peek()
+			if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '_' {
+				break
+			}
+			p.pos++
+		}
+		name := p.expr[start:p.pos]
+		if p.peek() == '(' {
+			return p.parseFuncCall(name, start)
+		}
+		switch name {
+		case "true":
+			return literalExpr{v: true}, nil
+		case "false":
+			return literalExpr{v: false}, nil
+		case "null":
+			return literalExpr{v: nil}, nil
+		}
+		p.pos = start
+		return nil, p.errorf("unexpected identifier %q", name)
+	}
+	return nil, p.errorf("expected filter operand")
+}
+
+// parseNumber 解析 JSON 数值字面量
+func (p *pathParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if p.pos == digits || (p.expr[digits] == '0' && p.pos-digits > 1) {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	if p.peek() == '.' {
+		p.pos++
+		frac := p.pos
+		for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+			p.pos++
+		}
+		if p.pos == frac {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		exp := p.pos
+		for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+			p.pos++
+		}
+		if p.pos == exp {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	return literalExpr{v: json.Number(p.expr[start:p.pos])}, nil
+}
+
+// parseFuncCall 解析函数调用参数并校验类型
+func (p *pathParser) parseFuncCall(name string, start int) (interface{}, error) {
+	def, ok := jsonPathFuncs[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	p.pos++ // '('
+	call := &funcCall{name: name}
+	p.skipBlank()
+	for p.peek() != ')' {
+		argStart := p.pos
+		arg, err := p.parseFuncArg()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkFuncArg(call.name, len(call.args), arg, argStart); err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		p.skipBlank()
+		if p.peek() == ',' {
+			p.pos++
+			p.skipBlank()
+			continue
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+	p.pos++
+	if len(call.args) != def.argc {
+		p.pos = start
+		return nil, p.errorf("function %s() expects %d argument(s)", name, def.argc)
+	}
+	if name == "match" || name == "search" {
+		if lit, ok := call.args[1].(literalExpr); ok {
+			pattern, _ := lit.v.(string)
+			re, err := compileIRegexp(pattern, name == "match")
+			if err != nil {
+				p.pos = start
+				return nil, p.errorf("invalid regular expression: %v", err)
+			}
+			call.regex = re
+		}
+	}
+	return call, nil
+}
+
+// parseFuncArg 解析单个函数参数
+func (p *pathParser) parseFuncArg() (interface{}, error) {
+	if c := p.peek(); c == '!' || c == '(' {
+		return p.parseLogicalOr()
+	}
+	save := p.pos
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	end := p.pos
+	p.skipBlank()
+	if p.hasPrefix("&&") || p.hasPrefix("||") || p.parseCompareOp() != "" {
+		p.pos = save
+		return p.parseLogicalOr()
+	}
+	p.pos = end
+	return operand, nil
+}
+
+// checkFuncArg 校验函数参数的类型
+func (p *pathParser) checkFuncArg(name string, index int, arg interface{}, pos int) error {
+	fail := func(want string) error {
+		p.pos = pos
+		return p.errorf("argument %d of %s() must be %s", index+1, name, want)
+	}
+	switch name {
+	case "count", "value":
+		if _, ok := arg.(*filterQuery); !ok {
+			return fail("a query")
+		}
+	default:
+		switch v := arg.(type) {
+		case literalExpr:
+		case *filterQuery:
+			if !segmentsSingular(v.segments) {
+				return fail("a singular query")
+			}
+		case *funcCall:
+			if jsonPathFuncs[v.name].result != funcValueResult {
+				return fail("a value")
+			}
+		default:
+			return fail("a value")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package jsonutil
+
+import (
+	"testing"
+)
+
+// rfc9535Bookstore 是 RFC 9535 第 1.5 节的示例文档
+const rfc9535Bookstore = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+// queryJSONPathText 在按键顺序解码的文档上执行查询，以 JSON 数组文本返回结果，便于与期望值比较
+func queryJSONPathText(t *testing.T, doc, expr string) string {
+	t.Helper()
+	data, err := DecodeOrdered([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := QueryJSONPath(data, expr)
+	if err != nil {
+		t.Fatalf("QueryJSONPath(%q) error = %v", expr, err)
+	}
+	if values == nil {
+		values = []interface{}{}
+	}
+	out, err := ToJSON(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestJSONPathRFC9535(t *testing.T) {
+	const (
+		names       = `{"o": {"j j": {"k.k": 3}}, "'": {"@": 2}}`
+		wildcard    = `{"o": {"j": 1, "k": 2}, "a": [5, 3]}`
+		letters     = `["a", "b", "c", "d", "e", "f", "g"]`
+		filter      = `{"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}], "o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}}, "e": "f"}`
+		descendants = `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`
+		nulls       = `{"a": null, "b": [null], "c": [{}], "null": 1}`
+	)
+	tests := []struct {
+		name string
+		doc  string
+		expr string
+		want string
+	}{
+		// 第 1.5 节
+		{name: "authors", doc: rfc9535Bookstore, expr: `$.store.book[*].author`, want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "all authors", doc: rfc9535Bookstore, expr: `$..author`, want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "store members", doc: rfc9535Bookstore, expr: `$.store.*`, want: `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],{"color":"red","price":399}]`},
+		{name: "store prices", doc: rfc9535Bookstore, expr: `$.store..price`, want: `[8.95,12.99,8.99,22.99,399]`},
+		{name: "third book", doc: rfc9535Bookstore, expr: `$..book[2].title`, want: `["Moby Dick"]`},
+		{name: "third book author", doc: rfc9535Bookstore, expr: `$..book[2].author`, want: `["Herman Melville"]`},
+		{name: "missing member", doc: rfc9535Bookstore, expr: `$..book[2].publisher`, want: `[]`},
+		{name: "last book", doc: rfc9535Bookstore, expr: `$..book[-1].title`, want: `["The Lord of the Rings"]`},
+		{name: "first two books by index", doc: rfc9535Bookstore, expr: `$..book[0,1].title`, want: `["Sayings of the Century","Sword of Honour"]`},
+		{name: "first two books by slice", doc: rfc9535Bookstore, expr: `$..book[:2].title`, want: `["Sayings of the Century","Sword of Honour"]`},
+		{name: "books with isbn", doc: rfc9535Bookstore, expr: `$..book[?@.isbn].title`, want: `["Moby Dick","The Lord of the Rings"]`},
+		{name: "cheap books", doc: rfc9535Bookstore, expr: `$..book[?@.price<10].title`, want: `["Sayings of the Century","Moby Dick"]`},
+
+		// 第 2.3.1.3 节
+		{name: "name with space", doc: names, expr: `$.o['j j']`, want: `[{"k.k":3}]`},
+		{name: "nested names", doc: names, expr: `$.o['j j']['k.k']`, want: `[3]`},
+		{name: "double quoted names", doc: names, expr: `$.o["j j"]["k.k"]`, want: `[3]`},
+		{name: "quote names", doc: names, expr: `$["'"]["@"]`, want: `[2]`},
+
+		// 第 2.3.2.3 节
+		{name: "root wildcard", doc: wildcard, expr: `$[*]`, want: `[{"j":1,"k":2},[5,3]]`},
+		{name: "object wildcard", doc: wildcard, expr: `$.o[*]`, want: `[1,2]`},
+		{name: "repeated wildcard", doc: wildcard, expr: `$.o[*, *]`, want: `[1,2,1,2]`},
+		{name: "array wildcard", doc: wildcard, expr: `$.a[*]`, want: `[5,3]`},
+
+		// 第 2.3.3.3 节
+		{name: "index", doc: `["a","b"]`, expr: `$[1]`, want: `["b"]`},
+		{name: "negative index", doc: `["a","b"]`, expr: `$[-2]`, want: `["a"]`},
+
+		// 第 2.3.4.3 节
+		{name: "slice", doc: letters, expr: `$[1:3]`, want: `["b","c"]`},
+		{name: "slice to end", doc: letters, expr: `$[5:]`, want: `["f","g"]`},
+		{name: "slice with step", doc: letters, expr: `$[1:5:2]`, want: `["b","d"]`},
+		{name: "slice with negative step", doc: letters, expr: `$[5:1:-2]`, want: `["f","d"]`},
+		{name: "reverse", doc: letters, expr: `$[::-1]`, want: `["g","f","e","d","c","b","a"]`},
+
+		// 第 2.3.5.3 节
+		{name: "filter equal", doc: filter, expr: `$.a[?@.b == 'kilo']`, want: `[{"b":"kilo"}]`},
+		{name: "filter parenthesized", doc: filter, expr: `$.a[?(@.b == 'kilo')]`, want: `[{"b":"kilo"}]`},
+		{name: "filter greater", doc: filter, expr: `$.a[?@>3.5]`, want: `[5,4,6]`},
+		{name: "filter existence", doc: filter, expr: `$.a[?@.b]`, want: `[{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}]`},
+		{name: "filter non-empty", doc: filter, expr: `$[?@.*]`, want: `[[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}],{"p":1,"q":2,"r":3,"s":5,"t":{"u":6}}]`},
+		{name: "nested filter", doc: filter, expr: `$[?@[?@.b]]`, want: `[[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}]]`},
+		{name: "repeated filter", doc: filter, expr: `$.o[?@<3, ?@<3]`, want: `[1,2,1,2]`},
+		{name: "logical or", doc: filter, expr: `$.a[?@<2 || @.b == "k"]`, want: `[1,{"b":"k"}]`},
+		{name: "match", doc: filter, expr: `$.a[?match(@.b, "[jk]")]`, want: `[{"b":"j"},{"b":"k"}]`},
+		{name: "search", doc: filter, expr: `$.a[?search(@.b, "[jk]")]`, want: `[{"b":"j"},{"b":"k"},{"b":"kilo"}]`},
+		{name: "logical and", doc: filter, expr: `$.o[?@>1 && @<4]`, want: `[2,3]`},
+		{name: "existence or", doc: filter, expr: `$.o[?@.u || @.x]`, want: `[{"u":6}]`},
+		{name: "nothing equals nothing", doc: filter, expr: `$.a[?@.b == $.x]`, want: `[3,5,1,2,4,6]`},
+		{name: "self equal", doc: filter, expr: `$.a[?@ == @]`, want: `[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}]`},
+
+		// 第 2.4 节函数扩展
+		{name: "length", doc: rfc9535Bookstore, expr: `$.store.book[?length(@.author) > 12].author`, want: `["Herman Melville","J. R. R. Tolkien"]`},
+		{name: "count", doc: rfc9535Bookstore, expr: `$.store[?count(@.*) == 2].color`, want: `["red"]`},
+		{name: "value", doc: `[{"c":["red"]},{"c":["red"],"d":{"c":["red"]}}]`, expr: `$[?value(@..c[0]) == "red"].c`, want: `[["red"]]`},
+
+		// 第 2.5.2.3 节
+		{name: "descendant member", doc: descendants, expr: `$..j`, want: `[1,4]`},
+		{name: "descendant index", doc: descendants, expr: `$..[0]`, want: `[5,{"j":4}]`},
+		{name: "descendant wildcard", doc: descendants, expr: `$..*`, want: `[{"j":1,"k":2},[5,3,[{"j":4},{"k":6}]],1,2,5,3,[{"j":4},{"k":6}],{"j":4},{"k":6},4,6]`},
+		{name: "descendant of member", doc: descendants, expr: `$..o`, want: `[{"j":1,"k":2}]`},
+		{name: "descendant repeated wildcard", doc: descendants, expr: `$.o..[*, *]`, want: `[1,2,1,2]`},
+		{name: "descendant indexes", doc: descendants, expr: `$.a..[0, 1]`, want: `[5,3,{"j":4},{"k":6}]`},
+
+		// 第 2.6.1 节
+		{name: "null member", doc: nulls, expr: `$.a`, want: `[null]`},
+		{name: "index on null", doc: nulls, expr: `$.a[0]`, want: `[]`},
+		{name: "member of null", doc: nulls, expr: `$.a.d`, want: `[]`},
+		{name: "null element", doc: nulls, expr: `$.b[0]`, want: `[null]`},
+		{name: "null wildcard", doc: nulls, expr: `$.b[*]`, want: `[null]`},
+		{name: "null existence", doc: nulls, expr: `$.b[?@]`, want: `[null]`},
+		{name: "null comparison", doc: nulls, expr: `$.b[?@==null]`, want: `[null]`},
+		{name: "missing is not null", doc: nulls, expr: `$.c[?@.d==null]`, want: `[]`},
+		{name: "member named null", doc: nulls, expr: `$.null`, want: `[1]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryJSONPathText(t, tt.doc, tt.expr); got != tt.want {
+				t.Errorf("%s = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathNormalizedPaths(t *testing.T) {
+	// RFC 9535 第 2.7 节
+	tests := []struct {
+		doc  string
+		expr string
+		want []string
+	}{
+		{doc: `{"a":1}`, expr: `$.a`, want: []string{`$['a']`}},
+		{doc: `["x","y"]`, expr: `$[1]`, want: []string{`$[1]`}},
+		{doc: `["x","y","z"]`, expr: `$[-1]`, want: []string{`$[2]`}},
+		{doc: `{"a":{"b":[0,1]}}`, expr: `$.a.b[1:2]`, want: []string{`$['a']['b'][1]`}},
+		{doc: `{"\u000b":1}`, expr: `$["\u000B"]`, want: []string{`$['\u000b']`}},
+		{doc: `{"'":1}`, expr: `$["'"]`, want: []string{`$['\'']`}},
+		{doc: `{"o":{"j":1},"a":[2]}`, expr: `$..*`, want: []string{`$['o']`, `$['a']`, `$['o']['j']`, `$['a'][0]`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			data, err := DecodeOrdered([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes := MustCompileJSONPath(tt.expr).QueryNodes(data)
+			if len(nodes) != len(tt.want) {
+				t.Fatalf("QueryNodes() returned %d nodes, want %d", len(nodes), len(tt.want))
+			}
+			for i, n := range nodes {
+				if n.Location != tt.want[i] {
+					t.Errorf("node %d location = %s, want %s", i, n.Location, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`store`,
+		`$.`,
+		`$[01]`,
+		`$[-0]`,
+		`$['a'`,
+		`$[?@.* == 1]`,
+		`$[?length(@.*) < 3]`,
+		`$[?count(1) == 1]`,
+		`$[?match(@.a, 'x') == true]`,
+		`$[?value(@..c)]`,
+		`$[?unknown(@)]`,
+	} {
+		if _, err := CompileJSONPath(expr); err == nil {
+			t.Errorf("CompileJSONPath(%q) error = nil, want error", expr)
+		}
+	}
+}
+
+func TestJSONPathSingular(t *testing.T) {
+	for expr, want := range map[string]bool{
+		`$`:           true,
+		`$.a[0]['b']`: true,
+		`$.a[*]`:      false,
+		`$..a`:        false,
+		`$.a[0,1]`:    false,
+	} {
+		if got := MustCompileJSONPath(expr).IsSingular(); got != want {
+			t.Errorf("IsSingular(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
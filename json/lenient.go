@@ -0,0 +1,576 @@
+package jsonutil
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Coercion 描述宽松解码时进行的一次类型转换
+type Coercion struct {
+	Path  string      // 值的路径，如 items[0].price，根值为空
+	From  string      // 原始值的 JSON 类型
+	To    string      // 目标 Go 类型
+	Value interface{} // 原始值，数字为 json.Number
+}
+
+// String 返回转换的描述，如 items[0].price: string "12.5" -> float64
+func (c Coercion) String() string {
+	return fmt.Sprintf("%s: %s %s -> %s", pathLabel(c.Path), c.From, lenientValueText(c.Value), c.To)
+}
+
+// lenientOptions 保存宽松解码的选项
+type lenientOptions struct {
+	layouts   []string
+	location  *time.Location
+	epochUnit time.Duration
+}
+
+// LenientOption 是设置宽松解码选项的函数类型
+type LenientOption func(*lenientOptions)
+
+// WithTimeLayouts 设置解析时间字符串时依次尝试的格式，如 datetime.DateTimeSecond；RFC 3339 总是最先尝试
+func WithTimeLayouts(layouts ...string) LenientOption {
+	return func(o *lenientOptions) {
+		o.layouts = layouts
+	}
+}
+
+// WithTimeLocation 设置不含时区的时间字符串所在的时区以及时间戳转换后的时区，默认为 time.Local
+func WithTimeLocation(loc *time.Location) LenientOption {
+	return func(o *lenientOptions) {
+		o.location = loc
+	}
+}
+
+// WithEpochUnit 设置数字形式的时间戳的单位，默认为毫秒，可为 time.Second、time.Microsecond 等
+func WithEpochUnit(unit time.Duration) LenientOption {
+	return func(o *lenientOptions) {
+		o.epochUnit = unit
+	}
+}
+
+// UnmarshalLenient 宽松地将 JSON 解析到 v 中，返回进行过的全部类型转换
+// 字段映射规则与 Unmarshal 相同，在此基础上：字符串、数字和布尔值之间按需转换（如 "123"、"true"、1），
+// 带小数的数字不会截断为整数；time.Time 接受 RFC 3339、WithTimeLayouts 设置的格式和数字时间戳，
+// time.Duration 接受 "1m30s" 形式的字符串；非字符串类型的字段遇到空字符串时设为零值
+func UnmarshalLenient(data []byte, v interface{}, opts ...LenientOption) ([]Coercion, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	tree, err := decodeOrderedBytes(data, true)
+	if err != nil {
+		return nil, annotateJSONError(data, "", err)
+	}
+	d := &lenientDecoder{opts: lenientOptions{layouts: lenientTimeLayouts, location: time.Local, epochUnit: time.Millisecond}}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	if d.opts.epochUnit <= 0 {
+		d.opts.epochUnit = time.Millisecond
+	}
+	if err := d.decode(nil, tree, rv.Elem(), false); err != nil {
+		return d.coercions, err
+	}
+	return d.coercions, nil
+}
+
+// FromJSONLenient 宽松地将 JSON 字符串解析到 v 中，见 UnmarshalLenient
+func FromJSONLenient(jsonStr string, v interface{}, opts ...LenientOption) ([]Coercion, error) {
+	return UnmarshalLenient([]byte(jsonStr), v, opts...)
+}
+
+// lenientDecoder 保存一次宽松解码的状态
+type lenientDecoder struct {
+	opts      lenientOptions
+	coercions []Coercion
+}
+
+// lenientFieldCache 缓存结构体类型的字段列表
+var lenientFieldCache sync.Map
+
+// cachedStructFields 返回结构体的 JSON 字段，结果按类型缓存
+func cachedStructFields(t reflect.Type) []fieldInfo {
+	if f, ok := lenientFieldCache.Load(t); ok {
+		return f.([]fieldInfo)
+	}
+	fields := structFields(t)
+	lenientFieldCache.Store(t, fields)
+	return fields
+}
+
+// pathLabel 返回用于错误和转换描述的路径，根值显示为 (root)
+func pathLabel(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// lenientValueText 返回原始值的 JSON 文本，用于错误和转换描述
+func lenientValueText(v interface{}) string {
+	switch c := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteJSONString(c)
+	case *JSONObject:
+		return "{...}"
+	case []interface{}:
+		return "[...]"
+	}
+	return fmt.Sprint(v)
+}
+
+// childKeys 返回追加了一段路径的新键序列，不修改原切片
+func childKeys(keys []interface{}, key interface{}) []interface{} {
+	return append(keys[:len(keys):len(keys)], key)
+}
+
+// coerced 记录一次类型转换
+func (d *lenientDecoder) coerced(keys []interface{}, src interface{}, t reflect.Type) {
+	d.coercions = append(d.coercions, Coercion{Path: dottedPath(keys), From: jsonTypeName(src), To: t.String(), Value: src})
+}
+
+// mismatch 返回无法转换的错误
+func (d *lenientDecoder) mismatch(keys []interface{}, src interface{}, t reflect.Type) error {
+	return fmt.Errorf("%s: cannot convert %s %s to %s", pathLabel(dottedPath(keys)), jsonTypeName(src), lenientValueText(src), t)
+}
+
+// decode 将 src 解码到 dst 中，quoted 表示字段带有 ,string 标签选项
+func (d *lenientDecoder) decode(keys []interface{}, src interface{}, dst reflect.Value, quoted bool) error {
+	t := dst.Type()
+	if src == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			dst.Set(reflect.Zero(t))
+		}
+		return nil
+	}
+	if s, ok := src.(string); ok && s == "" && !acceptsEmptyString(t) {
+		dst.Set(reflect.Zero(t))
+		d.coerced(keys, src, t)
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(t.Elem()))
+		}
+		return d.decode(keys, src, dst.Elem(), quoted)
+	}
+
+	switch {
+	case t == timeType:
+		return d.decodeTime(keys, src, dst)
+	case t == durationType:
+		if s, ok := src.(string); ok {
+			if dur, err := time.ParseDuration(strings.TrimSpace(s)); err == nil {
+				dst.SetInt(int64(dur))
+				d.coerced(keys, src, t)
+				return nil
+			}
+		}
+	case t == jsonNumberType:
+		switch c := src.(type) {
+		case json.Number:
+			dst.SetString(string(c))
+			return nil
+		case string:
+			if s := strings.TrimSpace(c); isJSONNumberText(s) {
+				dst.SetString(s)
+				d.coerced(keys, src, t)
+				return nil
+			}
+		}
+		return d.mismatch(keys, src, t)
+	case t == decimalType:
+		n, ok := lenientDecimal(src)
+		if !ok {
+			return d.mismatch(keys, src, t)
+		}
+		dst.Set(reflect.ValueOf(n))
+		if _, isString := src.(string); isString {
+			d.coerced(keys, src, t)
+		}
+		return nil
+	case reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		data, err := Marshal(src)
+		if err != nil {
+			return err
+		}
+		if err := dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("%s: %w", pathLabel(dottedPath(keys)), err)
+		}
+		return nil
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		text, ok := lenientString(src)
+		switch src.(type) {
+		case *JSONObject, []interface{}:
+			ok = false
+		}
+		if !ok {
+			return d.mismatch(keys, src, t)
+		}
+		if _, isString := src.(string); !isString {
+			d.coerced(keys, src, t)
+		}
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("%s: %w", pathLabel(dottedPath(keys)), err)
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return d.mismatch(keys, src, t)
+		}
+		dst.Set(reflect.ValueOf(toPlainTree(src, false)))
+		return nil
+	case reflect.Bool:
+		return d.decodeBool(keys, src, dst, quoted)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.decodeInt(keys, src, dst, quoted)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return d.decodeUint(keys, src, dst, quoted)
+	case reflect.Float32, reflect.Float64:
+		return d.decodeFloat(keys, src, dst, quoted)
+	case reflect.String:
+		return d.decodeString(keys, src, dst)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := src.(string); ok {
+				// []byte 与 encoding/json 一致按 base64 解码
+				data, _ := Marshal(src)
+				if err := json.Unmarshal(data, dst.Addr().Interface()); err != nil {
+					return fmt.Errorf("%s: %w", pathLabel(dottedPath(keys)), err)
+				}
+				return nil
+			}
+		}
+		arr, ok := src.([]interface{})
+		if !ok {
+			return d.mismatch(keys, src, t)
+		}
+		out := reflect.MakeSlice(t, len(arr), len(arr))
+		for i, item := range arr {
+			if err := d.decode(childKeys(keys, i), item, out.Index(i), false); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Array:
+		arr, ok := src.([]interface{})
+		if !ok {
+			return d.mismatch(keys, src, t)
+		}
+		for i := 0; i < dst.Len(); i++ {
+			if i >= len(arr) {
+				dst.Index(i).Set(reflect.Zero(t.Elem()))
+				continue
+			}
+			if err := d.decode(childKeys(keys, i), arr[i], dst.Index(i), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		return d.decodeMap(keys, src, dst)
+	case reflect.Struct:
+		return d.decodeStruct(keys, src, dst)
+	}
+	return d.mismatch(keys, src, t)
+}
+
+// acceptsEmptyString 判断类型是否能直接接收空字符串，不能接收的类型遇到空字符串时设为零值
+func acceptsEmptyString(t reflect.Type) bool {
+	t = derefType(t)
+	if t == jsonNumberType || t == timeType || t == decimalType {
+		return false
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// decodeBool 解码布尔值，接受 "true"、"yes"、"1" 等字符串以及数字 0 和 1
+func (d *lenientDecoder) decodeBool(keys []interface{}, src interface{}, dst reflect.Value, quoted bool) error {
+	switch c := src.(type) {
+	case bool:
+		dst.SetBool(c)
+		return nil
+	case string:
+		if b, ok := lenientBool(c); ok {
+			dst.SetBool(b)
+			if !quoted || (c != "true" && c != "false") {
+				d.coerced(keys, src, dst.Type())
+			}
+			return nil
+		}
+	case json.Number:
+		switch c.String() {
+		case "0", "1":
+			dst.SetBool(c == "1")
+			d.coerced(keys, src, dst.Type())
+			return nil
+		}
+	}
+	return d.mismatch(keys, src, dst.Type())
+}
+
+// lenientNumberText 返回数字或字符串形式的数字文本，以及是否无需记录转换
+func lenientNumberText(src interface{}, quoted bool) (string, bool, bool) {
+	switch c := src.(type) {
+	case json.Number:
+		return c.String(), true, true
+	case string:
+		return strings.TrimSpace(c), quoted && isJSONNumberText(c), true
+	case bool:
+		if c {
+			return "1", false, true
+		}
+		return "0", false, true
+	}
+	return "", false, false
+}
+
+// integralValue 将数字文本解析为整数值对应的 float64，带小数或无法解析时返回 false
+func integralValue(text string) (float64, bool) {
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || f != math.Trunc(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// decodeInt 解码有符号整数
+func (d *lenientDecoder) decodeInt(keys []interface{}, src interface{}, dst reflect.Value, quoted bool) error {
+	text, native, ok := lenientNumberText(src, quoted)
+	if !ok {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		f, ok := integralValue(text)
+		if !ok || f < math.MinInt64 || f >= math.MaxInt64 {
+			return d.mismatch(keys, src, dst.Type())
+		}
+		n, native = int64(f), false
+	}
+	if dst.OverflowInt(n) {
+		return fmt.Errorf("%s: value %s overflows %s", pathLabel(dottedPath(keys)), text, dst.Type())
+	}
+	dst.SetInt(n)
+	if !native {
+		d.coerced(keys, src, dst.Type())
+	}
+	return nil
+}
+
+// decodeUint 解码无符号整数
+func (d *lenientDecoder) decodeUint(keys []interface{}, src interface{}, dst reflect.Value, quoted bool) error {
+	text, native, ok := lenientNumberText(src, quoted)
+	if !ok {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	n, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		f, ok := integralValue(text)
+		if !ok || f < 0 || f >= math.MaxUint64 {
+			return d.mismatch(keys, src, dst.Type())
+		}
+		n, native = uint64(f), false
+	}
+	if dst.OverflowUint(n) {
+		return fmt.Errorf("%s: value %s overflows %s", pathLabel(dottedPath(keys)), text, dst.Type())
+	}
+	dst.SetUint(n)
+	if !native {
+		d.coerced(keys, src, dst.Type())
+	}
+	return nil
+}
+
+// decodeFloat 解码浮点数，不接受 NaN 和 Inf
+func (d *lenientDecoder) decodeFloat(keys []interface{}, src interface{}, dst reflect.Value, quoted bool) error {
+	text, native, ok := lenientNumberText(src, quoted)
+	if !ok {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	f, err := strconv.ParseFloat(text, dst.Type().Bits())
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	dst.SetFloat(f)
+	if !native {
+		d.coerced(keys, src, dst.Type())
+	}
+	return nil
+}
+
+// decodeString 解码字符串，数字和布尔值转换为其文本形式
+func (d *lenientDecoder) decodeString(keys []interface{}, src interface{}, dst reflect.Value) error {
+	switch c := src.(type) {
+	case string:
+		dst.SetString(c)
+		return nil
+	case json.Number:
+		dst.SetString(c.String())
+	case bool:
+		dst.SetString(strconv.FormatBool(c))
+	default:
+		return d.mismatch(keys, src, dst.Type())
+	}
+	d.coerced(keys, src, dst.Type())
+	return nil
+}
+
+// decodeTime 解码时间，RFC 3339 字符串无需转换，其余格式和数字时间戳记录为转换
+func (d *lenientDecoder) decodeTime(keys []interface{}, src interface{}, dst reflect.Value) error {
+	var text string
+	switch c := src.(type) {
+	case string:
+		text = strings.TrimSpace(c)
+		if t, err := time.Parse(time.RFC3339, c); err == nil {
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+		for _, layout := range d.opts.layouts {
+			if t, err := time.ParseInLocation(layout, text, d.opts.location); err == nil {
+				dst.Set(reflect.ValueOf(t))
+				d.coerced(keys, src, dst.Type())
+				return nil
+			}
+		}
+	case json.Number:
+		text = c.String()
+	default:
+		return d.mismatch(keys, src, dst.Type())
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	dst.Set(reflect.ValueOf(d.epochTime(n)))
+	d.coerced(keys, src, dst.Type())
+	return nil
+}
+
+// epochTime 按 WithEpochUnit 设置的单位将时间戳转换为时间
+func (d *lenientDecoder) epochTime(n int64) time.Time {
+	unit := d.opts.epochUnit
+	if unit >= time.Second {
+		return time.Unix(n*int64(unit/time.Second), 0).In(d.opts.location)
+	}
+	perSecond := int64(time.Second / unit)
+	return time.Unix(n/perSecond, n%perSecond*int64(unit)).In(d.opts.location)
+}
+
+// decodeMap 解码映射，键可以是字符串、整数或实现了 encoding.TextUnmarshaler 的类型
+func (d *lenientDecoder) decodeMap(keys []interface{}, src interface{}, dst reflect.Value) error {
+	obj, ok := src.(*JSONObject)
+	if !ok {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	t := dst.Type()
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(t, obj.Len()))
+	}
+	for _, k := range obj.keys {
+		key := reflect.New(t.Key()).Elem()
+		switch {
+		case reflect.PtrTo(t.Key()).Implements(textUnmarshalerType):
+			if err := key.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+				return fmt.Errorf("%s: invalid map key %q: %w", pathLabel(dottedPath(keys)), k, err)
+			}
+		case t.Key().Kind() == reflect.String:
+			key.SetString(k)
+		default:
+			if err := d.decode(childKeys(keys, k), json.Number(k), key, false); err != nil {
+				return fmt.Errorf("%s: invalid map key %q for %s", pathLabel(dottedPath(keys)), k, t.Key())
+			}
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(childKeys(keys, k), obj.values[k], elem, false); err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// decodeStruct 解码结构体，键优先精确匹配字段名，其次不区分大小写匹配，未知的键被忽略
+func (d *lenientDecoder) decodeStruct(keys []interface{}, src interface{}, dst reflect.Value) error {
+	obj, ok := src.(*JSONObject)
+	if !ok {
+		return d.mismatch(keys, src, dst.Type())
+	}
+	fields := cachedStructFields(dst.Type())
+	for _, k := range obj.keys {
+		f := findLenientField(fields, k)
+		if f == nil {
+			continue
+		}
+		fv, ok := fieldByIndexAlloc(dst, f.index)
+		if !ok || !fv.CanSet() {
+			continue
+		}
+		if err := d.decode(childKeys(keys, k), obj.values[k], fv, f.asString); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findLenientField 查找键对应的字段
+func findLenientField(fields []fieldInfo, key string) *fieldInfo {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// fieldByIndexAlloc 按索引路径获取字段，途经的 nil 嵌入指针会被分配；未导出的嵌入指针无法分配时返回 false
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
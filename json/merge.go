@@ -0,0 +1,188 @@
+package jsonutil
+
+// ArrayMergeStrategy 定义深度合并时数组的合并策略
+type ArrayMergeStrategy int
+
+const (
+	// ArrayReplace 用新数组替换旧数组（默认，与 RFC 7396 一致）
+	ArrayReplace ArrayMergeStrategy = iota
+	// ArrayAppend 将新数组的元素追加到旧数组之后
+	ArrayAppend
+	// ArrayMergeByKey 按键字段匹配对象元素并递归合并，未匹配的元素追加到末尾
+	ArrayMergeByKey
+)
+
+// mergeOptions 保存深度合并的选项
+type mergeOptions struct {
+	nullDelete    bool
+	arrayStrategy ArrayMergeStrategy
+	mergeKey      string
+}
+
+// MergeOption 是设置合并选项的函数类型
+type MergeOption func(*mergeOptions)
+
+// WithNullAsDelete 将补丁中的 null 视为删除对应的键（RFC 7396 语义）
+func WithNullAsDelete() MergeOption {
+	return func(o *mergeOptions) {
+		o.nullDelete = true
+	}
+}
+
+// WithArrayStrategy 设置数组的合并策略
+func WithArrayStrategy(strategy ArrayMergeStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.arrayStrategy = strategy
+	}
+}
+
+// WithArrayMergeKey 按指定键字段合并数组中的对象元素，如 "id"
+func WithArrayMergeKey(key string) MergeOption {
+	return func(o *mergeOptions) {
+		o.arrayStrategy = ArrayMergeByKey
+		o.mergeKey = key
+	}
+}
+
+// DeepMerge 将 src 深度合并到 dst，src 中的值优先，返回新值且不修改输入
+func DeepMerge(dst, src interface{}, opts ...MergeOption) interface{} {
+	o := &mergeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return deepMerge(dst, src, o)
+}
+
+// deepMerge 递归合并两个值
+// 任一方为 *JSONObject 时结果也是 *JSONObject：保留 dst 的键顺序，新键按 src 中的顺序追加
+func deepMerge(dst, src interface{}, o *mergeOptions) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}, *JSONObject:
+		if !isObject(s) {
+			return src
+		}
+		if !isObject(dst) {
+			if !o.nullDelete {
+				return cloneValue(s)
+			}
+			dst = nil
+		}
+		_, srcOrdered := s.(*JSONObject)
+		_, dstOrdered := dst.(*JSONObject)
+		result := newObject(srcOrdered || dstOrdered)
+		for _, k := range objectKeys(dst) {
+			v, _ := objectGet(dst, k)
+			objectSet(result, k, v)
+		}
+		for _, k := range objectKeys(s) {
+			v, _ := objectGet(s, k)
+			if v == nil && o.nullDelete {
+				objectDelete(result, k)
+				continue
+			}
+			old, _ := objectGet(result, k)
+			objectSet(result, k, deepMerge(old, v, o))
+		}
+		return result
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			return cloneValue(s)
+		}
+		switch o.arrayStrategy {
+		case ArrayAppend:
+			result := make([]interface{}, 0, len(d)+len(s))
+			result = append(result, d...)
+			return append(result, cloneValue(s).([]interface{})...)
+		case ArrayMergeByKey:
+			return mergeArrayByKey(d, s, o)
+		}
+		return cloneValue(s)
+	}
+	return src
+}
+
+// mergeArrayByKey 按键字段合并两个数组
+func mergeArrayByKey(dst, src []interface{}, o *mergeOptions) []interface{} {
+	result := make([]interface{}, len(dst), len(dst)+len(src))
+	copy(result, dst)
+	for _, item := range src {
+		merged := false
+		if key, ok := objectGet(item, o.mergeKey); ok {
+			for i, existing := range result {
+				if ek, ok := objectGet(existing, o.mergeKey); ok && valuesEqual(ek, key) {
+					result[i] = deepMerge(existing, item, o)
+					merged = true
+					break
+				}
+			}
+		}
+		if !merged {
+			result = append(result, deepMerge(nil, item, o))
+		}
+	}
+	return result
+}
+
+// MergePatch 按 RFC 7396 将合并补丁应用到 target，返回新值且不修改输入
+func MergePatch(target, patch interface{}) interface{} {
+	return DeepMerge(target, patch, WithNullAsDelete())
+}
+
+// MergePatchJSON 按 RFC 7396 将 JSON 合并补丁应用到 JSON 字符串
+func MergePatchJSON(targetJSON, patchJSON string) (string, error) {
+	target, err := DecodeOrdered([]byte(targetJSON))
+	if err != nil {
+		return "", err
+	}
+	patch, err := DecodeOrdered([]byte(patchJSON))
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(MergePatch(target, patch))
+}
+
+// CreateMergePatch 生成将 original 转换为 modified 的 RFC 7396 合并补丁
+// 注意：合并补丁无法表示将值设置为 null，modified 中的 null 会被当作删除
+func CreateMergePatch(original, modified interface{}) interface{} {
+	if !isObject(original) || !isObject(modified) {
+		return cloneValue(modified)
+	}
+	_, ordered := modified.(*JSONObject)
+	patch := newObject(ordered)
+	for _, k := range objectKeys(original) {
+		if _, ok := objectGet(modified, k); !ok {
+			objectSet(patch, k, nil)
+		}
+	}
+	for _, k := range objectKeys(modified) {
+		mv, _ := objectGet(modified, k)
+		ov, ok := objectGet(original, k)
+		if !ok {
+			objectSet(patch, k, cloneValue(mv))
+			continue
+		}
+		if valuesEqual(ov, mv) {
+			continue
+		}
+		if isObject(ov) && isObject(mv) {
+			objectSet(patch, k, CreateMergePatch(ov, mv))
+		} else {
+			objectSet(patch, k, cloneValue(mv))
+		}
+	}
+	return patch
+}
+
+// CreateMergePatchJSON 生成将 json1 转换为 json2 的 RFC 7396 合并补丁
+func CreateMergePatchJSON(json1, json2 string) (string, error) {
+	original, err := DecodeOrdered([]byte(json1))
+	if err != nil {
+		return "", err
+	}
+	modified, err := DecodeOrdered([]byte(json2))
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(CreateMergePatch(original, modified))
+}
@@ -0,0 +1,163 @@
+package jsonutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+// RFC 7396 附录 A 的示例
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.patch, func(t *testing.T) {
+			got, err := MergePatchJSON(tt.target, tt.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("MergePatchJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// RFC 7396 第 3 节的示例
+func TestMergePatchRFC7396Section3(t *testing.T) {
+	target := `{
+		"title": "Goodbye!",
+		"author": {"givenName": "John", "familyName": "Doe"},
+		"tags": ["example", "sample"],
+		"content": "This will be unchanged"
+	}`
+	patch := `{
+		"title": "Hello!",
+		"phoneNumber": "+01-123-456-7890",
+		"author": {"familyName": null},
+		"tags": ["example"]
+	}`
+	want := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`
+	got, err := MergePatchJSON(target, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("MergePatchJSON() = %s, want %s", got, want)
+	}
+
+	created, err := CreateMergePatchJSON(target, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jsonEqual(created, patch) {
+		t.Errorf("CreateMergePatchJSON() = %s, want %s", created, patch)
+	}
+}
+
+func TestCreateMergePatchRoundTrip(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{a: `{"a":"b"}`, b: `{"a":"c"}`},
+		{a: `{"a":"b","b":"c"}`, b: `{"b":"c"}`},
+		{a: `{"a":{"b":"c","d":[1]}}`, b: `{"a":{"b":"d","d":[1,2]},"e":{}}`},
+		{a: `{"a":"b"}`, b: `["c"]`},
+		{a: `[1,2]`, b: `{"a":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			patch, err := CreateMergePatchJSON(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := MergePatchJSON(tt.a, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(got, tt.b) {
+				t.Errorf("MergePatchJSON(%s) = %s, want %s", patch, got, tt.b)
+			}
+		})
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"a":     map[string]interface{}{"x": 1, "y": 2},
+		"list":  []interface{}{1},
+		"items": []interface{}{map[string]interface{}{"id": 1, "v": "a"}},
+		"n":     "keep",
+	}
+	src := map[string]interface{}{
+		"a":     map[string]interface{}{"y": 3},
+		"list":  []interface{}{2},
+		"items": []interface{}{map[string]interface{}{"id": 1, "v": "b"}, map[string]interface{}{"id": 2}},
+		"n":     nil,
+	}
+	tests := []struct {
+		name string
+		opts []MergeOption
+		want map[string]interface{}
+	}{
+		{
+			name: "default",
+			want: map[string]interface{}{
+				"a":     map[string]interface{}{"x": 1, "y": 3},
+				"list":  []interface{}{2},
+				"items": []interface{}{map[string]interface{}{"id": 1, "v": "b"}, map[string]interface{}{"id": 2}},
+				"n":     nil,
+			},
+		},
+		{
+			name: "append and null delete",
+			opts: []MergeOption{WithArrayStrategy(ArrayAppend), WithNullAsDelete()},
+			want: map[string]interface{}{
+				"a":    map[string]interface{}{"x": 1, "y": 3},
+				"list": []interface{}{1, 2},
+				"items": []interface{}{
+					map[string]interface{}{"id": 1, "v": "a"},
+					map[string]interface{}{"id": 1, "v": "b"},
+					map[string]interface{}{"id": 2},
+				},
+			},
+		},
+		{
+			name: "merge by key",
+			opts: []MergeOption{WithArrayMergeKey("id")},
+			want: map[string]interface{}{
+				"a":     map[string]interface{}{"x": 1, "y": 3},
+				"list":  []interface{}{1, 2},
+				"items": []interface{}{map[string]interface{}{"id": 1, "v": "b"}, map[string]interface{}{"id": 2}},
+				"n":     nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DeepMerge(dst, src, tt.opts...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeepMerge() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+	if !reflect.DeepEqual(dst["a"], map[string]interface{}{"x": 1, "y": 2}) {
+		t.Errorf("DeepMerge() modified dst: %#v", dst)
+	}
+}
@@ -0,0 +1,413 @@
+package jsonutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNDJSONLineTooLong 表示单行长度超过了 WithMaxLineSize 设置的上限
+var ErrNDJSONLineTooLong = errors.New("ndjson line too long")
+
+// NDJSONLineError 描述 NDJSON 中某一行的解析错误
+type NDJSONLineError struct {
+	Line int   // 行号，从 1 开始
+	Err  error // 底层错误
+}
+
+// Error 实现 error 接口
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("ndjson line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// ndjsonOptions 保存 NDJSON 读写的选项
+type ndjsonOptions struct {
+	skipErrors  bool
+	onError     func(*NDJSONLineError)
+	maxLineSize int
+	useNumber   bool
+	bufferSize  int
+	flushEvery  int
+}
+
+// NDJSONOption 是设置 NDJSON 读写选项的函数类型
+type NDJSONOption func(*ndjsonOptions)
+
+// WithSkipInvalidLines 跳过无法解析的行而不是返回错误，onError 不为 nil 时会收到每个被跳过的行
+func WithSkipInvalidLines(onError func(*NDJSONLineError)) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.skipErrors = true
+		o.onError = onError
+	}
+}
+
+// WithMaxLineSize 设置单行的最大字节数，0 表示不限制
+func WithMaxLineSize(n int) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.maxLineSize = n
+	}
+}
+
+// WithNDJSONUseNumber 将数字解码为 json.Number 而不是 float64
+func WithNDJSONUseNumber() NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.useNumber = true
+	}
+}
+
+// WithNDJSONBufferSize 设置读写缓冲区的大小
+func WithNDJSONBufferSize(n int) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.bufferSize = n
+	}
+}
+
+// WithFlushEvery 每写入 n 条记录自动刷新一次缓冲区，0 表示只在缓冲区满或调用 Flush 时写出
+func WithFlushEvery(n int) NDJSONOption {
+	return func(o *ndjsonOptions) {
+		o.flushEvery = n
+	}
+}
+
+// newNDJSONOptions 创建默认选项并应用自定义选项
+func newNDJSONOptions(opts []NDJSONOption) ndjsonOptions {
+	o := ndjsonOptions{bufferSize: 64 * 1024}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bufferSize <= 0 {
+		o.bufferSize = 64 * 1024
+	}
+	return o
+}
+
+// NDJSONReader 逐行读取 NDJSON（换行分隔的 JSON），每行解码为 T 类型
+type NDJSONReader[T any] struct {
+	r       *bufio.Reader
+	opts    ndjsonOptions
+	line    int
+	skipped int
+	err     error
+}
+
+// NewNDJSONReader 创建 NDJSON 读取器
+func NewNDJSONReader[T any](r io.Reader, opts ...NDJSONOption) *NDJSONReader[T] {
+	o := newNDJSONOptions(opts)
+	return &NDJSONReader[T]{r: bufio.NewReaderSize(r, o.bufferSize), opts: o}
+}
+
+// Next 读取并返回下一条记录，没有更多记录时返回 io.EOF
+// 空行会被忽略；解析失败时返回 *NDJSONLineError，之后仍可继续调用 Next 读取后续的行
+func (r *NDJSONReader[T]) Next() (T, error) {
+	var zero T
+	for {
+		if r.err != nil {
+			return zero, r.err
+		}
+		data, err := r.readLine()
+		if err != nil && err != io.EOF {
+			if !errors.Is(err, ErrNDJSONLineTooLong) {
+				r.err = err
+				return zero, err
+			}
+		}
+		if err == io.EOF {
+			r.err = io.EOF
+			if len(data) == 0 {
+				return zero, io.EOF
+			}
+		}
+		if err == nil || err == io.EOF {
+			if r.line == 1 {
+				data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+			}
+			data = bytes.TrimSpace(data)
+			if len(data) == 0 {
+				continue
+			}
+			var value T
+			if err = r.decode(data, &value); err == nil {
+				return value, nil
+			}
+		}
+		lineErr := &NDJSONLineError{Line: r.line, Err: err}
+		if !r.opts.skipErrors {
+			return zero, lineErr
+		}
+		r.skipped++
+		if r.opts.onError != nil {
+			r.opts.onError(lineErr)
+		}
+	}
+}
+
+// ForEach 依次对每条记录调用 fn，直到读完或 fn 返回错误
+func (r *NDJSONReader[T]) ForEach(fn func(value T) error) error {
+	for {
+		value, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+}
+
+// Line 返回最近读取的行号
+func (r *NDJSONReader[T]) Line() int {
+	return r.line
+}
+
+// Skipped 返回因解析失败而被跳过的行数
+func (r *NDJSONReader[T]) Skipped() int {
+	return r.skipped
+}
+
+// readLine 读取一行（不含换行符），超过长度上限时丢弃该行剩余部分并返回 ErrNDJSONLineTooLong
+func (r *NDJSONReader[T]) readLine() ([]byte, error) {
+	r.line++
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		if !tooLong {
+			// 换行符不计入行长度，最后一行可能没有换行符
+			n := len(chunk)
+			if err == nil {
+				n--
+			}
+			if r.opts.maxLineSize > 0 && len(line)+n > r.opts.maxLineSize {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLong && (err == nil || err == io.EOF) {
+			return nil, ErrNDJSONLineTooLong
+		}
+		if err == nil {
+			line = line[:len(line)-1]
+		}
+		return line, err
+	}
+}
+
+// decode 将一行解码到 v，并确认该行只包含一个 JSON 值
+func (r *NDJSONReader[T]) decode(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if r.opts.useNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if rest := bytes.TrimSpace(data[dec.InputOffset():]); len(rest) > 0 {
+		return fmt.Errorf("unexpected data after JSON value: %q", truncateBytes(rest, 20))
+	}
+	return nil
+}
+
+// truncateBytes 截断过长的字节切片用于错误信息
+func truncateBytes(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[:n]
+	}
+	return b
+}
+
+// NDJSONWriter 将 T 类型的记录逐行写为 NDJSON，写入经过缓冲，结束时需调用 Flush
+type NDJSONWriter[T any] struct {
+	w     *bufio.Writer
+	enc   *json.Encoder
+	opts  ndjsonOptions
+	count int
+}
+
+// NewNDJSONWriter 创建 NDJSON 写入器
+func NewNDJSONWriter[T any](w io.Writer, opts ...NDJSONOption) *NDJSONWriter[T] {
+	o := newNDJSONOptions(opts)
+	bw := bufio.NewWriterSize(w, o.bufferSize)
+	return &NDJSONWriter[T]{w: bw, enc: json.NewEncoder(bw), opts: o}
+}
+
+// Write 写入一条记录
+func (w *NDJSONWriter[T]) Write(value T) error {
+	if err := w.enc.Encode(value); err != nil {
+		return err
+	}
+	w.count++
+	if w.opts.flushEvery > 0 && w.count%w.opts.flushEvery == 0 {
+		return w.w.Flush()
+	}
+	return nil
+}
+
+// WriteAll 依次写入多条记录
+func (w *NDJSONWriter[T]) WriteAll(values []T) error {
+	for _, v := range values {
+		if err := w.Write(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush 将缓冲区中的数据写出
+func (w *NDJSONWriter[T]) Flush() error {
+	return w.w.Flush()
+}
+
+// Count 返回已写入的记录数
+func (w *NDJSONWriter[T]) Count() int {
+	return w.count
+}
+
+// ReadNDJSONFile 读取 NDJSON 文件中的全部记录
+func ReadNDJSONFile[T any](filename string, opts ...NDJSONOption) ([]T, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var values []T
+	err = NewNDJSONReader[T](f, opts...).ForEach(func(v T) error {
+		values = append(values, v)
+		return nil
+	})
+	return values, err
+}
+
+// WriteNDJSONFile 将记录写入 NDJSON 文件
+func WriteNDJSONFile[T any](filename string, values []T, opts ...NDJSONOption) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := NewNDJSONWriter[T](f, opts...)
+	if err := w.WriteAll(values); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ArrayToNDJSON 将 r 中的 JSON 数组流式转换为 NDJSON 写入 w，返回转换的记录数
+// 每次只在内存中保存一个数组元素
+func ArrayToNDJSON(r io.Reader, w io.Writer) (int, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok != json.Delim('[') {
+		return 0, fmt.Errorf("expected JSON array, got %v", tok)
+	}
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	count := 0
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return count, fmt.Errorf("array element %d: %w", count, err)
+		}
+		buf.Reset()
+		if err := json.Compact(&buf, raw); err != nil {
+			return count, err
+		}
+		buf.WriteByte('\n')
+		if _, err := bw.Write(buf.Bytes()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if _, err := dec.Token(); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// NDJSONToArray 将 r 中的 NDJSON 流式转换为 JSON 数组写入 w，返回转换的记录数
+func NDJSONToArray(r io.Reader, w io.Writer, opts ...NDJSONOption) (int, error) {
+	reader := NewNDJSONReader[json.RawMessage](r, opts...)
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	count := 0
+	if err := bw.WriteByte('['); err != nil {
+		return 0, err
+	}
+	err := reader.ForEach(func(raw json.RawMessage) error {
+		buf.Reset()
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		if err := json.Compact(&buf, raw); err != nil {
+			return err
+		}
+		if _, err := bw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := bw.WriteByte(']'); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// ArrayFileToNDJSON 将 JSON 数组文件转换为 NDJSON 文件
+func ArrayFileToNDJSON(src, dst string) (int, error) {
+	return convertFile(src, dst, func(r io.Reader, w io.Writer) (int, error) {
+		return ArrayToNDJSON(r, w)
+	})
+}
+
+// NDJSONFileToArray 将 NDJSON 文件转换为 JSON 数组文件
+func NDJSONFileToArray(src, dst string, opts ...NDJSONOption) (int, error) {
+	return convertFile(src, dst, func(r io.Reader, w io.Writer) (int, error) {
+		return NDJSONToArray(r, w, opts...)
+	})
+}
+
+// convertFile 打开源文件和目标文件并执行转换
+func convertFile(src, dst string, convert func(io.Reader, io.Writer) (int, error)) (int, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := convert(in, out)
+	if err != nil {
+		out.Close()
+		return n, err
+	}
+	return n, out.Close()
+}
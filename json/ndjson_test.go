@@ -0,0 +1,133 @@
+package jsonutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type ndjsonRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := "\xef\xbb\xbf{\"id\":1,\"name\":\"a\"}\r\n\n  {\"id\":2,\"name\":\"b\"}\n{\"id\":3,\"name\":\"c\"}"
+	r := NewNDJSONReader[ndjsonRecord](strings.NewReader(input))
+	var got []ndjsonRecord
+	if err := r.ForEach(func(v ndjsonRecord) error {
+		got = append(got, v)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []ndjsonRecord{{1, "a"}, {2, "b"}, {3, "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if r.Line() != 4 {
+		t.Errorf("Line() = %d, want 4", r.Line())
+	}
+}
+
+func TestNDJSONReaderLineError(t *testing.T) {
+	input := "{\"id\":1}\nnot json\n{\"id\":1} {\"id\":2}\n{\"id\":4}\n"
+	r := NewNDJSONReader[ndjsonRecord](strings.NewReader(input))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	var lineErr *NDJSONLineError
+	if _, err := r.Next(); !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("Next() error = %v, want line 2 error", err)
+	}
+
+	var skipped []int
+	r = NewNDJSONReader[ndjsonRecord](strings.NewReader(input), WithSkipInvalidLines(func(e *NDJSONLineError) {
+		skipped = append(skipped, e.Line)
+	}))
+	var ids []int
+	if err := r.ForEach(func(v ndjsonRecord) error {
+		ids = append(ids, v.ID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 4}) || !reflect.DeepEqual(skipped, []int{2, 3}) {
+		t.Errorf("ids = %v, skipped = %v, want [1 4] and [2 3]", ids, skipped)
+	}
+}
+
+func TestNDJSONReaderMaxLineSize(t *testing.T) {
+	const limit = 8 // len(`{"id":1}`)
+	tests := []struct {
+		name    string
+		input   string
+		wantIDs []int
+		tooLong []int
+	}{
+		{name: "exact with newline", input: "{\"id\":1}\n", wantIDs: []int{1}},
+		{name: "exact without newline", input: "{\"id\":1}", wantIDs: []int{1}},
+		{name: "one over with newline", input: "{\"id\":12}\n{\"id\":2}\n", wantIDs: []int{2}, tooLong: []int{1}},
+		{name: "one over without newline", input: "{\"id\":1}\n{\"id\":12}", wantIDs: []int{1}, tooLong: []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tooLong []int
+			r := NewNDJSONReader[ndjsonRecord](strings.NewReader(tt.input),
+				WithMaxLineSize(limit),
+				WithNDJSONBufferSize(16),
+				WithSkipInvalidLines(func(e *NDJSONLineError) {
+					if errors.Is(e, ErrNDJSONLineTooLong) {
+						tooLong = append(tooLong, e.Line)
+					}
+				}))
+			var ids []int
+			if err := r.ForEach(func(v ndjsonRecord) error {
+				ids = append(ids, v.ID)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || !reflect.DeepEqual(tooLong, tt.tooLong) {
+				t.Errorf("ids = %v, too long = %v, want %v and %v", ids, tooLong, tt.wantIDs, tt.tooLong)
+			}
+		})
+	}
+}
+
+func TestNDJSONWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter[ndjsonRecord](&buf)
+	if err := w.WriteAll([]ndjsonRecord{{1, "a"}, {2, "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n"; buf.String() != want {
+		t.Errorf("written %q, want %q", buf.String(), want)
+	}
+
+	var arr bytes.Buffer
+	n, err := NDJSONToArray(&buf, &arr)
+	if err != nil || n != 2 {
+		t.Fatalf("NDJSONToArray() = %d, %v", n, err)
+	}
+	var back bytes.Buffer
+	if n, err := ArrayToNDJSON(&arr, &back); err != nil || n != 2 {
+		t.Fatalf("ArrayToNDJSON() = %d, %v", n, err)
+	}
+	r := NewNDJSONReader[ndjsonRecord](&back)
+	for _, want := range []ndjsonRecord{{1, "a"}, {2, "b"}} {
+		got, err := r.Next()
+		if err != nil || got != want {
+			t.Fatalf("Next() = %v, %v, want %v", got, err, want)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,893 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONObject 是保持键插入顺序的 JSON 对象，提供宽松类型转换的取值方法
+// 零值可直接使用；解码时嵌套对象为 *JSONObject，数组为 []interface{}（可通过 GetArray 取得 JSONArray）
+// 取值方法（Get*、Has、Lookup、Len、Keys、ForEach、GetByPath）可在 nil 指针上调用，此时视为空对象，
+// 因此 o.GetObject("a").GetString("b", "def") 这样的链式调用在中间对象缺失时返回默认值
+type JSONObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// JSONArray 是 JSON 数组，提供宽松类型转换的取值方法
+type JSONArray []interface{}
+
+// NewJSONObject 创建空的 JSONObject
+func NewJSONObject() *JSONObject {
+	return &JSONObject{values: make(map[string]interface{})}
+}
+
+// ParseJSONObject 将 JSON 对象字符串解析为 JSONObject，保持键的原始顺序
+func ParseJSONObject(jsonStr string) (*JSONObject, error) {
+	obj := NewJSONObject()
+	if err := obj.UnmarshalJSON([]byte(jsonStr)); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ParseJSONArray 将 JSON 数组字符串解析为 JSONArray
+func ParseJSONArray(jsonStr string) (JSONArray, error) {
+	var arr JSONArray
+	if err := arr.UnmarshalJSON([]byte(jsonStr)); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
+
+// FromJSONOrdered 将 JSON 字符串解析为保持键顺序的值，见 DecodeOrdered
+func FromJSONOrdered(jsonStr string) (interface{}, error) {
+	return DecodeOrdered([]byte(jsonStr))
+}
+
+// ToJSONObject 将 JSON 字符串、字节切片、map 或结构体转换为 JSONObject
+// 结构体按字段声明顺序，map 按键的字典序
+func ToJSONObject(v interface{}) (*JSONObject, error) {
+	switch s := v.(type) {
+	case *JSONObject:
+		return s, nil
+	case string:
+		return ParseJSONObject(s)
+	case []byte:
+		return ParseJSONObject(string(s))
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONObject(string(data))
+}
+
+// Len 返回键的数量
+func (o *JSONObject) Len() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.keys)
+}
+
+// Keys 按插入顺序返回所有键
+func (o *JSONObject) Keys() []string {
+	if o == nil {
+		return nil
+	}
+	return append([]string(nil), o.keys...)
+}
+
+// Has 判断是否包含指定键
+func (o *JSONObject) Has(key string) bool {
+	_, ok := o.Lookup(key)
+	return ok
+}
+
+// Get 返回键对应的值，不存在时返回 nil
+func (o *JSONObject) Get(key string) interface{} {
+	v, _ := o.Lookup(key)
+	return v
+}
+
+// Lookup 返回键对应的值以及键是否存在
+func (o *JSONObject) Lookup(key string) (interface{}, bool) {
+	if o == nil {
+		return nil, false
+	}
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set 设置键的值，新键追加到末尾，已有的键保持原位置，返回自身以便链式调用
+func (o *JSONObject) Set(key string, value interface{}) *JSONObject {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = normalizeObjectValue(value)
+	return o
+}
+
+// SetIfAbsent 仅当键不存在时设置值，返回自身以便链式调用
+func (o *JSONObject) SetIfAbsent(key string, value interface{}) *JSONObject {
+	if !o.Has(key) {
+		o.Set(key, value)
+	}
+	return o
+}
+
+// Remove 删除键，返回自身以便链式调用
+func (o *JSONObject) Remove(key string) *JSONObject {
+	if _, ok := o.values[key]; !ok {
+		return o
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return o
+}
+
+// ForEach 按插入顺序遍历键值对，fn 返回 false 时停止
+func (o *JSONObject) ForEach(fn func(key string, value interface{}) bool) {
+	if o == nil {
+		return
+	}
+	for _, k := range o.keys {
+		if !fn(k, o.values[k]) {
+			return
+		}
+	}
+}
+
+// GetString 获取字符串值，数字和布尔值会被转换为字符串；不存在或无法转换时返回默认值
+func (o *JSONObject) GetString(key string, def ...string) string {
+	if s, ok := lenientString(o.Get(key)); ok {
+		return s
+	}
+	return firstOr(def)
+}
+
+// GetInt 获取 int 值，支持从数字字符串转换；不存在或无法转换时返回默认值
+func (o *JSONObject) GetInt(key string, def ...int) int {
+	if n, ok := lenientInt64(o.Get(key)); ok && n >= math.MinInt && n <= math.MaxInt {
+		return int(n)
+	}
+	return firstOr(def)
+}
+
+// GetInt64 获取 int64 值，支持从数字字符串转换；不存在或无法转换时返回默认值
+func (o *JSONObject) GetInt64(key string, def ...int64) int64 {
+	if n, ok := lenientInt64(o.Get(key)); ok {
+		return n
+	}
+	return firstOr(def)
+}
+
+// GetFloat64 获取 float64 值，支持从数字字符串转换；不存在或无法转换时返回默认值
+func (o *JSONObject) GetFloat64(key string, def ...float64) float64 {
+	if f, ok := lenientFloat64(o.Get(key)); ok {
+		return f
+	}
+	return firstOr(def)
+}
+
+// GetDecimal 获取精确的十进制数，支持从数字字符串转换；不存在或无法转换时返回默认值
+func (o *JSONObject) GetDecimal(key string, def ...Decimal) Decimal {
+	if d, ok := lenientDecimal(o.Get(key)); ok {
+		return d
+	}
+	return firstOr(def)
+}
+
+// GetBool 获取布尔值，支持 "true"/"false"/"1"/"0" 等字符串和数字；不存在或无法转换时返回默认值
+func (o *JSONObject) GetBool(key string, def ...bool) bool {
+	if b, ok := lenientBool(o.Get(key)); ok {
+		return b
+	}
+	return firstOr(def)
+}
+
+// GetTime 获取时间值，支持 RFC 3339 及常见日期时间格式的字符串，数字按 Unix 毫秒时间戳处理；
+// 不存在或无法转换时返回默认值
+func (o *JSONObject) GetTime(key string, def ...time.Time) time.Time {
+	if t, ok := lenientTime(o.Get(key)); ok {
+		return t
+	}
+	return firstOr(def)
+}
+
+// GetObject 获取嵌套对象，不存在或不是对象时返回 nil
+func (o *JSONObject) GetObject(key string) *JSONObject {
+	obj, _ := asJSONObject(o.Get(key))
+	return obj
+}
+
+// GetArray 获取嵌套数组，不存在或不是数组时返回 nil
+func (o *JSONObject) GetArray(key string) JSONArray {
+	arr, _ := asJSONArray(o.Get(key))
+	return arr
+}
+
+// GetByPath 按路径获取值，路径使用点号和方括号下标，如 a.b[0].c 或 a.b.0.c，数组下标可为负数
+// 路径可以穿过通过 Set 存入的 map[string]interface{}、JSONArray 和 *JSONArray
+func (o *JSONObject) GetByPath(path string) (interface{}, bool) {
+	if o == nil {
+		return nil, false
+	}
+	tokens, err := parseObjectPath(path)
+	if err != nil {
+		return nil, false
+	}
+	var cur interface{} = o
+	for _, tok := range tokens {
+		switch c := normalizeObjectValue(cur).(type) {
+		case *JSONObject:
+			v, ok := c.Lookup(tok.name)
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case map[string]interface{}:
+			v, ok := c[tok.name]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []interface{}:
+			i, err := strconv.Atoi(tok.name)
+			if err != nil {
+				return nil, false
+			}
+			if i < 0 {
+				i += len(c)
+			}
+			if i < 0 || i >= len(c) {
+				return nil, false
+			}
+			cur = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// SetByPath 按路径设置值，自动创建缺失的中间对象或数组（下一段为方括号下标时）；
+// 数组下标等于数组长度时追加元素，路径中的 map[string]interface{} 会被原地修改
+func (o *JSONObject) SetByPath(path string, value interface{}) error {
+	tokens, err := parseObjectPath(path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("empty path")
+	}
+	value = normalizeObjectValue(value)
+	var set func(cur interface{}, depth int) (interface{}, error)
+	set = func(cur interface{}, depth int) (interface{}, error) {
+		tok := tokens[depth]
+		last := depth == len(tokens)-1
+		newChild := func() interface{} {
+			if tokens[depth+1].index {
+				return []interface{}{}
+			}
+			return NewJSONObject()
+		}
+		switch c := normalizeObjectValue(cur).(type) {
+		case *JSONObject:
+			if last {
+				return c.Set(tok.name, value), nil
+			}
+			child, ok := c.values[tok.name]
+			if !ok || child == nil {
+				child = newChild()
+			}
+			child, err := set(child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return c.Set(tok.name, child), nil
+		case map[string]interface{}:
+			if last {
+				c[tok.name] = value
+				return c, nil
+			}
+			child, ok := c[tok.name]
+			if !ok || child == nil {
+				child = newChild()
+			}
+			child, err := set(child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			c[tok.name] = child
+			return c, nil
+		case []interface{}:
+			i, err := strconv.Atoi(tok.name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q in path %q", tok.name, path)
+			}
+			if i < 0 {
+				i += len(c)
+			}
+			if i < 0 || i > len(c) {
+				return nil, fmt.Errorf("index out of range: %s", tok.name)
+			}
+			if i == len(c) {
+				c = append(c, nil)
+			}
+			if last {
+				c[i] = value
+				return c, nil
+			}
+			child := c[i]
+			if child == nil {
+				child = newChild()
+			}
+			if c[i], err = set(child, depth+1); err != nil {
+				return nil, err
+			}
+			return c, nil
+		}
+		return nil, fmt.Errorf("cannot set %q in path %q: parent is not an object or array", tok.name, path)
+	}
+	_, err = set(o, 0)
+	return err
+}
+
+// objectPathToken 是路径中的一段，index 表示以方括号下标的形式出现
+type objectPathToken struct {
+	name  string
+	index bool
+}
+
+// parseObjectPath 将 a.b[0].c 形式的路径拆分为键序列
+func parseObjectPath(path string) ([]objectPathToken, error) {
+	var tokens []objectPathToken
+	for _, part := range strings.Split(path, ".") {
+		name, rest := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, rest = part[:i], part[i:]
+		}
+		if name != "" {
+			tokens = append(tokens, objectPathToken{name: name})
+		} else if rest == "" && path != "" {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			tokens = append(tokens, objectPathToken{name: rest[1:end], index: true})
+			rest = rest[end+1:]
+		}
+	}
+	return tokens, nil
+}
+
+// Clone 深拷贝对象
+func (o *JSONObject) Clone() *JSONObject {
+	return cloneValue(o).(*JSONObject)
+}
+
+// ToMap 将对象递归转换为 map[string]interface{}，嵌套的 JSONObject 也会被转换
+func (o *JSONObject) ToMap() map[string]interface{} {
+	m, _ := plainValue(o).(map[string]interface{})
+	return m
+}
+
+// ToBean 将对象转换为结构体或其他类型，规则与 Unmarshal 相同
+func (o *JSONObject) ToBean(v interface{}) error {
+	data, err := o.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return Unmarshal(data, v)
+}
+
+// String 返回紧凑的 JSON 字符串，nil 时返回 null，编码失败时返回空字符串
+func (o *JSONObject) String() string {
+	data, err := o.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ToJSON 将对象编码为 JSON 字符串
+func (o *JSONObject) ToJSON() (string, error) {
+	return ToJSON(o)
+}
+
+// ToPrettyJSON 将对象编码为格式化的 JSON 字符串
+func (o *JSONObject) ToPrettyJSON() (string, error) {
+	return PrettyPrint(o)
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，按插入顺序输出键，nil 时输出 null
+func (o *JSONObject) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，保持键的原始顺序
+func (o *JSONObject) UnmarshalJSON(data []byte) error {
+	v, err := DecodeOrdered(data)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	obj, ok := v.(*JSONObject)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %s into JSONObject", jsonTypeName(v))
+	}
+	*o = *obj
+	return nil
+}
+
+// Len 返回元素个数
+func (a JSONArray) Len() int {
+	return len(a)
+}
+
+// Get 返回下标对应的元素，越界时返回 nil
+func (a JSONArray) Get(i int) interface{} {
+	if i < 0 || i >= len(a) {
+		return nil
+	}
+	return a[i]
+}
+
+// GetString 获取字符串元素，越界或无法转换时返回默认值
+func (a JSONArray) GetString(i int, def ...string) string {
+	if s, ok := lenientString(a.Get(i)); ok {
+		return s
+	}
+	return firstOr(def)
+}
+
+// GetInt 获取 int 元素，越界或无法转换时返回默认值
+func (a JSONArray) GetInt(i int, def ...int) int {
+	if n, ok := lenientInt64(a.Get(i)); ok && n >= math.MinInt && n <= math.MaxInt {
+		return int(n)
+	}
+	return firstOr(def)
+}
+
+// GetInt64 获取 int64 元素，越界或无法转换时返回默认值
+func (a JSONArray) GetInt64(i int, def ...int64) int64 {
+	if n, ok := lenientInt64(a.Get(i)); ok {
+		return n
+	}
+	return firstOr(def)
+}
+
+// GetFloat64 获取 float64 元素，越界或无法转换时返回默认值
+func (a JSONArray) GetFloat64(i int, def ...float64) float64 {
+	if f, ok := lenientFloat64(a.Get(i)); ok {
+		return f
+	}
+	return firstOr(def)
+}
+
+// GetDecimal 获取精确的十进制数元素，越界或无法转换时返回默认值
+func (a JSONArray) GetDecimal(i int, def ...Decimal) Decimal {
+	if d, ok := lenientDecimal(a.Get(i)); ok {
+		return d
+	}
+	return firstOr(def)
+}
+
+// GetBool 获取布尔元素，越界或无法转换时返回默认值
+func (a JSONArray) GetBool(i int, def ...bool) bool {
+	if b, ok := lenientBool(a.Get(i)); ok {
+		return b
+	}
+	return firstOr(def)
+}
+
+// GetTime 获取时间元素，越界或无法转换时返回默认值
+func (a JSONArray) GetTime(i int, def ...time.Time) time.Time {
+	if t, ok := lenientTime(a.Get(i)); ok {
+		return t
+	}
+	return firstOr(def)
+}
+
+// GetObject 获取对象元素，越界或不是对象时返回 nil
+func (a JSONArray) GetObject(i int) *JSONObject {
+	obj, _ := asJSONObject(a.Get(i))
+	return obj
+}
+
+// GetArray 获取数组元素，越界或不是数组时返回 nil
+func (a JSONArray) GetArray(i int) JSONArray {
+	arr, _ := asJSONArray(a.Get(i))
+	return arr
+}
+
+// Add 追加元素，返回自身以便链式调用
+func (a *JSONArray) Add(values ...interface{}) *JSONArray {
+	for _, v := range values {
+		*a = append(*a, normalizeObjectValue(v))
+	}
+	return a
+}
+
+// Set 设置下标对应的元素，下标等于长度时追加，返回自身以便链式调用；越界时 panic
+func (a *JSONArray) Set(i int, value interface{}) *JSONArray {
+	if i == len(*a) {
+		return a.Add(value)
+	}
+	(*a)[i] = normalizeObjectValue(value)
+	return a
+}
+
+// Remove 删除下标对应的元素，越界时不做任何操作，返回自身以便链式调用
+func (a *JSONArray) Remove(i int) *JSONArray {
+	if i >= 0 && i < len(*a) {
+		*a = append((*a)[:i], (*a)[i+1:]...)
+	}
+	return a
+}
+
+// String 返回紧凑的 JSON 字符串，nil 时返回 null，编码失败时返回空字符串
+func (a JSONArray) String() string {
+	s, err := ToJSON(a)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// ToSlice 将数组递归转换为 []interface{}，嵌套的 JSONObject 会被转换为 map[string]interface{}
+func (a JSONArray) ToSlice() []interface{} {
+	return plainValue([]interface{}(a)).([]interface{})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，嵌套对象解码为 *JSONObject
+func (a *JSONArray) UnmarshalJSON(data []byte) error {
+	v, err := DecodeOrdered(data)
+	if err != nil {
+		return err
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		if v == nil {
+			*a = nil
+			return nil
+		}
+		return fmt.Errorf("cannot unmarshal %s into JSONArray", jsonTypeName(v))
+	}
+	*a = arr
+	return nil
+}
+
+// DecodeOrdered 解析 JSON 字节，对象解码为保持键原始顺序的 *JSONObject，数组为 []interface{}
+// 数字解码为 json.Number，超过 2^53 的整数和高精度小数不会丢失精度；
+// 结果可直接用于 JSONPointer、JSONPath、DeepMerge、Compare 等函数，重新编码时键按原顺序输出
+func DecodeOrdered(data []byte) (interface{}, error) {
+	return decodeOrderedBytes(data, true)
+}
+
+// decodeOrderedBytes 按 DecodeOrdered 的规则解析 JSON 字节，useNumber 为 true 时数字解码为 json.Number
+func decodeOrderedBytes(data []byte, useNumber bool) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if useNumber {
+		dec.UseNumber()
+	}
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return v, nil
+}
+
+// decodeOrdered 从解码器读取一个值，对象解码为 *JSONObject，重复的键以最后一个值为准
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := NewJSONObject()
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(keyTok.(string), value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return tok, nil
+}
+
+// jsonTypeName 返回值对应的 JSON 类型名称
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case *JSONObject, map[string]interface{}:
+		return "object"
+	case []interface{}, JSONArray:
+		return "array"
+	}
+	if isNumber(v) {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// normalizeObjectValue 统一存入 JSONObject/JSONArray 的值的表示
+func normalizeObjectValue(v interface{}) interface{} {
+	switch c := v.(type) {
+	case JSONArray:
+		return []interface{}(c)
+	case *JSONArray:
+		if c == nil {
+			return nil
+		}
+		return []interface{}(*c)
+	case JSONObject:
+		return &c
+	}
+	return v
+}
+
+// plainValue 将 *JSONObject 递归转换为 map[string]interface{}，JSONArray 转换为 []interface{}
+func plainValue(v interface{}) interface{} {
+	switch c := normalizeObjectValue(v).(type) {
+	case *JSONObject:
+		if c == nil {
+			return nil
+		}
+		m := make(map[string]interface{}, len(c.keys))
+		for _, k := range c.keys {
+			m[k] = plainValue(c.values[k])
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, item := range c {
+			m[k] = plainValue(item)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(c))
+		for i, item := range c {
+			arr[i] = plainValue(item)
+		}
+		return arr
+	}
+	return v
+}
+
+// asJSONObject 将值转换为 *JSONObject，map 按键的字典序转换
+func asJSONObject(v interface{}) (*JSONObject, bool) {
+	switch c := v.(type) {
+	case *JSONObject:
+		return c, c != nil
+	case map[string]interface{}:
+		obj := NewJSONObject()
+		for _, k := range sortedKeys(c) {
+			obj.Set(k, c[k])
+		}
+		return obj, true
+	}
+	return nil, false
+}
+
+// asJSONArray 将值转换为 JSONArray
+func asJSONArray(v interface{}) (JSONArray, bool) {
+	switch c := v.(type) {
+	case []interface{}:
+		return JSONArray(c), true
+	case JSONArray:
+		return c, true
+	}
+	return nil, false
+}
+
+// firstOr 返回第一个默认值，没有时返回零值
+func firstOr[T any](def []T) T {
+	if len(def) > 0 {
+		return def[0]
+	}
+	var zero T
+	return zero
+}
+
+// lenientString 宽松地将值转换为字符串
+func lenientString(v interface{}) (string, bool) {
+	switch c := v.(type) {
+	case string:
+		return c, true
+	case bool:
+		return strconv.FormatBool(c), true
+	case json.Number:
+		return c.String(), true
+	case float64:
+		return strconv.FormatFloat(c, 'f', -1, 64), true
+	case *JSONObject, []interface{}:
+		s, err := ToJSON(c)
+		return s, err == nil
+	}
+	if isNumber(v) {
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// lenientInt64 宽松地将值转换为 int64，带小数的数值不会被截断
+func lenientInt64(v interface{}) (int64, bool) {
+	switch c := v.(type) {
+	case string:
+		s := strings.TrimSpace(c)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, true
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		v = f
+	case json.Number:
+		if n, err := c.Int64(); err == nil {
+			return n, true
+		}
+	case int64:
+		return c, true
+	case int:
+		return int64(c), true
+	case uint64:
+		return int64(c), c <= math.MaxInt64
+	}
+	f, ok := toFloat64(v)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// lenientFloat64 宽松地将值转换为 float64
+func lenientFloat64(v interface{}) (float64, bool) {
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return toFloat64(v)
+}
+
+// lenientDecimal 宽松地将值转换为 Decimal
+func lenientDecimal(v interface{}) (Decimal, bool) {
+	if s, ok := v.(string); ok {
+		d, err := ParseDecimal(strings.TrimSpace(s))
+		return d, err == nil
+	}
+	return toDecimal(v)
+}
+
+// lenientBool 宽松地将值转换为布尔值
+func lenientBool(v interface{}) (bool, bool) {
+	switch c := v.(type) {
+	case bool:
+		return c, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(c)) {
+		case "true", "yes", "y", "on", "1":
+			return true, true
+		case "false", "no", "n", "off", "0":
+			return false, true
+		}
+		return false, false
+	}
+	if f, ok := toFloat64(v); ok {
+		return f != 0, true
+	}
+	return false, false
+}
+
+// lenientTimeLayouts 是 GetTime 尝试的时间格式
+var lenientTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// lenientTime 宽松地将值转换为时间，数字按 Unix 毫秒时间戳处理
+func lenientTime(v interface{}) (time.Time, bool) {
+	switch c := v.(type) {
+	case time.Time:
+		return c, true
+	case string:
+		s := strings.TrimSpace(c)
+		for _, layout := range lenientTimeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, true
+			}
+		}
+		if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.UnixMilli(ms), true
+		}
+		return time.Time{}, false
+	}
+	if ms, ok := lenientInt64(v); ok {
+		return time.UnixMilli(ms), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,142 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONObjectOrder(t *testing.T) {
+	obj, err := ParseJSONObject(`{"z":1,"a":{"y":2,"b":3},"m":[1,2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := obj.Keys(); !reflect.DeepEqual(got, []string{"z", "a", "m"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	obj.Set("z", 0).Set("c", true).Remove("a")
+	if got, want := obj.String(), `{"z":0,"m":[1,2],"c":true}`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestJSONObjectGetByPath(t *testing.T) {
+	obj, err := ParseJSONObject(`{"a":{"b":[{"c":1},{"c":2}],"d.e":3},"list":[[10,20],[30]]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj.Set("m", map[string]interface{}{"x": 1, "arr": []interface{}{"p", "q"}})
+	obj.Set("ja", JSONArray{"u", map[string]interface{}{"v": "w"}})
+	ptr := &JSONArray{"r", "s"}
+	obj.Set("pa", ptr)
+	obj.GetObject("a").Set("nested", []interface{}{JSONArray{"deep"}, ptr})
+
+	tests := []struct {
+		path string
+		want interface{}
+		ok   bool
+	}{
+		{path: "a.b[1].c", want: json.Number("2"), ok: true},
+		{path: "a.b.0.c", want: json.Number("1"), ok: true},
+		{path: "a.b[-1].c", want: json.Number("2"), ok: true},
+		{path: "list[0][1]", want: json.Number("20"), ok: true},
+		{path: "list[1][0]", want: json.Number("30"), ok: true},
+		{path: "m.x", want: 1, ok: true},
+		{path: "m.arr[1]", want: "q", ok: true},
+		{path: "ja[0]", want: "u", ok: true},
+		{path: "ja[1].v", want: "w", ok: true},
+		{path: "pa[1]", want: "s", ok: true},
+		{path: "a.nested[0][0]", want: "deep", ok: true},
+		{path: "a.nested[1][0]", want: "r", ok: true},
+		{path: "a.b[2].c"},
+		{path: "a.b[-3]"},
+		{path: "a.b.x"},
+		{path: "a.missing"},
+		{path: "m.y"},
+		{path: "a.b[1].c.d"},
+		{path: "a[0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := obj.GetByPath(tt.path)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetByPath(%q) = %#v, %v, want %#v, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestJSONObjectSetByPath(t *testing.T) {
+	obj := NewJSONObject()
+	obj.Set("m", map[string]interface{}{"x": 1})
+	obj.Set("ja", JSONArray{1})
+	steps := []struct {
+		path  string
+		value interface{}
+	}{
+		{"a.b.c", 1},
+		{"a.b.d", 2},
+		{"list[0].name", "x"},
+		{"list[1]", "y"},
+		{"list[-1]", "z"},
+		{"grid[0][0]", 5},
+		{"m.y", 2},
+		{"m.sub.z", 3},
+		{"ja[1]", 2},
+	}
+	for _, st := range steps {
+		if err := obj.SetByPath(st.path, st.value); err != nil {
+			t.Fatalf("SetByPath(%q) error = %v", st.path, err)
+		}
+	}
+	want := `{"m":{"sub":{"z":3},"x":1,"y":2},"ja":[1,2],"a":{"b":{"c":1,"d":2}},"list":[{"name":"x"},"z"],"grid":[[5]]}`
+	if got := obj.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	if v, ok := obj.GetByPath("m.sub.z"); !ok || v != 3 {
+		t.Errorf("GetByPath(m.sub.z) = %v, %v", v, ok)
+	}
+
+	for _, path := range []string{"", "list[5]", "a.b.c.d", "list[x]"} {
+		if err := obj.SetByPath(path, 1); err == nil {
+			t.Errorf("SetByPath(%q) error = nil, want error", path)
+		}
+	}
+}
+
+func TestJSONObjectNil(t *testing.T) {
+	var obj *JSONObject
+	if obj.Len() != 0 || obj.Has("a") || obj.Get("a") != nil || obj.GetString("a", "def") != "def" {
+		t.Error("getters on nil JSONObject should return zero values or defaults")
+	}
+	if _, ok := obj.GetByPath("a.b"); ok {
+		t.Error("GetByPath on nil JSONObject should report false")
+	}
+	if got := obj.String(); got != "null" {
+		t.Errorf("String() = %q, want null", got)
+	}
+	data, err := Marshal(map[string]interface{}{"o": obj})
+	if err != nil || string(data) != `{"o":null}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+	bean := struct{ A int }{A: 1}
+	if err := obj.ToBean(&bean); err != nil || bean.A != 1 {
+		t.Errorf("ToBean() = %+v, %v", bean, err)
+	}
+}
+
+func TestJSONObjectLenientGetters(t *testing.T) {
+	obj, err := ParseJSONObject(`{"s":"42","n":7,"b":"true","f":"1.5","o":{"k":1},"a":[1,"2"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetInt("s") != 42 || obj.GetString("n") != "7" || !obj.GetBool("b") || obj.GetFloat64("f") != 1.5 {
+		t.Error("lenient getters did not convert values")
+	}
+	if obj.GetInt("missing", 9) != 9 || obj.GetInt("o", 9) != 9 {
+		t.Error("getters should fall back to the default")
+	}
+	if obj.GetObject("o").GetInt("k") != 1 || obj.GetArray("a").GetInt(1) != 2 {
+		t.Error("nested getters failed")
+	}
+}
@@ -0,0 +1,232 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSON Patch 操作类型
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// JSON Patch 操作可能返回的错误，可通过 errors.Is 判断
+var (
+	ErrPatchInvalidOp  = errors.New("invalid patch operation")
+	ErrPatchTestFailed = errors.New("test operation failed")
+)
+
+// PatchError 描述补丁中哪一个操作执行失败
+type PatchError struct {
+	Index     int            // 操作序号，从 0 开始
+	Operation PatchOperation // 失败的操作
+	Err       error          // 底层错误
+}
+
+// Error 实现 error 接口
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json patch operation #%d (%s %s): %v", e.Index, e.Operation.Op, e.Operation.Path, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// PatchOperation 表示 RFC 6902 JSON Patch 中的一个操作
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// hasValue 判断操作是否需要 value 成员
+func (op PatchOperation) hasValue() bool {
+	return op.Op == PatchAdd || op.Op == PatchReplace || op.Op == PatchTest
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，add/replace/test 操作始终输出 value（包括 null）
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type plain struct {
+		Op    string       `json:"op"`
+		From  string       `json:"from,omitempty"`
+		Path  string       `json:"path"`
+		Value *interface{} `json:"value,omitempty"`
+	}
+	p := plain{Op: op.Op, From: op.From, Path: op.Path}
+	if op.hasValue() {
+		p.Value = &op.Value
+	}
+	return json.Marshal(p)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，并校验必需的成员
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var result PatchOperation
+	for _, field := range []struct {
+		name string
+		dst  *string
+	}{{"op", &result.Op}, {"path", &result.Path}, {"from", &result.From}} {
+		if msg, ok := raw[field.name]; ok {
+			if err := json.Unmarshal(msg, field.dst); err != nil {
+				return fmt.Errorf("%w: member %q must be a string", ErrPatchInvalidOp, field.name)
+			}
+		}
+	}
+	if _, ok := raw["op"]; !ok {
+		return fmt.Errorf("%w: missing member \"op\"", ErrPatchInvalidOp)
+	}
+	if _, ok := raw["path"]; !ok {
+		return fmt.Errorf("%w: missing member \"path\"", ErrPatchInvalidOp)
+	}
+	switch result.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		msg, ok := raw["value"]
+		if !ok {
+			return fmt.Errorf("%w: missing member \"value\" for %s", ErrPatchInvalidOp, result.Op)
+		}
+		v, err := DecodeOrdered(msg)
+		if err != nil {
+			return err
+		}
+		result.Value = v
+	case PatchMove, PatchCopy:
+		if _, ok := raw["from"]; !ok {
+			return fmt.Errorf("%w: missing member \"from\" for %s", ErrPatchInvalidOp, result.Op)
+		}
+	case PatchRemove:
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrPatchInvalidOp, result.Op)
+	}
+	*op = result
+	return nil
+}
+
+// Patch 表示 RFC 6902 JSON Patch 文档
+type Patch []PatchOperation
+
+// DecodePatch 从 JSON 字节解析补丁
+func DecodePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// ToJSON 将补丁编码为 JSON 字符串
+func (p Patch) ToJSON() (string, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	return ToJSON([]PatchOperation(p))
+}
+
+// Apply 将补丁应用到已解析的文档上并返回新文档
+// 补丁以全有或全无的方式执行：任何操作失败时返回错误，原文档保持不变
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	result := cloneValue(doc)
+	for i, op := range p {
+		var err error
+		if result, err = applyOperation(result, op); err != nil {
+			return nil, &PatchError{Index: i, Operation: op, Err: err}
+		}
+	}
+	return result, nil
+}
+
+// ApplyJSON 将补丁应用到 JSON 字符串上并返回新的 JSON 字符串
+func (p Patch) ApplyJSON(jsonStr string) (string, error) {
+	doc, err := DecodeOrdered([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	doc, err = p.Apply(doc)
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(doc)
+}
+
+// applyOperation 执行单个补丁操作
+func applyOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case PatchAdd:
+		return path.Add(doc, cloneValue(op.Value))
+	case PatchRemove:
+		return path.Remove(doc)
+	case PatchReplace:
+		return path.Replace(doc, cloneValue(op.Value))
+	case PatchMove, PatchCopy:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == PatchCopy {
+			return path.Add(doc, cloneValue(value))
+		}
+		if len(path) > len(from) && path.HasPrefix(from) {
+			return nil, fmt.Errorf("%w: cannot move %q into its own child %q", ErrPatchInvalidOp, op.From, op.Path)
+		}
+		if doc, err = from.Remove(doc); err != nil {
+			return nil, err
+		}
+		return path.Add(doc, value)
+	case PatchTest:
+		value, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !valuesEqual(value, op.Value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrPatchInvalidOp, op.Op)
+}
+
+// ApplyPatch 将 JSON 格式的补丁应用到 JSON 字符串上
+func ApplyPatch(jsonStr, patchStr string) (string, error) {
+	patch, err := DecodePatch([]byte(patchStr))
+	if err != nil {
+		return "", err
+	}
+	return patch.ApplyJSON(jsonStr)
+}
+
+// CreatePatch 生成将 original 转换为 modified 的补丁
+func CreatePatch(original, modified interface{}, opts ...DiffOption) Patch {
+	return Compare(original, modified, opts...).Patch()
+}
+
+// CreatePatchJSON 生成将 json1 转换为 json2 的补丁
+func CreatePatchJSON(json1, json2 string, opts ...DiffOption) (Patch, error) {
+	original, err := DecodeOrdered([]byte(json1))
+	if err != nil {
+		return nil, err
+	}
+	modified, err := DecodeOrdered([]byte(json2))
+	if err != nil {
+		return nil, err
+	}
+	return CreatePatch(original, modified, opts...), nil
+}
@@ -0,0 +1,233 @@
+package jsonutil
+
+import (
+	"errors"
+	"testing"
+)
+
+// RFC 6902 附录 A 的示例
+func TestApplyPatchRFC6902(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+		err   error
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[
+				{"op":"test","path":"/baz","value":"qux"},
+				{"op":"test","path":"/foo/1","value":2}
+			]`,
+			want: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:  "A.9 testing a value: error",
+			doc:   `{"baz":"qux"}`,
+			patch: `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:   ErrPatchTestFailed,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:  `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:   ErrPointerKeyNotFound,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":10}]`,
+			want:  `{"/":9,"~1":10}`,
+		},
+		{
+			name:  "A.15 comparing strings and numbers",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":"10"}]`,
+			err:   ErrPatchTestFailed,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:  `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:  "copy",
+			doc:   `{"a":{"b":[1]}}`,
+			patch: `[{"op":"copy","from":"/a/b","path":"/c"},{"op":"add","path":"/c/-","value":2}]`,
+			want:  `{"a":{"b":[1]},"c":[1,2]}`,
+		},
+		{
+			name:  "test numbers by value",
+			doc:   `{"n":1.0}`,
+			patch: `[{"op":"test","path":"/n","value":1}]`,
+			want:  `{"n":1.0}`,
+		},
+		{
+			name:  "move into own child",
+			doc:   `{"a":{"b":{}}}`,
+			patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			err:   ErrPatchInvalidOp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(tt.doc, tt.patch)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ApplyPatch() error = %v, want %v", err, tt.err)
+				}
+				var patchErr *PatchError
+				if !errors.As(err, &patchErr) {
+					t.Errorf("ApplyPatch() error = %T, want *PatchError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePatchInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		// RFC 6902 A.13：重复的 op 成员使补丁无效
+		{name: "A.13 invalid JSON patch document", patch: `[{"op":"add","path":"/baz","value":"qux","op":"move"}]`},
+		{name: "missing op", patch: `[{"path":"/a"}]`},
+		{name: "missing path", patch: `[{"op":"remove"}]`},
+		{name: "missing value", patch: `[{"op":"add","path":"/a"}]`},
+		{name: "missing from", patch: `[{"op":"move","path":"/a"}]`},
+		{name: "unknown op", patch: `[{"op":"merge","path":"/a","value":1}]`},
+		{name: "non-string path", patch: `[{"op":"remove","path":1}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodePatch([]byte(tt.patch)); !errors.Is(err, ErrPatchInvalidOp) {
+				t.Errorf("DecodePatch() error = %v, want %v", err, ErrPatchInvalidOp)
+			}
+		})
+	}
+}
+
+func TestPatchApplyAtomic(t *testing.T) {
+	doc, err := DecodeOrdered([]byte(`{"a":1,"b":[1,2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := DecodePatch([]byte(`[
+		{"op":"replace","path":"/a","value":2},
+		{"op":"remove","path":"/b/0"},
+		{"op":"remove","path":"/missing"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = patch.Apply(doc)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 2 {
+		t.Fatalf("Apply() error = %v, want failure at operation 2", err)
+	}
+	if got, _ := ToJSON(doc); got != `{"a":1,"b":[1,2]}` {
+		t.Errorf("document modified after failed patch: %s", got)
+	}
+}
+
+func TestCreatePatchJSON(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{a: `{"foo":"bar"}`, b: `{"foo":"bar","baz":"qux"}`},
+		{a: `{"foo":["all","grass","cows","eat"]}`, b: `{"foo":["all","cows","eat","grass"]}`},
+		{a: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, b: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{a: `{"/":9,"~1":10}`, b: `{"/":9,"~1":11,"a/b":[]}`},
+		{a: `[]`, b: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.b, func(t *testing.T) {
+			patch, err := CreatePatchJSON(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, err := patch.ToJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ApplyPatch(tt.a, text)
+			if err != nil {
+				t.Fatalf("ApplyPatch(%s) error = %v", text, err)
+			}
+			if !jsonEqual(got, tt.b) {
+				t.Errorf("ApplyPatch(%s) = %s, want %s", text, got, tt.b)
+			}
+		})
+	}
+
+	patch, err := CreatePatchJSON(`{"a":1}`, `{"a":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := patch.ToJSON(); text != "[]" {
+		t.Errorf("CreatePatchJSON() of equal documents = %s, want []", text)
+	}
+}
@@ -0,0 +1,417 @@
+package jsonutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// JSON Pointer 操作可能返回的错误，可通过 errors.Is 判断
+var (
+	ErrPointerSyntax       = errors.New("invalid JSON pointer syntax")
+	ErrPointerKeyNotFound  = errors.New("key not found")
+	ErrPointerInvalidIndex = errors.New("invalid array index")
+	ErrPointerOutOfRange   = errors.New("array index out of range")
+	ErrPointerNotContainer = errors.New("value is not an object or array")
+	ErrPointerRoot         = errors.New("operation not allowed on document root")
+)
+
+// PointerError 描述 JSON Pointer 在哪一段上失败
+type PointerError struct {
+	Pointer string // 完整的指针字符串
+	Segment string // 失败的段（未转义）
+	Index   int    // 失败段的序号，从 0 开始；-1 表示指针本身有误
+	Err     error  // 底层错误
+}
+
+// Error 实现 error 接口
+func (e *PointerError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("json pointer %q: %v", e.Pointer, e.Err)
+	}
+	return fmt.Sprintf("json pointer %q: segment %q (#%d): %v", e.Pointer, e.Segment, e.Index, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+// JSONPointer 表示 RFC 6901 JSON Pointer，每个元素是一个未转义的引用段
+type JSONPointer []string
+
+// NewJSONPointer 使用未转义的引用段创建 JSONPointer
+func NewJSONPointer(tokens ...string) JSONPointer {
+	return append(JSONPointer{}, tokens...)
+}
+
+// ParseJSONPointer 解析 JSON Pointer 字符串，支持 URI 片段形式（以 # 开头）
+func ParseJSONPointer(s string) (JSONPointer, error) {
+	raw := s
+	if strings.HasPrefix(s, "#") {
+		decoded, err := url.PathUnescape(s[1:])
+		if err != nil {
+			return nil, &PointerError{Pointer: raw, Index: -1, Err: ErrPointerSyntax}
+		}
+		s = decoded
+	}
+	if s == "" {
+		return JSONPointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, &PointerError{Pointer: raw, Index: -1, Err: ErrPointerSyntax}
+	}
+	parts := strings.Split(s[1:], "/")
+	p := make(JSONPointer, len(parts))
+	for i, part := range parts {
+		token, ok := unescapePointerToken(part)
+		if !ok {
+			return nil, &PointerError{Pointer: raw, Segment: part, Index: i, Err: ErrPointerSyntax}
+		}
+		p[i] = token
+	}
+	return p, nil
+}
+
+// MustParseJSONPointer 解析 JSON Pointer 字符串，失败时 panic
+func MustParseJSONPointer(s string) JSONPointer {
+	p, err := ParseJSONPointer(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// unescapePointerToken 将 ~1 还原为 /，~0 还原为 ~
+func unescapePointerToken(s string) (string, bool) {
+	if !strings.Contains(s, "~") {
+		return s, true
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '~' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return "", false
+		}
+		switch s[i+1] {
+		case '0':
+			sb.WriteByte('~')
+		case '1':
+			sb.WriteByte('/')
+		default:
+			return "", false
+		}
+		i++
+	}
+	return sb.String(), true
+}
+
+// escapePointerToken 将 ~ 转义为 ~0，/ 转义为 ~1
+func escapePointerToken(s string) string {
+	if !strings.ContainsAny(s, "~/") {
+		return s
+	}
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// String 返回转义后的指针字符串
+func (p JSONPointer) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteByte('/')
+		sb.WriteString(escapePointerToken(token))
+	}
+	return sb.String()
+}
+
+// IsRoot 判断指针是否指向文档根
+func (p JSONPointer) IsRoot() bool {
+	return len(p) == 0
+}
+
+// Append 返回追加了引用段的新指针
+func (p JSONPointer) Append(tokens ...string) JSONPointer {
+	np := make(JSONPointer, 0, len(p)+len(tokens))
+	np = append(np, p...)
+	return append(np, tokens...)
+}
+
+// AppendIndex 返回追加了数组下标的新指针
+func (p JSONPointer) AppendIndex(i int) JSONPointer {
+	return p.Append(strconv.Itoa(i))
+}
+
+// Parent 返回父指针，根指针的父指针仍为根
+func (p JSONPointer) Parent() JSONPointer {
+	if len(p) == 0 {
+		return p
+	}
+	return p[: len(p)-1 : len(p)-1]
+}
+
+// HasPrefix 判断指针是否以 prefix 开头
+func (p JSONPointer) HasPrefix(prefix JSONPointer) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// errAt 构造指定段上的错误
+func (p JSONPointer) errAt(index int, err error) error {
+	return &PointerError{Pointer: p.String(), Segment: p[index], Index: index, Err: err}
+}
+
+// arrayIndex 解析数组下标，allowEnd 为 true 时允许等于数组长度（包括 "-"）
+func (p JSONPointer) arrayIndex(arr []interface{}, depth int, allowEnd bool) (int, error) {
+	token := p[depth]
+	if token == "-" {
+		if allowEnd {
+			return len(arr), nil
+		}
+		return 0, p.errAt(depth, ErrPointerOutOfRange)
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, p.errAt(depth, ErrPointerInvalidIndex)
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, p.errAt(depth, ErrPointerInvalidIndex)
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, p.errAt(depth, ErrPointerInvalidIndex)
+	}
+	if i > len(arr) || (i == len(arr) && !allowEnd) {
+		return 0, p.errAt(depth, ErrPointerOutOfRange)
+	}
+	return i, nil
+}
+
+// Get 获取指针指向的值
+func (p JSONPointer) Get(doc interface{}) (interface{}, error) {
+	node := doc
+	for depth, token := range p {
+		switch c := node.(type) {
+		case map[string]interface{}, *JSONObject:
+			v, ok := objectGet(c, token)
+			if !ok {
+				return nil, p.errAt(depth, ErrPointerKeyNotFound)
+			}
+			node = v
+		case []interface{}:
+			i, err := p.arrayIndex(c, depth, false)
+			if err != nil {
+				return nil, err
+			}
+			node = c[i]
+		default:
+			return nil, p.errAt(depth, ErrPointerNotContainer)
+		}
+	}
+	return node, nil
+}
+
+// Has 判断指针指向的值是否存在
+func (p JSONPointer) Has(doc interface{}) bool {
+	_, err := p.Get(doc)
+	return err == nil
+}
+
+// Set 设置指针指向的值：对象成员不存在时创建，数组下标必须存在或为 "-"（追加）
+// 返回修改后的文档根，调用方应使用返回值替换原文档
+func (p JSONPointer) Set(doc, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+	return p.modify(doc, 0, func(container interface{}, depth int) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}, *JSONObject:
+			objectSet(c, p[depth], value)
+			return c, nil
+		case []interface{}:
+			i, err := p.arrayIndex(c, depth, true)
+			if err != nil {
+				return nil, err
+			}
+			if i == len(c) {
+				return append(c, value), nil
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, p.errAt(depth, ErrPointerNotContainer)
+	})
+}
+
+// Add 按 RFC 6902 add 语义添加值：对象成员被创建或替换，数组在下标处插入，"-" 表示追加
+// 返回修改后的文档根，调用方应使用返回值替换原文档
+func (p JSONPointer) Add(doc, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+	return p.modify(doc, 0, func(container interface{}, depth int) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}, *JSONObject:
+			objectSet(c, p[depth], value)
+			return c, nil
+		case []interface{}:
+			i, err := p.arrayIndex(c, depth, true)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, 0, len(c)+1)
+			result = append(result, c[:i]...)
+			result = append(result, value)
+			return append(result, c[i:]...), nil
+		}
+		return nil, p.errAt(depth, ErrPointerNotContainer)
+	})
+}
+
+// Replace 替换指针指向的已存在的值
+// 返回修改后的文档根，调用方应使用返回值替换原文档
+func (p JSONPointer) Replace(doc, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+	return p.modify(doc, 0, func(container interface{}, depth int) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}, *JSONObject:
+			if _, ok := objectGet(c, p[depth]); !ok {
+				return nil, p.errAt(depth, ErrPointerKeyNotFound)
+			}
+			objectSet(c, p[depth], value)
+			return c, nil
+		case []interface{}:
+			i, err := p.arrayIndex(c, depth, false)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, p.errAt(depth, ErrPointerNotContainer)
+	})
+}
+
+// Remove 删除指针指向的值，数组中后续元素前移
+// 返回修改后的文档根，调用方应使用返回值替换原文档
+func (p JSONPointer) Remove(doc interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return nil, &PointerError{Pointer: "", Index: -1, Err: ErrPointerRoot}
+	}
+	return p.modify(doc, 0, func(container interface{}, depth int) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}, *JSONObject:
+			if _, ok := objectGet(c, p[depth]); !ok {
+				return nil, p.errAt(depth, ErrPointerKeyNotFound)
+			}
+			objectDelete(c, p[depth])
+			return c, nil
+		case []interface{}:
+			i, err := p.arrayIndex(c, depth, false)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, 0, len(c)-1)
+			result = append(result, c[:i]...)
+			return append(result, c[i+1:]...), nil
+		}
+		return nil, p.errAt(depth, ErrPointerNotContainer)
+	})
+}
+
+// modify 沿指针下行到父容器，调用 leaf 修改后将新容器逐级写回
+func (p JSONPointer) modify(node interface{}, depth int, leaf func(container interface{}, depth int) (interface{}, error)) (interface{}, error) {
+	if depth == len(p)-1 {
+		return leaf(node, depth)
+	}
+	switch c := node.(type) {
+	case map[string]interface{}, *JSONObject:
+		child, ok := objectGet(c, p[depth])
+		if !ok {
+			return nil, p.errAt(depth, ErrPointerKeyNotFound)
+		}
+		nc, err := p.modify(child, depth+1, leaf)
+		if err != nil {
+			return nil, err
+		}
+		objectSet(c, p[depth], nc)
+		return c, nil
+	case []interface{}:
+		i, err := p.arrayIndex(c, depth, false)
+		if err != nil {
+			return nil, err
+		}
+		nc, err := p.modify(c[i], depth+1, leaf)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = nc
+		return c, nil
+	}
+	return nil, p.errAt(depth, ErrPointerNotContainer)
+}
+
+// GetByPointer 通过 JSON Pointer 获取 JSON 字符串中的值
+func GetByPointer(jsonStr, pointer string) (interface{}, error) {
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeAny([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(doc)
+}
+
+// SetByPointer 通过 JSON Pointer 设置 JSON 字符串中的值，返回新的 JSON 字符串
+func SetByPointer(jsonStr, pointer string, value interface{}) (string, error) {
+	return modifyJSONByPointer(jsonStr, pointer, func(p JSONPointer, doc interface{}) (interface{}, error) {
+		return p.Set(doc, value)
+	})
+}
+
+// AddByPointer 按 RFC 6902 add 语义向 JSON 字符串添加值，返回新的 JSON 字符串
+func AddByPointer(jsonStr, pointer string, value interface{}) (string, error) {
+	return modifyJSONByPointer(jsonStr, pointer, func(p JSONPointer, doc interface{}) (interface{}, error) {
+		return p.Add(doc, value)
+	})
+}
+
+// RemoveByPointer 通过 JSON Pointer 删除 JSON 字符串中的值，返回新的 JSON 字符串
+func RemoveByPointer(jsonStr, pointer string) (string, error) {
+	return modifyJSONByPointer(jsonStr, pointer, func(p JSONPointer, doc interface{}) (interface{}, error) {
+		return p.Remove(doc)
+	})
+}
+
+// modifyJSONByPointer 解析 JSON 字符串，执行修改后重新编码，对象键保持原有顺序
+func modifyJSONByPointer(jsonStr, pointer string, fn func(JSONPointer, interface{}) (interface{}, error)) (string, error) {
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	doc, err := DecodeOrdered([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	doc, err = fn(p, doc)
+	if err != nil {
+		return "", err
+	}
+	return ToJSON(doc)
+}
@@ -0,0 +1,136 @@
+package jsonutil
+
+import (
+	"errors"
+	"testing"
+)
+
+// rfc6901Document 是 RFC 6901 第 5 节的示例文档
+const rfc6901Document = `{
+  "foo": ["bar", "baz"],
+  "": 0,
+  "a/b": 1,
+  "c%d": 2,
+  "e^f": 3,
+  "g|h": 4,
+  "i\\j": 5,
+  "k\"l": 6,
+  " ": 7,
+  "m~n": 8
+}`
+
+func TestJSONPointerRFC6901(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		fragment string
+		want     string
+	}{
+		{pointer: ``, fragment: `#`, want: `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`},
+		{pointer: `/foo`, fragment: `#/foo`, want: `["bar","baz"]`},
+		{pointer: `/foo/0`, fragment: `#/foo/0`, want: `"bar"`},
+		{pointer: `/`, fragment: `#/`, want: `0`},
+		{pointer: `/a~1b`, fragment: `#/a~1b`, want: `1`},
+		{pointer: `/c%d`, fragment: `#/c%25d`, want: `2`},
+		{pointer: `/e^f`, fragment: `#/e%5Ef`, want: `3`},
+		{pointer: `/g|h`, fragment: `#/g%7Ch`, want: `4`},
+		{pointer: `/i\j`, fragment: `#/i%5Cj`, want: `5`},
+		{pointer: `/k"l`, fragment: `#/k%22l`, want: `6`},
+		{pointer: `/ `, fragment: `#/%20`, want: `7`},
+		{pointer: `/m~0n`, fragment: `#/m~0n`, want: `8`},
+	}
+	doc, err := DecodeOrdered([]byte(rfc6901Document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		for _, s := range []string{tt.pointer, tt.fragment} {
+			t.Run(s, func(t *testing.T) {
+				p, err := ParseJSONPointer(s)
+				if err != nil {
+					t.Fatalf("ParseJSONPointer() error = %v", err)
+				}
+				v, err := p.Get(doc)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if got, _ := ToJSON(v); got != tt.want {
+					t.Errorf("Get() = %s, want %s", got, tt.want)
+				}
+				if p.String() != tt.pointer {
+					t.Errorf("String() = %q, want %q", p.String(), tt.pointer)
+				}
+			})
+		}
+	}
+}
+
+func TestJSONPointerErrors(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    error
+	}{
+		{pointer: `foo`, want: ErrPointerSyntax},
+		{pointer: `/m~2n`, want: ErrPointerSyntax},
+		{pointer: `/a~`, want: ErrPointerSyntax},
+		{pointer: `/missing`, want: ErrPointerKeyNotFound},
+		{pointer: `/foo/2`, want: ErrPointerOutOfRange},
+		{pointer: `/foo/-`, want: ErrPointerOutOfRange},
+		{pointer: `/foo/01`, want: ErrPointerInvalidIndex},
+		{pointer: `/foo/-1`, want: ErrPointerInvalidIndex},
+		{pointer: `/foo/x`, want: ErrPointerInvalidIndex},
+		{pointer: `/foo/0/bar`, want: ErrPointerNotContainer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			_, err := GetByPointer(rfc6901Document, tt.pointer)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("GetByPointer() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err := GetByPointer(`{"a":{"b":1}}`, `/a/c`)
+	var pe *PointerError
+	if !errors.As(err, &pe) || pe.Segment != "c" || pe.Index != 1 {
+		t.Errorf("GetByPointer() error = %#v, want segment c at index 1", err)
+	}
+}
+
+func TestJSONPointerModify(t *testing.T) {
+	const doc = `{"a":{"b":[1,2]},"c":3}`
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{name: "set member", fn: func() (string, error) { return SetByPointer(doc, "/a/x", 9) }, want: `{"a":{"b":[1,2],"x":9},"c":3}`},
+		{name: "set element", fn: func() (string, error) { return SetByPointer(doc, "/a/b/0", 9) }, want: `{"a":{"b":[9,2]},"c":3}`},
+		{name: "set append", fn: func() (string, error) { return SetByPointer(doc, "/a/b/-", 9) }, want: `{"a":{"b":[1,2,9]},"c":3}`},
+		{name: "add inserts", fn: func() (string, error) { return AddByPointer(doc, "/a/b/1", 9) }, want: `{"a":{"b":[1,9,2]},"c":3}`},
+		{name: "add at end", fn: func() (string, error) { return AddByPointer(doc, "/a/b/2", 9) }, want: `{"a":{"b":[1,2,9]},"c":3}`},
+		{name: "remove member", fn: func() (string, error) { return RemoveByPointer(doc, "/c") }, want: `{"a":{"b":[1,2]}}`},
+		{name: "remove element", fn: func() (string, error) { return RemoveByPointer(doc, "/a/b/0") }, want: `{"a":{"b":[2]},"c":3}`},
+		{name: "set root", fn: func() (string, error) { return SetByPointer(doc, "", []int{1}) }, want: `[1]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RemoveByPointer(doc, ""); !errors.Is(err, ErrPointerRoot) {
+		t.Errorf("RemoveByPointer(root) error = %v, want %v", err, ErrPointerRoot)
+	}
+	if _, err := AddByPointer(doc, "/a/b/3", 9); !errors.Is(err, ErrPointerOutOfRange) {
+		t.Errorf("AddByPointer() error = %v, want %v", err, ErrPointerOutOfRange)
+	}
+	if _, err := SetByPointer(doc, "/missing/x", 9); !errors.Is(err, ErrPointerKeyNotFound) {
+		t.Errorf("SetByPointer() error = %v, want %v", err, ErrPointerKeyNotFound)
+	}
+}
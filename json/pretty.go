@@ -0,0 +1,368 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorScheme 定义终端输出各类词法单元使用的 ANSI 转义序列，为空的字段不着色
+type ColorScheme struct {
+	Key    string // 对象的键
+	String string // 字符串值
+	Number string // 数字
+	Bool   string // true 和 false
+	Null   string // null
+	Delim  string // 括号、逗号和冒号
+}
+
+// DefaultColorScheme 是 WithColor 使用的默认配色
+var DefaultColorScheme = ColorScheme{
+	Key:    "\x1b[34;1m",
+	String: "\x1b[32m",
+	Number: "\x1b[36m",
+	Bool:   "\x1b[33m",
+	Null:   "\x1b[90m",
+}
+
+// ansiReset 恢复终端默认样式
+const ansiReset = "\x1b[0m"
+
+// prettyOptions 保存格式化的选项
+type prettyOptions struct {
+	indent          string
+	sortKeys        bool
+	maxWidth        int
+	colors          *ColorScheme
+	escapeHTML      *bool
+	trailingNewline bool
+}
+
+// PrettyOption 是设置格式化选项的函数类型
+type PrettyOption func(*prettyOptions)
+
+// WithPrettyIndent 设置缩进字符串，默认为两个空格
+func WithPrettyIndent(indent string) PrettyOption {
+	return func(o *prettyOptions) {
+		o.indent = indent
+	}
+}
+
+// WithSortedKeys 按字典序输出对象的键，默认保持原有顺序
+func WithSortedKeys() PrettyOption {
+	return func(o *prettyOptions) {
+		o.sortKeys = true
+	}
+}
+
+// WithMaxWidth 设置最大行宽，能在一行内放下的对象和数组输出为单行，0 表示总是展开
+func WithMaxWidth(width int) PrettyOption {
+	return func(o *prettyOptions) {
+		o.maxWidth = width
+	}
+}
+
+// WithColor 使用 DefaultColorScheme 输出 ANSI 着色的文本，用于终端显示
+func WithColor() PrettyOption {
+	return WithColorScheme(DefaultColorScheme)
+}
+
+// WithColorScheme 使用指定的配色输出 ANSI 着色的文本
+func WithColorScheme(scheme ColorScheme) PrettyOption {
+	return func(o *prettyOptions) {
+		o.colors = &scheme
+	}
+}
+
+// WithEscapeHTML 为 true 时将字符串中的 <、>、& 转义为 \u003c 等形式，为 false 时还原这些转义；
+// 未设置时保持输入中的写法
+func WithEscapeHTML(escape bool) PrettyOption {
+	return func(o *prettyOptions) {
+		o.escapeHTML = &escape
+	}
+}
+
+// WithTrailingNewline 在输出的末尾添加换行符
+func WithTrailingNewline() PrettyOption {
+	return func(o *prettyOptions) {
+		o.trailingNewline = true
+	}
+}
+
+// FormatJSON 格式化 JSON 字节，直接处理原始文本而不解码为 map，数字和字符串保持原样
+func FormatJSON(data []byte, opts ...PrettyOption) ([]byte, error) {
+	o := prettyOptions{indent: "  "}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+	p := &prettyParser{data: compact.Bytes(), opts: &o}
+	root := p.parseValue()
+	f := &prettyFormatter{opts: &o, indentWidth: utf8.RuneCountInString(o.indent)}
+	f.write(root, 0, 0)
+	if o.trailingNewline {
+		f.buf.WriteByte('\n')
+	}
+	return f.buf.Bytes(), nil
+}
+
+// prettyNode 是格式化使用的语法树节点，标量保存已处理过转义的原始文本
+type prettyNode struct {
+	kind     byte // '{'、'[' 或 0（标量）
+	raw      []byte
+	key      []byte
+	children []*prettyNode
+	width    int // 单行输出的宽度，-1 表示尚未计算
+}
+
+// prettyParser 解析经过 json.Compact 校验和压缩的文本
+type prettyParser struct {
+	data []byte
+	pos  int
+	opts *prettyOptions
+}
+
+// parseValue 解析一个值，输入已经校验过，因此无需处理错误
+func (p *prettyParser) parseValue() *prettyNode {
+	n := &prettyNode{width: -1}
+	switch c := p.data[p.pos]; c {
+	case '{', '[':
+		n.kind = c
+		p.pos++
+		for p.data[p.pos] != c+2 { // '}' 和 ']' 分别比 '{' 和 '[' 大 2
+			var key []byte
+			if c == '{' {
+				key = p.parseString()
+				p.pos++ // ':'
+			}
+			child := p.parseValue()
+			child.key = key
+			n.children = append(n.children, child)
+			if p.data[p.pos] == ',' {
+				p.pos++
+			}
+		}
+		p.pos++
+		if c == '{' && p.opts.sortKeys {
+			sort.SliceStable(n.children, func(i, j int) bool {
+				return unquoteKey(n.children[i].key) < unquoteKey(n.children[j].key)
+			})
+		}
+	case '"':
+		n.raw = p.parseString()
+	default:
+		start := p.pos
+		for p.pos < len(p.data) && !strings.ContainsRune(",:]}", rune(p.data[p.pos])) {
+			p.pos++
+		}
+		n.raw = p.data[start:p.pos]
+	}
+	return n
+}
+
+// parseString 解析字符串并按选项处理 HTML 字符的转义
+func (p *prettyParser) parseString() []byte {
+	start := p.pos
+	p.pos++
+	for p.data[p.pos] != '"' {
+		if p.data[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos++
+	raw := p.data[start:p.pos]
+	if p.opts.escapeHTML == nil {
+		return raw
+	}
+	return rewriteHTMLEscapes(raw, *p.opts.escapeHTML)
+}
+
+// htmlEscapes 是 HTML 敏感字符对应的 JSON 转义
+var htmlEscapes = map[byte]string{'<': `\u003c`, '>': `\u003e`, '&': `\u0026`}
+
+// htmlUnescapes 是 htmlEscapes 的反向映射，键为 \u 之后的四位十六进制数（小写）
+var htmlUnescapes = map[string]byte{"003c": '<', "003e": '>', "0026": '&'}
+
+// rewriteHTMLEscapes 转义或还原带引号字符串中的 <、>、&
+func rewriteHTMLEscapes(raw []byte, escape bool) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\':
+			n := 2
+			if raw[i+1] == 'u' {
+				n = 6
+			}
+			seq := raw[i : i+n]
+			i += n - 1
+			if n == 6 && !escape {
+				if r, ok := htmlUnescapes[strings.ToLower(string(seq[2:]))]; ok {
+					buf.WriteByte(r)
+					continue
+				}
+			}
+			buf.Write(seq)
+		case escape && htmlEscapes[c] != "":
+			buf.WriteString(htmlEscapes[c])
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.Bytes()
+}
+
+// unquoteKey 解码带引号的键，用于排序
+func unquoteKey(raw []byte) string {
+	var s string
+	_ = json.Unmarshal(raw, &s)
+	return s
+}
+
+// prettyFormatter 输出格式化的文本
+type prettyFormatter struct {
+	opts        *prettyOptions
+	indentWidth int
+	buf         bytes.Buffer
+}
+
+// inlineWidth 返回节点输出为单行时的宽度
+func (f *prettyFormatter) inlineWidth(n *prettyNode) int {
+	if n.width >= 0 {
+		return n.width
+	}
+	if n.kind == 0 {
+		n.width = utf8.RuneCount(n.raw)
+		return n.width
+	}
+	w := 2
+	for i, child := range n.children {
+		if i > 0 {
+			w += 2
+		}
+		if n.kind == '{' {
+			w += utf8.RuneCount(child.key) + 2
+		}
+		w += f.inlineWidth(child)
+	}
+	n.width = w
+	return w
+}
+
+// colored 按配色输出一段文本
+func (f *prettyFormatter) colored(color string, text []byte) {
+	if f.opts.colors == nil || color == "" {
+		f.buf.Write(text)
+		return
+	}
+	f.buf.WriteString(color)
+	f.buf.Write(text)
+	f.buf.WriteString(ansiReset)
+}
+
+// delim 输出括号、逗号、冒号等分隔符
+func (f *prettyFormatter) delim(s string) {
+	if f.opts.colors == nil {
+		f.buf.WriteString(s)
+		return
+	}
+	f.colored(f.opts.colors.Delim, []byte(s))
+}
+
+// scalar 输出标量值
+func (f *prettyFormatter) scalar(raw []byte) {
+	if f.opts.colors == nil {
+		f.buf.Write(raw)
+		return
+	}
+	var color string
+	switch raw[0] {
+	case '"':
+		color = f.opts.colors.String
+	case 't', 'f':
+		color = f.opts.colors.Bool
+	case 'n':
+		color = f.opts.colors.Null
+	default:
+		color = f.opts.colors.Number
+	}
+	f.colored(color, raw)
+}
+
+// key 输出对象的键和冒号
+func (f *prettyFormatter) key(raw []byte) {
+	if f.opts.colors == nil {
+		f.buf.Write(raw)
+	} else {
+		f.colored(f.opts.colors.Key, raw)
+	}
+	f.delim(":")
+	f.buf.WriteByte(' ')
+}
+
+// write 输出节点，column 为当前行已占用的宽度
+func (f *prettyFormatter) write(n *prettyNode, depth, column int) {
+	if n.kind == 0 {
+		f.scalar(n.raw)
+		return
+	}
+	openDelim, closeDelim := string(n.kind), string(n.kind+2)
+	if len(n.children) == 0 {
+		f.delim(openDelim + closeDelim)
+		return
+	}
+	// 预留一列给可能紧随其后的逗号
+	if f.opts.maxWidth > 0 && column+f.inlineWidth(n)+1 <= f.opts.maxWidth {
+		f.writeInline(n)
+		return
+	}
+	f.delim(openDelim)
+	for i, child := range n.children {
+		f.newline(depth + 1)
+		column := (depth + 1) * f.indentWidth
+		if n.kind == '{' {
+			f.key(child.key)
+			column += utf8.RuneCount(child.key) + 2
+		}
+		f.write(child, depth+1, column)
+		if i < len(n.children)-1 {
+			f.delim(",")
+		}
+	}
+	f.newline(depth)
+	f.delim(closeDelim)
+}
+
+// writeInline 将节点输出为单行
+func (f *prettyFormatter) writeInline(n *prettyNode) {
+	if n.kind == 0 {
+		f.scalar(n.raw)
+		return
+	}
+	f.delim(string(n.kind))
+	for i, child := range n.children {
+		if i > 0 {
+			f.delim(",")
+			f.buf.WriteByte(' ')
+		}
+		if n.kind == '{' {
+			f.key(child.key)
+		}
+		f.writeInline(child)
+	}
+	f.delim(string(n.kind + 2))
+}
+
+// newline 换行并缩进到指定层级
+func (f *prettyFormatter) newline(depth int) {
+	f.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		f.buf.WriteString(f.opts.indent)
+	}
+}
@@ -0,0 +1,1342 @@
+package jsonutil
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSON Schema 草案版本
+const (
+	Draft7      = 7
+	Draft202012 = 2020
+)
+
+// 草案版本对应的 $schema 标识
+const (
+	Draft7URI      = "http://json-schema.org/draft-07/schema#"
+	Draft202012URI = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// SchemaError 表示一处校验失败
+type SchemaError struct {
+	InstanceLocation        string // 实例中失败值的 JSON Pointer
+	KeywordLocation         string // 经过 $ref 的求值路径（JSON Pointer）
+	AbsoluteKeywordLocation string // 关键字所在的绝对位置（URI#JSON Pointer）
+	Message                 string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e SchemaError) Error() string {
+	loc := e.InstanceLocation
+	if loc == "" {
+		loc = "(root)"
+	}
+	return fmt.Sprintf("%s: %s (schema %s)", loc, e.Message, e.KeywordLocation)
+}
+
+// ValidationError 包含实例校验失败时的所有错误
+type ValidationError struct {
+	Errors []SchemaError
+}
+
+// Error 实现 error 接口
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "json schema validation failed with %d error(s)", len(e.Errors))
+	for _, se := range e.Errors {
+		sb.WriteString("\n  ")
+		sb.WriteString(se.Error())
+	}
+	return sb.String()
+}
+
+// schemaOptions 保存模式编译的选项
+type schemaOptions struct {
+	draft           int
+	baseURI         string
+	formatAssertion bool
+	loader          func(uri string) (interface{}, error)
+}
+
+// SchemaOption 是设置模式编译选项的函数类型
+type SchemaOption func(*schemaOptions)
+
+// WithSchemaDraft 设置模式未声明 $schema 时使用的草案版本，默认为 Draft202012
+func WithSchemaDraft(draft int) SchemaOption {
+	return func(o *schemaOptions) {
+		o.draft = draft
+	}
+}
+
+// WithSchemaBaseURI 设置根模式的基础 URI，用于解析相对引用，默认为当前工作目录
+func WithSchemaBaseURI(uri string) SchemaOption {
+	return func(o *schemaOptions) {
+		o.baseURI = uri
+	}
+}
+
+// WithFormatAssertion 设置是否校验 format 关键字，默认校验
+func WithFormatAssertion(enabled bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.formatAssertion = enabled
+	}
+}
+
+// WithSchemaLoader 设置外部模式的加载函数，默认仅支持 file:// 引用
+func WithSchemaLoader(loader func(uri string) (interface{}, error)) SchemaOption {
+	return func(o *schemaOptions) {
+		o.loader = loader
+	}
+}
+
+// loadSchemaFile 加载 file:// 形式的模式文件
+func loadSchemaFile(uri string) (interface{}, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("unsupported schema URI %q", uri)
+	}
+	data, err := os.ReadFile(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, err
+	}
+	return decodeAny(data)
+}
+
+// fileURI 将本地路径转换为 file:// URI
+func fileURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+}
+
+// SchemaCompiler 编译 JSON Schema，并管理通过 $id 或文件引用的外部模式资源
+type SchemaCompiler struct {
+	opts      schemaOptions
+	resources map[string]*schemaResource
+	anchors   map[string]interface{}
+	dynamic   map[string]bool
+	locations map[uintptr]string
+	compiled  map[uintptr]*schemaNode
+	pending   []string
+}
+
+// schemaResource 表示一个具有独立基础 URI 的模式资源
+type schemaResource struct {
+	raw   interface{}
+	draft int
+}
+
+// NewSchemaCompiler 创建一个新的 SchemaCompiler
+func NewSchemaCompiler(opts ...SchemaOption) *SchemaCompiler {
+	c := &SchemaCompiler{
+		opts:      schemaOptions{draft: Draft202012, formatAssertion: true, loader: loadSchemaFile},
+		resources: make(map[string]*schemaResource),
+		anchors:   make(map[string]interface{}),
+		dynamic:   make(map[string]bool),
+		locations: make(map[uintptr]string),
+		compiled:  make(map[uintptr]*schemaNode),
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	if c.opts.baseURI == "" {
+		if wd, err := os.Getwd(); err == nil {
+			c.opts.baseURI, _ = fileURI(filepath.Join(wd, "schema.json"))
+		}
+		if c.opts.baseURI == "" {
+			c.opts.baseURI = "urn:jsonutil:schema"
+		}
+	}
+	return c
+}
+
+// AddResource 注册一个模式资源，供 $ref 按 URI 引用
+func (c *SchemaCompiler) AddResource(uri, schemaJSON string) error {
+	raw, err := decodeAny([]byte(schemaJSON))
+	if err != nil {
+		return err
+	}
+	return c.addResource(uri, raw)
+}
+
+// addResource 注册已解析的模式资源并建立索引
+func (c *SchemaCompiler) addResource(uri string, raw interface{}) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	u.Fragment, u.RawFragment = "", ""
+	c.index(raw, u.String(), "", c.opts.draft, true)
+	return nil
+}
+
+// Compile 编译已注册的 URI 对应的模式
+func (c *SchemaCompiler) Compile(uri string) (*Schema, error) {
+	root, err := c.resolveRef(c.opts.baseURI, uri)
+	if err != nil {
+		return nil, err
+	}
+	for len(c.pending) > 0 {
+		next := c.pending[0]
+		c.pending = c.pending[1:]
+		if _, err := c.resolveRef(next, next); err != nil {
+			return nil, err
+		}
+	}
+	return &Schema{root: root, compiler: c}, nil
+}
+
+// CompileSchema 编译 JSON 字符串形式的模式
+func CompileSchema(schemaJSON string, opts ...SchemaOption) (*Schema, error) {
+	c := NewSchemaCompiler(opts...)
+	if err := c.AddResource(c.opts.baseURI, schemaJSON); err != nil {
+		return nil, err
+	}
+	return c.Compile(c.opts.baseURI)
+}
+
+// CompileSchemaFile 编译模式文件，文件中的相对引用相对于该文件解析
+func CompileSchemaFile(filename string, opts ...SchemaOption) (*Schema, error) {
+	uri, err := fileURI(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := NewSchemaCompiler(append(opts, WithSchemaBaseURI(uri))...)
+	return c.Compile(uri)
+}
+
+// ValidateJSON 使用模式校验 JSON 字符串，校验失败时返回 *ValidationError
+func ValidateJSON(schemaJSON, jsonStr string) error {
+	s, err := CompileSchema(schemaJSON)
+	if err != nil {
+		return err
+	}
+	return s.ValidateJSON(jsonStr)
+}
+
+// resolveURI 将引用相对于基础 URI 解析为绝对 URI
+func resolveURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+// splitFragment 将 URI 拆分为文档部分和（已解码的）片段
+func splitFragment(uri string) (string, string) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		if i := strings.IndexByte(uri, '#'); i >= 0 {
+			return uri[:i], uri[i+1:]
+		}
+		return uri, ""
+	}
+	fragment := u.Fragment
+	u.Fragment, u.RawFragment = "", ""
+	return u.String(), fragment
+}
+
+// mapKey 返回 map 的标识，用于缓存编译结果
+func mapKey(m map[string]interface{}) uintptr {
+	return reflect.ValueOf(m).Pointer()
+}
+
+// detectDraft 根据 $schema 判断草案版本
+func detectDraft(raw interface{}, fallback int) int {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return fallback
+	}
+	s, _ := m["$schema"].(string)
+	switch {
+	case strings.Contains(s, "draft-07"), strings.Contains(s, "draft-06"), strings.Contains(s, "draft-04"):
+		return Draft7
+	case strings.Contains(s, "2020-12"), strings.Contains(s, "2019-09"):
+		return Draft202012
+	}
+	return fallback
+}
+
+// 包含单个子模式的关键字
+var schemaKeywords = []string{
+	"additionalProperties", "propertyNames", "items", "additionalItems", "contains", "not",
+	"if", "then", "else", "unevaluatedItems", "unevaluatedProperties", "contentSchema",
+}
+
+// 包含子模式映射的关键字
+var schemaMapKeywords = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "dependencies"}
+
+// 包含子模式数组的关键字
+var schemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems", "items"}
+
+// index 遍历模式，登记资源、锚点以及每个子模式的位置
+func (c *SchemaCompiler) index(raw interface{}, base, pointer string, draft int, isRoot bool) {
+	if isRoot {
+		draft = detectDraft(raw, draft)
+		if _, exists := c.resources[base]; !exists {
+			c.resources[base] = &schemaResource{raw: raw, draft: draft}
+		}
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if id, ok := m["$id"].(string); ok {
+		if draft == Draft7 && strings.HasPrefix(id, "#") {
+			c.anchors[base+id] = m
+		} else if abs, err := resolveURI(base, id); err == nil {
+			doc, _ := splitFragment(abs)
+			if doc != base || isRoot {
+				draft = detectDraft(raw, draft)
+			}
+			base, pointer = doc, ""
+			isRoot = true
+		}
+	}
+	if isRoot {
+		if _, exists := c.resources[base]; !exists {
+			c.resources[base] = &schemaResource{raw: raw, draft: draft}
+		}
+	}
+	c.locations[mapKey(m)] = base + "#" + pointer
+	if anchor, ok := m["$anchor"].(string); ok {
+		c.anchors[base+"#"+anchor] = m
+	}
+	if anchor, ok := m["$dynamicAnchor"].(string); ok {
+		c.anchors[base+"#"+anchor] = m
+		c.dynamic[base+"#"+anchor] = true
+		c.pending = append(c.pending, base+"#"+anchor)
+	}
+	for _, kw := range schemaKeywords {
+		if v, ok := m[kw]; ok {
+			c.index(v, base, pointer+"/"+kw, draft, false)
+		}
+	}
+	for _, kw := range schemaMapKeywords {
+		if sub, ok := m[kw].(map[string]interface{}); ok {
+			for k, v := range sub {
+				c.index(v, base, pointer+"/"+kw+"/"+escapePointerToken(k), draft, false)
+			}
+		}
+	}
+	for _, kw := range schemaArrayKeywords {
+		if arr, ok := m[kw].([]interface{}); ok {
+			for i, v := range arr {
+				c.index(v, base, pointer+"/"+kw+"/"+strconv.Itoa(i), draft, false)
+			}
+		}
+	}
+}
+
+// resolveRef 解析引用并编译目标模式
+func (c *SchemaCompiler) resolveRef(base, ref string) (*schemaNode, error) {
+	abs, err := resolveURI(base, ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	raw, resBase, draft, err := c.lookup(abs)
+	if err != nil {
+		return nil, err
+	}
+	return c.compile(raw, resBase, draft)
+}
+
+// lookup 查找绝对 URI 指向的原始模式及其基础 URI
+func (c *SchemaCompiler) lookup(abs string) (interface{}, string, int, error) {
+	doc, fragment := splitFragment(abs)
+	res, ok := c.resources[doc]
+	if !ok {
+		raw, err := c.opts.loader(doc)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("cannot load schema %q: %w", doc, err)
+		}
+		c.index(raw, doc, "", c.opts.draft, true)
+		if res, ok = c.resources[doc]; !ok {
+			return nil, "", 0, fmt.Errorf("cannot load schema %q", doc)
+		}
+	}
+	if fragment == "" {
+		return res.raw, doc, res.draft, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		raw, ok := c.anchors[doc+"#"+fragment]
+		if !ok {
+			return nil, "", 0, fmt.Errorf("anchor %q not found in %q", fragment, doc)
+		}
+		return raw, doc, res.draft, nil
+	}
+	p, err := ParseJSONPointer(fragment)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	node, base, draft := res.raw, doc, res.draft
+	for i, token := range p {
+		switch cur := node.(type) {
+		case map[string]interface{}:
+			next, ok := cur[token]
+			if !ok {
+				return nil, "", 0, fmt.Errorf("$ref %q: %w", abs, p.errAt(i, ErrPointerKeyNotFound))
+			}
+			node = next
+		case []interface{}:
+			idx, err := p.arrayIndex(cur, i, false)
+			if err != nil {
+				return nil, "", 0, fmt.Errorf("$ref %q: %w", abs, err)
+			}
+			node = cur[idx]
+		default:
+			return nil, "", 0, fmt.Errorf("$ref %q: %w", abs, p.errAt(i, ErrPointerNotContainer))
+		}
+		if m, ok := node.(map[string]interface{}); ok {
+			if id, ok := m["$id"].(string); ok && !(draft == Draft7 && strings.HasPrefix(id, "#")) {
+				if resolved, err := resolveURI(base, id); err == nil {
+					base, _ = splitFragment(resolved)
+					draft = detectDraft(m, draft)
+				}
+			}
+		}
+	}
+	return node, base, draft, nil
+}
+
+// schemaNode 是编译后的模式
+type schemaNode struct {
+	boolean  *bool
+	location string
+	base     string
+	draft    int
+	resource bool
+
+	ref           *schemaNode
+	dynamicRef    *schemaNode
+	dynamicAnchor string
+
+	types    []string
+	enum     []interface{}
+	hasEnum  bool
+	constVal interface{}
+	hasConst bool
+
+	minimum, maximum                   interface{}
+	exclusiveMinimum, exclusiveMaximum interface{}
+	multipleOf                         interface{}
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+
+	minItems, maxItems       *int
+	uniqueItems              bool
+	prefixItems              []*schemaNode
+	items                    *schemaNode
+	contains                 *schemaNode
+	minContains, maxContains *int
+	unevaluatedItems         *schemaNode
+
+	minProperties, maxProperties *int
+	required                     []string
+	properties                   map[string]*schemaNode
+	patternProperties            []patternSchema
+	additionalProperties         *schemaNode
+	propertyNames                *schemaNode
+	dependentRequired            map[string][]string
+	dependentSchemas             map[string]*schemaNode
+	depRequiredKeyword           string
+	depSchemasKeyword            string
+	unevaluatedProperties        *schemaNode
+
+	allOf, anyOf, oneOf []*schemaNode
+	not                 *schemaNode
+	ifSchema            *schemaNode
+	thenSchema          *schemaNode
+	elseSchema          *schemaNode
+}
+
+// patternSchema 表示 patternProperties 中的一项
+type patternSchema struct {
+	source string
+	re     *regexp.Regexp
+	schema *schemaNode
+}
+
+// compile 编译原始模式，相同的模式对象只编译一次，从而支持递归引用
+func (c *SchemaCompiler) compile(raw interface{}, base string, draft int) (*schemaNode, error) {
+	switch v := raw.(type) {
+	case bool:
+		b := v
+		return &schemaNode{boolean: &b, base: base, draft: draft}, nil
+	case map[string]interface{}:
+		key := mapKey(v)
+		if n, ok := c.compiled[key]; ok {
+			return n, nil
+		}
+		n := &schemaNode{base: base, draft: draft, location: c.locations[key]}
+		if n.location == "" {
+			n.location = base + "#"
+		}
+		if id, ok := v["$id"].(string); ok && !(draft == Draft7 && strings.HasPrefix(id, "#")) {
+			n.resource = true
+			if abs, err := resolveURI(base, id); err == nil {
+				n.base, _ = splitFragment(abs)
+				n.draft = detectDraft(v, draft)
+			}
+		}
+		c.compiled[key] = n
+		if err := c.compileKeywords(n, v); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("schema must be an object or boolean, got %T", raw)
+}
+
+// compileKeywords 编译模式对象中的各个关键字
+func (c *SchemaCompiler) compileKeywords(n *schemaNode, m map[string]interface{}) error {
+	var err error
+	compileAt := func(v interface{}, path string) (*schemaNode, error) {
+		s, e := c.compile(v, n.base, n.draft)
+		if e == nil && s.boolean != nil {
+			s.location = n.location + path
+		}
+		return s, e
+	}
+	sub := func(kw string) *schemaNode {
+		v, ok := m[kw]
+		if !ok || err != nil {
+			return nil
+		}
+		var s *schemaNode
+		s, err = compileAt(v, "/"+kw)
+		return s
+	}
+	subArray := func(kw string) []*schemaNode {
+		arr, ok := m[kw].([]interface{})
+		if !ok || err != nil {
+			return nil
+		}
+		result := make([]*schemaNode, 0, len(arr))
+		for i, v := range arr {
+			s, e := compileAt(v, "/"+kw+"/"+strconv.Itoa(i))
+			if e != nil {
+				err = e
+				return nil
+			}
+			result = append(result, s)
+		}
+		return result
+	}
+	subMap := func(kw string) map[string]*schemaNode {
+		obj, ok := m[kw].(map[string]interface{})
+		if !ok || err != nil {
+			return nil
+		}
+		result := make(map[string]*schemaNode, len(obj))
+		for k, v := range obj {
+			s, e := compileAt(v, "/"+kw+"/"+escapePointerToken(k))
+			if e != nil {
+				err = e
+				return nil
+			}
+			result[k] = s
+		}
+		return result
+	}
+	nonNegative := func(kw string) *int {
+		f, ok := toFloat64(m[kw])
+		if !ok || f < 0 || f != math.Trunc(f) {
+			return nil
+		}
+		i := int(f)
+		return &i
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		if n.ref, err = c.resolveRef(n.base, ref); err != nil {
+			return err
+		}
+		if n.draft == Draft7 {
+			return nil
+		}
+	}
+	if ref, ok := m["$dynamicRef"].(string); ok {
+		if n.dynamicRef, err = c.resolveRef(n.base, ref); err != nil {
+			return err
+		}
+		if i := strings.IndexByte(ref, '#'); i >= 0 {
+			name := ref[i+1:]
+			if target, ok := c.anchors[n.dynamicRef.base+"#"+name]; ok && c.dynamic[n.dynamicRef.base+"#"+name] {
+				if tm, ok := target.(map[string]interface{}); ok && c.compiled[mapKey(tm)] == n.dynamicRef {
+					n.dynamicAnchor = name
+				}
+			}
+		}
+	}
+
+	switch t := m["type"].(type) {
+	case string:
+		n.types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				n.types = append(n.types, s)
+			}
+		}
+	}
+	if enum, ok := m["enum"].([]interface{}); ok {
+		n.enum, n.hasEnum = enum, true
+	}
+	if v, ok := m["const"]; ok {
+		n.constVal, n.hasConst = v, true
+	}
+
+	for kw, dst := range map[string]*interface{}{
+		"minimum": &n.minimum, "maximum": &n.maximum, "multipleOf": &n.multipleOf,
+		"exclusiveMinimum": &n.exclusiveMinimum, "exclusiveMaximum": &n.exclusiveMaximum,
+	} {
+		if v, ok := m[kw]; ok && isNumber(v) {
+			*dst = v
+		}
+	}
+	n.minLength, n.maxLength = nonNegative("minLength"), nonNegative("maxLength")
+	if pattern, ok := m["pattern"].(string); ok {
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s/pattern: invalid regular expression: %w", n.location, err)
+		}
+	}
+	n.format, _ = m["format"].(string)
+
+	n.minItems, n.maxItems = nonNegative("minItems"), nonNegative("maxItems")
+	n.uniqueItems, _ = m["uniqueItems"].(bool)
+	if _, isArray := m["items"].([]interface{}); isArray || n.draft == Draft7 {
+		// draft-07：items 为数组时是元组校验，additionalItems 校验其余元素
+		if isArray {
+			n.prefixItems = subArray("items")
+			n.items = sub("additionalItems")
+		} else {
+			n.items = sub("items")
+		}
+	} else {
+		n.prefixItems = subArray("prefixItems")
+		n.items = sub("items")
+	}
+	n.contains = sub("contains")
+	n.minContains, n.maxContains = nonNegative("minContains"), nonNegative("maxContains")
+	n.unevaluatedItems = sub("unevaluatedItems")
+
+	n.minProperties, n.maxProperties = nonNegative("minProperties"), nonNegative("maxProperties")
+	if required, ok := m["required"].([]interface{}); ok {
+		for _, r := range required {
+			if s, ok := r.(string); ok {
+				n.required = append(n.required, s)
+			}
+		}
+	}
+	n.properties = subMap("properties")
+	if pp, ok := m["patternProperties"].(map[string]interface{}); ok {
+		for _, source := range sortedKeys(pp) {
+			re, e := regexp.Compile(source)
+			if e != nil {
+				return fmt.Errorf("%s/patternProperties: invalid regular expression: %w", n.location, e)
+			}
+			s, e := compileAt(pp[source], "/patternProperties/"+escapePointerToken(source))
+			if e != nil {
+				return e
+			}
+			n.patternProperties = append(n.patternProperties, patternSchema{source: source, re: re, schema: s})
+		}
+	}
+	n.additionalProperties = sub("additionalProperties")
+	n.propertyNames = sub("propertyNames")
+	n.dependentSchemas = subMap("dependentSchemas")
+	n.depRequiredKeyword, n.depSchemasKeyword = "dependentRequired", "dependentSchemas"
+	if dr, ok := m["dependentRequired"].(map[string]interface{}); ok {
+		n.dependentRequired = make(map[string][]string, len(dr))
+		for k, v := range dr {
+			n.dependentRequired[k] = toStringSlice(v)
+		}
+	}
+	if deps, ok := m["dependencies"].(map[string]interface{}); ok {
+		n.depRequiredKeyword, n.depSchemasKeyword = "dependencies", "dependencies"
+		for k, v := range deps {
+			if arr, ok := v.([]interface{}); ok {
+				if n.dependentRequired == nil {
+					n.dependentRequired = make(map[string][]string)
+				}
+				n.dependentRequired[k] = toStringSlice(arr)
+				continue
+			}
+			s, e := compileAt(v, "/dependencies/"+escapePointerToken(k))
+			if e != nil {
+				return e
+			}
+			if n.dependentSchemas == nil {
+				n.dependentSchemas = make(map[string]*schemaNode)
+			}
+			n.dependentSchemas[k] = s
+		}
+	}
+	n.unevaluatedProperties = sub("unevaluatedProperties")
+
+	n.allOf, n.anyOf, n.oneOf = subArray("allOf"), subArray("anyOf"), subArray("oneOf")
+	n.not = sub("not")
+	n.ifSchema, n.thenSchema, n.elseSchema = sub("if"), sub("then"), sub("else")
+	return err
+}
+
+// toStringSlice 将 []interface{} 中的字符串取出
+func toStringSlice(v interface{}) []string {
+	arr, _ := v.([]interface{})
+	result := make([]string, 0, len(arr))
+	for _, item := range arr {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Schema 是编译后的 JSON Schema，可并发地用于校验多个实例
+type Schema struct {
+	root     *schemaNode
+	compiler *SchemaCompiler
+}
+
+// Validate 校验已解析的实例，失败时返回包含所有错误的 *ValidationError
+// 实例中的 *JSONObject、JSONArray 按普通的对象和数组校验
+func (s *Schema) Validate(instance interface{}) error {
+	v := &schemaValidator{formatAssertion: s.compiler.opts.formatAssertion, compiler: s.compiler}
+	errs, _ := v.validate(s.root, plainValue(instance), nil, "", nil)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateJSON 校验 JSON 字符串
+func (s *Schema) ValidateJSON(jsonStr string) error {
+	instance, err := decodeAny([]byte(jsonStr))
+	if err != nil {
+		return err
+	}
+	return s.Validate(instance)
+}
+
+// IsValid 判断实例是否符合模式
+func (s *Schema) IsValid(instance interface{}) bool {
+	return s.Validate(instance) == nil
+}
+
+// evaluated 记录已被求值的属性和数组元素，用于 unevaluated* 关键字
+type evaluated struct {
+	props map[string]bool
+	items map[int]bool
+}
+
+// merge 合并另一组求值记录
+func (e *evaluated) merge(other *evaluated) {
+	if other == nil {
+		return
+	}
+	for k := range other.props {
+		e.props[k] = true
+	}
+	for i := range other.items {
+		e.items[i] = true
+	}
+}
+
+// schemaValidator 执行实例校验
+type schemaValidator struct {
+	formatAssertion bool
+	compiler        *SchemaCompiler
+}
+
+// validate 使用模式校验实例，返回错误列表和求值记录
+func (v *schemaValidator) validate(n *schemaNode, inst interface{}, instLoc []string, kwLoc string, scope []*schemaNode) ([]SchemaError, *evaluated) {
+	ev := &evaluated{props: map[string]bool{}, items: map[int]bool{}}
+	var errs []SchemaError
+	fail := func(keyword, format string, args ...interface{}) {
+		errs = append(errs, SchemaError{
+			InstanceLocation:        JSONPointer(instLoc).String(),
+			KeywordLocation:         kwLoc + "/" + keyword,
+			AbsoluteKeywordLocation: n.location + "/" + keyword,
+			Message:                 fmt.Sprintf(format, args...),
+		})
+	}
+	if n.boolean != nil {
+		if !*n.boolean {
+			errs = append(errs, SchemaError{
+				InstanceLocation:        JSONPointer(instLoc).String(),
+				KeywordLocation:         kwLoc,
+				AbsoluteKeywordLocation: n.location,
+				Message:                 "schema is false, no value is allowed",
+			})
+		}
+		return errs, ev
+	}
+	if n.resource || len(scope) == 0 {
+		scope = append(scope[:len(scope):len(scope)], n)
+	}
+	apply := func(s *schemaNode, keyword string, collect bool) bool {
+		subErrs, subEv := v.validate(s, inst, instLoc, kwLoc+"/"+keyword, scope)
+		if collect {
+			errs = append(errs, subErrs...)
+		}
+		if len(subErrs) == 0 {
+			ev.merge(subEv)
+		}
+		return len(subErrs) == 0
+	}
+
+	if n.ref != nil {
+		apply(n.ref, "$ref", true)
+	}
+	if n.dynamicRef != nil {
+		target := n.dynamicRef
+		if n.dynamicAnchor != "" {
+			for _, s := range scope {
+				key := s.base + "#" + n.dynamicAnchor
+				if raw, ok := v.compiler.anchors[key]; ok && v.compiler.dynamic[key] {
+					if m, ok := raw.(map[string]interface{}); ok {
+						if compiled, ok := v.compiler.compiled[mapKey(m)]; ok {
+							target = compiled
+							break
+						}
+					}
+				}
+			}
+		}
+		apply(target, "$dynamicRef", true)
+	}
+
+	if len(n.types) > 0 {
+		matched := false
+		for _, t := range n.types {
+			if schemaTypeMatches(t, inst) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("type", "expected %s, got %s", strings.Join(n.types, " or "), schemaTypeOf(inst))
+		}
+	}
+	if n.hasEnum {
+		found := false
+		for _, e := range n.enum {
+			if valuesEqual(e, inst) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "value must be one of %s", compactJSON(n.enum))
+		}
+	}
+	if n.hasConst && !valuesEqual(n.constVal, inst) {
+		fail("const", "value must be %s", compactJSON(n.constVal))
+	}
+
+	switch x := inst.(type) {
+	case string:
+		v.validateString(n, x, fail)
+	case map[string]interface{}:
+		errs = append(errs, v.validateObject(n, x, instLoc, kwLoc, scope, ev, fail)...)
+	case []interface{}:
+		errs = append(errs, v.validateArray(n, x, instLoc, kwLoc, scope, ev, fail)...)
+	default:
+		if isNumber(inst) {
+			validateNumber(n, inst, fail)
+		}
+	}
+
+	for i, s := range n.allOf {
+		apply(s, "allOf/"+strconv.Itoa(i), true)
+	}
+	if len(n.anyOf) > 0 {
+		matched := false
+		for i, s := range n.anyOf {
+			if apply(s, "anyOf/"+strconv.Itoa(i), false) {
+				matched = true
+			}
+		}
+		if !matched {
+			fail("anyOf", "value does not match any schema in anyOf")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		var matched []int
+		for i, s := range n.oneOf {
+			if apply(s, "oneOf/"+strconv.Itoa(i), false) {
+				matched = append(matched, i)
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			fail("oneOf", "value does not match any schema in oneOf")
+		case len(matched) > 1:
+			fail("oneOf", "value matches more than one schema in oneOf (indexes %v)", matched)
+		}
+	}
+	if n.not != nil {
+		subErrs, _ := v.validate(n.not, inst, instLoc, kwLoc+"/not", scope)
+		if len(subErrs) == 0 {
+			fail("not", "value must not match the schema in not")
+		}
+	}
+	if n.ifSchema != nil {
+		if apply(n.ifSchema, "if", false) {
+			if n.thenSchema != nil {
+				apply(n.thenSchema, "then", true)
+			}
+		} else if n.elseSchema != nil {
+			apply(n.elseSchema, "else", true)
+		}
+	}
+
+	// unevaluated* 需要在所有其他关键字求值之后处理
+	if obj, ok := inst.(map[string]interface{}); ok && n.unevaluatedProperties != nil {
+		for _, k := range sortedKeys(obj) {
+			if ev.props[k] {
+				continue
+			}
+			subErrs, _ := v.validate(n.unevaluatedProperties, obj[k], appendToken(instLoc, k), kwLoc+"/unevaluatedProperties", scope)
+			errs = append(errs, subErrs...)
+			ev.props[k] = true
+		}
+	}
+	if arr, ok := inst.([]interface{}); ok && n.unevaluatedItems != nil {
+		for i, item := range arr {
+			if ev.items[i] {
+				continue
+			}
+			subErrs, _ := v.validate(n.unevaluatedItems, item, appendToken(instLoc, strconv.Itoa(i)), kwLoc+"/unevaluatedItems", scope)
+			errs = append(errs, subErrs...)
+			ev.items[i] = true
+		}
+	}
+	return errs, ev
+}
+
+// appendToken 返回追加了引用段的新路径
+func appendToken(loc []string, token string) []string {
+	result := make([]string, len(loc)+1)
+	copy(result, loc)
+	result[len(loc)] = token
+	return result
+}
+
+// validateString 校验字符串相关关键字
+func (v *schemaValidator) validateString(n *schemaNode, s string, fail func(string, string, ...interface{})) {
+	length := utf8.RuneCountInString(s)
+	if n.minLength != nil && length < *n.minLength {
+		fail("minLength", "length must be >= %d, got %d", *n.minLength, length)
+	}
+	if n.maxLength != nil && length > *n.maxLength {
+		fail("maxLength", "length must be <= %d, got %d", *n.maxLength, length)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		fail("pattern", "value does not match pattern %q", n.pattern.String())
+	}
+	if n.format != "" && v.formatAssertion {
+		if check, ok := schemaFormats[n.format]; ok && !check(s) {
+			fail("format", "value is not a valid %s", n.format)
+		}
+	}
+}
+
+// validateNumber 校验数值相关关键字
+func validateNumber(n *schemaNode, x interface{}, fail func(string, string, ...interface{})) {
+	cmp := func(a, b interface{}) int {
+		c, _ := compareNumbers(a, b)
+		return c
+	}
+	if n.minimum != nil && cmp(x, n.minimum) < 0 {
+		fail("minimum", "value must be >= %v", n.minimum)
+	}
+	if n.maximum != nil && cmp(x, n.maximum) > 0 {
+		fail("maximum", "value must be <= %v", n.maximum)
+	}
+	if n.exclusiveMinimum != nil && cmp(x, n.exclusiveMinimum) <= 0 {
+		fail("exclusiveMinimum", "value must be > %v", n.exclusiveMinimum)
+	}
+	if n.exclusiveMaximum != nil && cmp(x, n.exclusiveMaximum) >= 0 {
+		fail("exclusiveMaximum", "value must be < %v", n.exclusiveMaximum)
+	}
+	if n.multipleOf != nil && !isMultipleOf(x, n.multipleOf) {
+		fail("multipleOf", "value must be a multiple of %v", n.multipleOf)
+	}
+}
+
+// numberRat 将 JSON 数值转换为精确的有理数
+func numberRat(v interface{}) (*big.Rat, bool) {
+	var s string
+	switch n := v.(type) {
+	case interface{ String() string }:
+		s = n.String()
+	default:
+		f, ok := toFloat64(v)
+		if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, false
+		}
+		s = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// isMultipleOf 判断 x 是否为 m 的整数倍
+func isMultipleOf(x, m interface{}) bool {
+	rx, ok1 := numberRat(x)
+	rm, ok2 := numberRat(m)
+	if !ok1 || !ok2 || rm.Sign() == 0 {
+		return true
+	}
+	return new(big.Rat).Quo(rx, rm).IsInt()
+}
+
+// validateObject 校验对象相关关键字
+func (v *schemaValidator) validateObject(n *schemaNode, obj map[string]interface{}, instLoc []string, kwLoc string, scope []*schemaNode, ev *evaluated, fail func(string, string, ...interface{})) []SchemaError {
+	var errs []SchemaError
+	child := func(s *schemaNode, key, keyword string) bool {
+		subErrs, _ := v.validate(s, obj[key], appendToken(instLoc, key), kwLoc+"/"+keyword, scope)
+		errs = append(errs, subErrs...)
+		return len(subErrs) == 0
+	}
+	if n.minProperties != nil && len(obj) < *n.minProperties {
+		fail("minProperties", "object must have >= %d properties, got %d", *n.minProperties, len(obj))
+	}
+	if n.maxProperties != nil && len(obj) > *n.maxProperties {
+		fail("maxProperties", "object must have <= %d properties, got %d", *n.maxProperties, len(obj))
+	}
+	for _, r := range n.required {
+		if _, ok := obj[r]; !ok {
+			fail("required", "missing required property %q", r)
+		}
+	}
+	for _, k := range sortedKeys(obj) {
+		if deps, ok := n.dependentRequired[k]; ok {
+			for _, d := range deps {
+				if _, ok := obj[d]; !ok {
+					fail(n.depRequiredKeyword, "property %q is required when %q is present", d, k)
+				}
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(obj) {
+		matched := false
+		if s, ok := n.properties[k]; ok {
+			matched = true
+			child(s, k, "properties/"+escapePointerToken(k))
+			ev.props[k] = true
+		}
+		for _, pp := range n.patternProperties {
+			if pp.re.MatchString(k) {
+				matched = true
+				child(pp.schema, k, "patternProperties/"+escapePointerToken(pp.source))
+				ev.props[k] = true
+			}
+		}
+		if !matched && n.additionalProperties != nil {
+			child(n.additionalProperties, k, "additionalProperties")
+			ev.props[k] = true
+		}
+		if n.propertyNames != nil {
+			subErrs, _ := v.validate(n.propertyNames, k, appendToken(instLoc, k), kwLoc+"/propertyNames", scope)
+			errs = append(errs, subErrs...)
+		}
+	}
+	for _, k := range sortedKeys(obj) {
+		if s, ok := n.dependentSchemas[k]; ok {
+			subErrs, subEv := v.validate(s, obj, instLoc, kwLoc+"/"+n.depSchemasKeyword+"/"+escapePointerToken(k), scope)
+			errs = append(errs, subErrs...)
+			if len(subErrs) == 0 {
+				ev.merge(subEv)
+			}
+		}
+	}
+	return errs
+}
+
+// validateArray 校验数组相关关键字
+func (v *schemaValidator) validateArray(n *schemaNode, arr []interface{}, instLoc []string, kwLoc string, scope []*schemaNode, ev *evaluated, fail func(string, string, ...interface{})) []SchemaError {
+	var errs []SchemaError
+	if n.minItems != nil && len(arr) < *n.minItems {
+		fail("minItems", "array must have >= %d items, got %d", *n.minItems, len(arr))
+	}
+	if n.maxItems != nil && len(arr) > *n.maxItems {
+		fail("maxItems", "array must have <= %d items, got %d", *n.maxItems, len(arr))
+	}
+	if n.uniqueItems {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if valuesEqual(arr[i], arr[j]) {
+					fail("uniqueItems", "items at index %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	prefixKeyword, itemsKeyword := "prefixItems", "items"
+	if n.draft == Draft7 {
+		prefixKeyword, itemsKeyword = "items", "additionalItems"
+		if len(n.prefixItems) == 0 {
+			itemsKeyword = "items"
+		}
+	}
+	for i, item := range arr {
+		var s *schemaNode
+		keyword := ""
+		if i < len(n.prefixItems) {
+			s, keyword = n.prefixItems[i], prefixKeyword+"/"+strconv.Itoa(i)
+		} else if n.items != nil {
+			s, keyword = n.items, itemsKeyword
+		}
+		if s == nil {
+			continue
+		}
+		subErrs, _ := v.validate(s, item, appendToken(instLoc, strconv.Itoa(i)), kwLoc+"/"+keyword, scope)
+		errs = append(errs, subErrs...)
+		ev.items[i] = true
+	}
+
+	if n.contains != nil {
+		count := 0
+		for i, item := range arr {
+			subErrs, _ := v.validate(n.contains, item, appendToken(instLoc, strconv.Itoa(i)), kwLoc+"/contains", scope)
+			if len(subErrs) == 0 {
+				count++
+				ev.items[i] = true
+			}
+		}
+		minContains := 1
+		if n.minContains != nil {
+			minContains = *n.minContains
+		}
+		if count < minContains {
+			if n.minContains != nil {
+				fail("minContains", "array must contain >= %d matching items, got %d", minContains, count)
+			} else {
+				fail("contains", "array does not contain a matching item")
+			}
+		}
+		if n.maxContains != nil && count > *n.maxContains {
+			fail("maxContains", "array must contain <= %d matching items, got %d", *n.maxContains, count)
+		}
+	}
+	return errs
+}
+
+// schemaTypeMatches 判断实例是否属于 JSON Schema 类型
+func schemaTypeMatches(t string, inst interface{}) bool {
+	switch t {
+	case "null":
+		return inst == nil
+	case "boolean":
+		_, ok := inst.(bool)
+		return ok
+	case "string":
+		_, ok := inst.(string)
+		return ok
+	case "object":
+		_, ok := inst.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := inst.([]interface{})
+		return ok
+	case "number":
+		return isNumber(inst)
+	case "integer":
+		r, ok := numberRat(inst)
+		return ok && r.IsInt()
+	}
+	return false
+}
+
+// schemaTypeOf 返回实例的 JSON Schema 类型名
+func schemaTypeOf(inst interface{}) string {
+	for _, t := range []string{"null", "boolean", "string", "object", "array", "integer", "number"} {
+		if schemaTypeMatches(t, inst) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", inst)
+}
+
+// compactJSON 返回值的紧凑 JSON 表示，用于错误信息
+func compactJSON(v interface{}) string {
+	s, err := ToJSON(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return s
+}
+
+var (
+	emailPattern    = regexp.MustCompile(`^[^\s@]+@[^\s@]+$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+	uuidPattern     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	durationPattern = regexp.MustCompile(`^P(?:(?:\d+Y(?:\d+M(?:\d+D)?)?|\d+M(?:\d+D)?|\d+D)(?:T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S))?|T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S)|\d+W)$`)
+	datePattern     = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	timePattern     = regexp.MustCompile(`^(?i)(\d{2}):(\d{2}):(\d{2})(\.\d+)?(z|[+-]\d{2}:\d{2})$`)
+	relPtrPattern   = regexp.MustCompile(`^(0|[1-9][0-9]*)(#|(/.*)?)$`)
+)
+
+// schemaFormats 定义了 format 关键字支持的格式
+var schemaFormats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		i := strings.IndexAny(s, "Tt")
+		return i > 0 && isValidDate(s[:i]) && isValidTime(s[i+1:])
+	},
+	"date":     isValidDate,
+	"time":     isValidTime,
+	"duration": durationPattern.MatchString,
+	"email":    emailPattern.MatchString,
+	"idn-email": func(s string) bool {
+		return emailPattern.MatchString(s)
+	},
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	},
+	"ipv4": isValidIPv4,
+	"ipv6": func(s string) bool {
+		return strings.Contains(s, ":") && !strings.Contains(s, "%") && net.ParseIP(s) != nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs() && !strings.ContainsAny(s, " \\<>\"{}|^`")
+	},
+	"uri-reference": func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil && !strings.ContainsAny(s, " \\<>\"{}|^`")
+	},
+	"iri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"iri-reference": func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+	"regex": func(s string) bool {
+		_, err := regexp.Compile(s)
+		return err == nil
+	},
+	"json-pointer": func(s string) bool {
+		if s != "" && !strings.HasPrefix(s, "/") {
+			return false
+		}
+		_, err := ParseJSONPointer(s)
+		return err == nil
+	},
+	"relative-json-pointer": func(s string) bool {
+		m := relPtrPattern.FindStringSubmatch(s)
+		if m == nil {
+			return false
+		}
+		if m[3] != "" {
+			_, err := ParseJSONPointer(m[3])
+			return err == nil
+		}
+		return true
+	},
+}
+
+// isValidDate 校验 RFC 3339 full-date
+func isValidDate(s string) bool {
+	m := datePattern.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	days := []int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}[month-1]
+	if month == 2 && year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		days = 29
+	}
+	return day <= days
+}
+
+// isValidTime 校验 RFC 3339 full-time，允许闰秒
+func isValidTime(s string) bool {
+	m := timePattern.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	second, _ := strconv.Atoi(m[3])
+	if hour > 23 || minute > 59 || second > 60 {
+		return false
+	}
+	offset := strings.ToLower(m[5])
+	if offset != "z" {
+		oh, _ := strconv.Atoi(offset[1:3])
+		om, _ := strconv.Atoi(offset[4:6])
+		if oh > 23 || om > 59 {
+			return false
+		}
+	}
+	if second == 60 {
+		// 闰秒只能出现在 UTC 23:59:60
+		utc := hour*60 + minute
+		if offset != "z" {
+			oh, _ := strconv.Atoi(offset[1:3])
+			om, _ := strconv.Atoi(offset[4:6])
+			delta := oh*60 + om
+			if offset[0] == '+' {
+				utc -= delta
+			} else {
+				utc += delta
+			}
+			utc = (utc%1440 + 1440) % 1440
+		}
+		return utc == 23*60+59
+	}
+	return true
+}
+
+// isValidIPv4 校验点分十进制 IPv4 地址，不允许前导零
+func isValidIPv4(s string) bool {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return false
+	}
+	for _, p := range parts {
+		if p == "" || len(p) > 3 || (len(p) > 1 && p[0] == '0') {
+			return false
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n > 255 || strings.TrimLeft(p, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
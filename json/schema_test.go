@@ -0,0 +1,127 @@
+package jsonutil
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchemaValidateJSON(t *testing.T) {
+	const schema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {"tag": {"type": "string", "minLength": 1}},
+  "type": "object",
+  "required": ["id", "name"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "name": {"type": "string", "pattern": "^[a-z]+$"},
+    "email": {"type": "string", "format": "email"},
+    "price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+    "tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true},
+    "kind": {"enum": ["a", "b"]}
+  },
+  "additionalProperties": false
+}`
+	tests := []struct {
+		name     string
+		instance string
+		wantLocs []string
+	}{
+		{name: "valid", instance: `{"id":1,"name":"abc","email":"a@b.c","price":9.99,"tags":["x","y"],"kind":"a"}`},
+		{name: "missing required", instance: `{"id":1}`, wantLocs: []string{""}},
+		{name: "wrong type", instance: `{"id":"1","name":"abc"}`, wantLocs: []string{"/id"}},
+		{name: "not integer", instance: `{"id":1.5,"name":"abc"}`, wantLocs: []string{"/id"}},
+		{name: "pattern", instance: `{"id":1,"name":"ABC"}`, wantLocs: []string{"/name"}},
+		{name: "format", instance: `{"id":1,"name":"abc","email":"nope"}`, wantLocs: []string{"/email"}},
+		{name: "multipleOf", instance: `{"id":1,"name":"abc","price":0.001}`, wantLocs: []string{"/price"}},
+		{name: "ref", instance: `{"id":1,"name":"abc","tags":["x",""]}`, wantLocs: []string{"/tags/1"}},
+		{name: "uniqueItems", instance: `{"id":1,"name":"abc","tags":["x","x"]}`, wantLocs: []string{"/tags"}},
+		{name: "enum", instance: `{"id":1,"name":"abc","kind":"c"}`, wantLocs: []string{"/kind"}},
+		{name: "additionalProperties", instance: `{"id":1,"name":"abc","extra":1}`, wantLocs: []string{"/extra"}},
+		{name: "multiple errors", instance: `{"id":0,"name":"A"}`, wantLocs: []string{"/id", "/name"}},
+	}
+	s, err := CompileSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateJSON(tt.instance)
+			if len(tt.wantLocs) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateJSON() error = %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateJSON() error = %v, want *ValidationError", err)
+			}
+			locs := map[string]bool{}
+			for _, e := range verr.Errors {
+				locs[e.InstanceLocation] = true
+			}
+			for _, loc := range tt.wantLocs {
+				if !locs[loc] {
+					t.Errorf("missing error at %q in %v", loc, verr)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaDraft7(t *testing.T) {
+	s, err := CompileSchema(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "definitions": {"pos": {"type": "integer", "minimum": 0}},
+  "type": "array",
+  "items": [{"type": "string"}],
+  "additionalItems": {"$ref": "#/definitions/pos"}
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateJSON(`["a",1,2]`); err != nil {
+		t.Errorf("ValidateJSON() error = %v", err)
+	}
+	if err := s.ValidateJSON(`["a",1,-2]`); err == nil {
+		t.Error("ValidateJSON() error = nil, want error for additionalItems")
+	}
+}
+
+func TestSchemaValidateOrderedValues(t *testing.T) {
+	s, err := CompileSchema(`{
+  "type": "object",
+  "required": ["items"],
+  "properties": {
+    "items": {"type": "array", "items": {"type": "object", "required": ["id"]}},
+    "meta": {"type": "object"}
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := ParseJSONObject(`{"items":[{"id":1}],"meta":{}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj.GetObject("meta").Set("list", JSONArray{1, 2})
+	if err := s.Validate(obj); err != nil {
+		t.Errorf("Validate(*JSONObject) error = %v", err)
+	}
+	items := &JSONArray{NewJSONObject().Set("id", 2)}
+	if err := s.Validate(map[string]interface{}{"items": items}); err != nil {
+		t.Errorf("Validate(*JSONArray child) error = %v", err)
+	}
+	obj.Remove("items")
+	if err := s.Validate(obj); err == nil {
+		t.Error("Validate() error = nil, want missing items")
+	}
+
+	arrSchema, err := CompileSchema(`{"type":"array","minItems":2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !arrSchema.IsValid(JSONArray{1, 2}) || !arrSchema.IsValid(&JSONArray{1, 2}) {
+		t.Error("IsValid(JSONArray) = false, want true")
+	}
+}
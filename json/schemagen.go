@@ -0,0 +1,756 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema 表示生成的 JSON Schema 文档或子模式
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Examples             []interface{}          `json:"examples,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64               `json:"multipleOf,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"-"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+
+	propertyOrder []string
+}
+
+// SetProperty 添加属性，属性按添加顺序输出
+func (s *JSONSchema) SetProperty(name string, schema *JSONSchema) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]*JSONSchema)
+	}
+	if _, ok := s.Properties[name]; !ok {
+		s.propertyOrder = append(s.propertyOrder, name)
+	}
+	s.Properties[name] = schema
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，properties 按字段声明顺序输出
+func (s *JSONSchema) MarshalJSON() ([]byte, error) {
+	type plain JSONSchema
+	data, err := json.Marshal((*plain)(s))
+	if err != nil || len(s.Properties) == 0 {
+		return data, err
+	}
+	order := append([]string(nil), s.propertyOrder...)
+	for _, k := range sortedSchemaKeys(s.Properties) {
+		if indexOfString(order, k) < 0 {
+			order = append(order, k)
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	if len(data) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"properties":{`)
+	for i, k := range order {
+		prop, ok := s.Properties[k]
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(prop)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// ToJSON 将模式编码为格式化的 JSON 字符串
+func (s *JSONSchema) ToJSON() (string, error) {
+	return PrettyPrint(s)
+}
+
+// Compile 将生成的模式编译为可用于校验的 Schema
+func (s *JSONSchema) Compile(opts ...SchemaOption) (*Schema, error) {
+	data, err := Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return CompileSchema(string(data), opts...)
+}
+
+// sortedSchemaKeys 返回按字典序排列的属性名
+func sortedSchemaKeys(m map[string]*JSONSchema) []string {
+	keys := make(map[string]interface{}, len(m))
+	for k := range m {
+		keys[k] = nil
+	}
+	return sortedKeys(keys)
+}
+
+// indexOfString 返回 s 在切片中的下标
+func indexOfString(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// JSONSchemaProvider 由需要自定义模式的类型实现
+type JSONSchemaProvider interface {
+	JSONSchema() *JSONSchema
+}
+
+// schemaGenOptions 保存模式生成的选项
+type schemaGenOptions struct {
+	draft              int
+	id                 string
+	noAdditionalFields bool
+}
+
+// SchemaGenOption 是设置模式生成选项的函数类型
+type SchemaGenOption func(*schemaGenOptions)
+
+// WithGeneratedDraft 设置生成模式的草案版本，默认为 Draft202012
+func WithGeneratedDraft(draft int) SchemaGenOption {
+	return func(o *schemaGenOptions) {
+		o.draft = draft
+	}
+}
+
+// WithGeneratedID 设置生成模式的 $id
+func WithGeneratedID(id string) SchemaGenOption {
+	return func(o *schemaGenOptions) {
+		o.id = id
+	}
+}
+
+// WithNoAdditionalProperties 为所有结构体生成 additionalProperties: false
+func WithNoAdditionalProperties() SchemaGenOption {
+	return func(o *schemaGenOptions) {
+		o.noAdditionalFields = true
+	}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	decimalType       = reflect.TypeOf(Decimal{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	schemaProvider    = reflect.TypeOf((*JSONSchemaProvider)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GenerateSchema 根据 Go 值的类型生成 JSON Schema
+// 支持 json 标签（包括 omitempty 和 string 选项）、嵌入结构体、指针、映射、切片和 time.Time；
+// 字段可通过 description 标签添加描述，通过 jsonschema 标签设置约束，如
+// `jsonschema:"minimum=1,maximum=10,enum=a|b,pattern=^[a-z]+$,format=email,required"`，值中的逗号写作 \,
+// 被多处引用或递归引用的具名结构体放在 $defs 中；指针、切片和映射的 nil 值编码为 null，其模式为 anyOf 形式并同时接受 null
+func GenerateSchema(v interface{}, opts ...SchemaGenOption) (*JSONSchema, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil")
+	}
+	o := schemaGenOptions{draft: Draft202012}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g := &schemaGenerator{
+		opts:      o,
+		refs:      make(map[reflect.Type]int),
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+		defs:      make(map[string]*JSONSchema),
+	}
+	g.count(t, nil)
+
+	root, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+	if root.Ref != "" {
+		root = &JSONSchema{Ref: root.Ref}
+	}
+	if o.draft == Draft7 {
+		root.Schema = Draft7URI
+		if len(g.defs) > 0 {
+			root.Definitions = g.defs
+		}
+	} else {
+		root.Schema = Draft202012URI
+		if len(g.defs) > 0 {
+			root.Defs = g.defs
+		}
+	}
+	root.ID = o.id
+	return root, nil
+}
+
+// GenerateSchemaFor 根据类型参数生成 JSON Schema
+func GenerateSchemaFor[T any](opts ...SchemaGenOption) (*JSONSchema, error) {
+	return GenerateSchema(reflect.TypeOf((*T)(nil)).Elem(), opts...)
+}
+
+// GenerateSchemaJSON 生成 JSON Schema 并编码为格式化的 JSON 字符串
+func GenerateSchemaJSON(v interface{}, opts ...SchemaGenOption) (string, error) {
+	s, err := GenerateSchema(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	return s.ToJSON()
+}
+
+// schemaGenerator 保存模式生成过程中的状态
+type schemaGenerator struct {
+	opts      schemaGenOptions
+	refs      map[reflect.Type]int
+	recursive map[reflect.Type]bool
+	names     map[reflect.Type]string
+	defs      map[string]*JSONSchema
+}
+
+// derefType 去掉指针
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isDefCandidate 判断类型是否可以放入 $defs
+func isDefCandidate(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Name() != "" && t != timeType && !t.Implements(schemaProvider) &&
+		!reflect.PtrTo(t).Implements(schemaProvider)
+}
+
+// count 统计具名结构体被引用的次数，并找出递归类型
+func (g *schemaGenerator) count(t reflect.Type, stack []reflect.Type) {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		g.count(t.Elem(), stack)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if isDefCandidate(t) {
+		for _, s := range stack {
+			if s == t {
+				g.recursive[t] = true
+				return
+			}
+		}
+		g.refs[t]++
+		if g.refs[t] > 1 {
+			return
+		}
+	}
+	stack = append(stack, t)
+	for _, f := range structFields(t) {
+		g.count(f.typ, stack)
+	}
+}
+
+// defName 返回类型在 $defs 中的名称
+func (g *schemaGenerator) defName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := sanitizeDefName(t.Name())
+	for other, n := range g.names {
+		if n == name && other != t {
+			pkg := t.PkgPath()
+			if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+				pkg = pkg[i+1:]
+			}
+			name = sanitizeDefName(pkg + "_" + t.Name())
+			break
+		}
+	}
+	g.names[t] = name
+	return name
+}
+
+// sanitizeDefName 将类型名中不适合作为 JSON Pointer 段的字符替换为下划线
+func sanitizeDefName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// generate 生成类型对应的模式
+func (g *schemaGenerator) generate(t reflect.Type) (*JSONSchema, error) {
+	t = derefType(t)
+	if t.Implements(schemaProvider) {
+		return reflect.Zero(t).Interface().(JSONSchemaProvider).JSONSchema(), nil
+	}
+	if reflect.PtrTo(t).Implements(schemaProvider) {
+		return reflect.New(t).Interface().(JSONSchemaProvider).JSONSchema(), nil
+	}
+	switch t {
+	case timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}, nil
+	case jsonNumberType, decimalType:
+		return &JSONSchema{Type: "number"}, nil
+	case rawMessageType:
+		return &JSONSchema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &JSONSchema{Type: "string", ContentEncoding: "base64"}, nil
+		}
+		items, err := g.generateNullable(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s := &JSONSchema{Type: "array", Items: items}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+		return s, nil
+	case reflect.Map:
+		k := t.Key()
+		if k.Kind() != reflect.String && !k.Implements(textMarshalerType) && !isIntegerKind(k.Kind()) {
+			return nil, fmt.Errorf("unsupported map key type %s", k)
+		}
+		values, err := g.generateNullable(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+			return &JSONSchema{Type: "string"}, nil
+		}
+		if isDefCandidate(t) && (g.refs[t] > 1 || g.recursive[t]) {
+			return g.reference(t)
+		}
+		return g.generateStruct(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// generateNullable 生成类型对应的模式，可能为 nil 的类型同时接受 null，见 isNullableType
+func (g *schemaGenerator) generateNullable(t reflect.Type) (*JSONSchema, error) {
+	s, err := g.generate(t)
+	if err != nil || !isNullableType(t) {
+		return s, err
+	}
+	return nullableSchema(s), nil
+}
+
+// isNullableType 判断类型的 nil 值是否会被 Marshal 编码为 null，即指针、切片和 map
+func isNullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// nullableSchema 返回同时接受 null 的模式，不限制类型的空模式原样返回
+func nullableSchema(s *JSONSchema) *JSONSchema {
+	if reflect.DeepEqual(s, &JSONSchema{}) {
+		return s
+	}
+	return &JSONSchema{AnyOf: []*JSONSchema{s, {Type: "null"}}}
+}
+
+// isIntegerKind 判断是否为整数类型
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// reference 将类型放入 $defs 并返回引用
+func (g *schemaGenerator) reference(t reflect.Type) (*JSONSchema, error) {
+	name := g.defName(t)
+	prefix := "#/$defs/"
+	if g.opts.draft == Draft7 {
+		prefix = "#/definitions/"
+	}
+	ref := &JSONSchema{Ref: prefix + escapePointerToken(name)}
+	if _, ok := g.defs[name]; ok {
+		return ref, nil
+	}
+	g.defs[name] = &JSONSchema{} // 占位，处理递归引用
+	s, err := g.generateStruct(t)
+	if err != nil {
+		return nil, err
+	}
+	g.defs[name] = s
+	return ref, nil
+}
+
+// generateStruct 生成结构体的模式
+func (g *schemaGenerator) generateStruct(t reflect.Type) (*JSONSchema, error) {
+	s := &JSONSchema{Type: "object"}
+	if g.opts.noAdditionalFields {
+		s.AdditionalProperties = false
+	}
+	for _, f := range structFields(t) {
+		prop, err := g.generate(f.typ)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.goName, err)
+		}
+		if f.asString {
+			switch prop.Type {
+			case "integer", "number", "boolean":
+				prop = &JSONSchema{Type: "string"}
+			}
+		}
+		required := !f.omitEmpty
+		if f.field.Tag.Get("description") != "" || f.field.Tag.Get("jsonschema") != "" {
+			if prop.Ref != "" {
+				// $ref 的同级关键字需要独立的模式对象
+				prop = &JSONSchema{Ref: prop.Ref}
+			}
+			prop.Description = f.field.Tag.Get("description")
+			if required, err = applySchemaTag(prop, f.field.Tag.Get("jsonschema"), required); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.goName, err)
+			}
+		}
+		if isNullableType(f.typ) {
+			prop = nullableSchema(prop)
+		}
+		s.SetProperty(f.name, prop)
+		if required {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s, nil
+}
+
+// splitSchemaTag 按未转义的逗号拆分 jsonschema 标签
+func splitSchemaTag(tag string) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(tag[i])
+		}
+	}
+	return append(parts, cur.String())
+}
+
+// applySchemaTag 将 jsonschema 标签中的约束应用到模式上，返回字段是否必需
+func applySchemaTag(s *JSONSchema, tag string, required bool) (bool, error) {
+	if tag == "" {
+		return required, nil
+	}
+	// 数组的元素约束作用于 items
+	target := s
+	for _, part := range splitSchemaTag(tag) {
+		key, value, _ := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		parseFloat := func() (*float64, error) {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			return &f, nil
+		}
+		parseInt := func() (*int, error) {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			return &n, nil
+		}
+		var err error
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "title":
+			target.Title = value
+		case "description":
+			target.Description = value
+		case "format":
+			target.Format = value
+		case "pattern":
+			target.Pattern = value
+		case "enum":
+			for _, item := range strings.Split(value, "|") {
+				target.Enum = append(target.Enum, typedTagValue(target.Type, item))
+			}
+		case "default":
+			target.Default = typedTagValue(target.Type, value)
+		case "example":
+			target.Examples = append(target.Examples, typedTagValue(target.Type, value))
+		case "minimum":
+			target.Minimum, err = parseFloat()
+		case "maximum":
+			target.Maximum, err = parseFloat()
+		case "exclusiveMinimum":
+			target.ExclusiveMinimum, err = parseFloat()
+		case "exclusiveMaximum":
+			target.ExclusiveMaximum, err = parseFloat()
+		case "multipleOf":
+			target.MultipleOf, err = parseFloat()
+		case "minLength":
+			target.MinLength, err = parseInt()
+		case "maxLength":
+			target.MaxLength, err = parseInt()
+		case "minItems":
+			s.MinItems, err = parseInt()
+		case "maxItems":
+			s.MaxItems, err = parseInt()
+		case "uniqueItems":
+			s.UniqueItems = true
+		case "deprecated":
+			s.Deprecated = true
+		case "readOnly":
+			s.ReadOnly = true
+		case "writeOnly":
+			s.WriteOnly = true
+		case "items":
+			// items 之后的约束作用于数组元素
+			if s.Items == nil {
+				return required, fmt.Errorf("items used on non-array field")
+			}
+			target = s.Items
+			if len(target.AnyOf) == 2 && target.AnyOf[1].Type == "null" {
+				// 指针元素的约束作用于非 null 的分支
+				target = target.AnyOf[0]
+			}
+		default:
+			return required, fmt.Errorf("unknown jsonschema tag option %q", key)
+		}
+		if err != nil {
+			return required, err
+		}
+	}
+	return required, nil
+}
+
+// typedTagValue 根据模式类型转换标签中的值
+func typedTagValue(schemaType, value string) interface{} {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// fieldInfo 描述按 encoding/json 规则展开后的结构体字段
+type fieldInfo struct {
+	name      string
+	goName    string
+	index     []int
+	typ       reflect.Type
+	field     reflect.StructField
+	omitEmpty bool
+	asString  bool
+	tagged    bool
+}
+
+// structFields 按 encoding/json 的规则列出结构体的 JSON 字段，包括嵌入结构体提升的字段
+func structFields(t reflect.Type) []fieldInfo {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+	current := []queued{}
+	next := []queued{{typ: t}}
+	visited := map[reflect.Type]bool{}
+	var fields []fieldInfo
+	depthOf := map[string]int{}
+	depth := 0
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		levelCount := map[string]int{}
+		var levelFields []fieldInfo
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := make([]int, len(q.index)+1)
+				copy(index, q.index)
+				index[len(q.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, queued{typ: ft, index: index})
+					continue
+				}
+				info := fieldInfo{
+					name:      name,
+					goName:    sf.Name,
+					index:     index,
+					typ:       sf.Type,
+					field:     sf,
+					omitEmpty: hasTagOption(opts, "omitempty"),
+					tagged:    name != "",
+				}
+				if info.name == "" {
+					info.name = sf.Name
+				}
+				if hasTagOption(opts, "string") {
+					switch derefType(sf.Type).Kind() {
+					case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+						info.asString = true
+					}
+				}
+				levelCount[info.name]++
+				levelFields = append(levelFields, info)
+			}
+		}
+		for _, f := range levelFields {
+			if _, seen := depthOf[f.name]; seen {
+				continue // 较浅层级的字段优先
+			}
+			if levelCount[f.name] > 1 {
+				// 同一层级的同名字段：只有唯一带标签的字段生效
+				tagged := 0
+				for _, other := range levelFields {
+					if other.name == f.name && other.tagged {
+						tagged++
+					}
+				}
+				if tagged != 1 || !f.tagged {
+					continue
+				}
+			}
+			fields = append(fields, f)
+		}
+		for name := range levelCount {
+			if _, seen := depthOf[name]; !seen {
+				depthOf[name] = depth
+			}
+		}
+		depth++
+	}
+	// 与 encoding/json 一致，按字段在结构体中的声明顺序排列
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// hasTagOption 判断标签选项中是否包含指定项
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var cur string
+		cur, opts, _ = strings.Cut(opts, ",")
+		if cur == option {
+			return true
+		}
+	}
+	return false
+}
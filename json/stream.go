@@ -0,0 +1,273 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStopStreaming 由回调返回以提前结束流式解析，此时 Decode 返回 nil
+var ErrStopStreaming = errors.New("stop streaming")
+
+// StreamDecoder 是可感知路径的流式解码器
+// 解析过程中记录当前所在的 JSON 路径，并将与注册路径匹配的子值解码后交给回调，
+// 内存占用只与单个匹配值的大小和嵌套深度有关，适合处理超大文件
+type StreamDecoder struct {
+	dec      *json.Decoder
+	handlers []streamHandler
+	keys     []interface{}
+
+	useNumber       bool
+	disallowUnknown bool
+}
+
+// streamHandler 是一个已注册的路径回调
+type streamHandler struct {
+	path *JSONPath
+	fn   func(decode func(v interface{}) error) error
+}
+
+// NewStreamDecoder 创建流式解码器
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{dec: json.NewDecoder(r)}
+}
+
+// UseNumber 将匹配值中的数字解码为 json.Number 而不是 float64
+func (d *StreamDecoder) UseNumber() {
+	d.dec.UseNumber()
+	d.useNumber = true
+}
+
+// DisallowUnknownFields 解码到结构体时拒绝未知字段
+func (d *StreamDecoder) DisallowUnknownFields() {
+	d.dec.DisallowUnknownFields()
+	d.disallowUnknown = true
+}
+
+// Location 返回当前值的规范化路径，如 $['items'][3]，在回调中调用时即为匹配值的位置
+func (d *StreamDecoder) Location() string {
+	return normalizedPath(d.keys)
+}
+
+// Handle 注册路径回调，回调通过 decode 函数将匹配的值解码到任意目标
+// 路径支持名称、通配符、非负下标、步长为正的切片和后代段（..），不支持过滤器和负下标；
+// 匹配的值会被整体消费，其内部不再触发其他回调
+func (d *StreamDecoder) Handle(path string, fn func(decode func(v interface{}) error) error) error {
+	p, err := CompileJSONPath(path)
+	if err != nil {
+		return err
+	}
+	for _, seg := range p.segments {
+		for _, sel := range seg.selectors {
+			if err := checkStreamSelector(sel); err != nil {
+				return &JSONPathError{Expr: path, Msg: err.Error()}
+			}
+		}
+	}
+	d.handlers = append(d.handlers, streamHandler{path: p, fn: fn})
+	return nil
+}
+
+// OnPath 注册路径回调，匹配的值被解码为 T 类型后传给回调
+func OnPath[T any](d *StreamDecoder, path string, fn func(value T) error) error {
+	return d.Handle(path, func(decode func(v interface{}) error) error {
+		var value T
+		if err := decode(&value); err != nil {
+			return err
+		}
+		return fn(value)
+	})
+}
+
+// StreamPath 流式读取 r，将与路径匹配的每个值解码为 T 类型后传给回调
+func StreamPath[T any](r io.Reader, path string, fn func(value T) error) error {
+	d := NewStreamDecoder(r)
+	if err := OnPath(d, path, fn); err != nil {
+		return err
+	}
+	return d.Decode()
+}
+
+// Decode 读取并处理输入中的全部 JSON 值（支持多个顶层值首尾相接）
+// 回调返回 ErrStopStreaming 时立即停止并返回 nil，返回其他错误时停止并原样返回该错误；输入为空时返回 io.EOF
+func (d *StreamDecoder) Decode() error {
+	for first := true; first || d.dec.More(); first = false {
+		if err := d.value(); err != nil {
+			if errors.Is(err, ErrStopStreaming) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStreamSelector 检查选择器能否在不知道数组长度的情况下匹配
+func checkStreamSelector(sel pathSelector) error {
+	switch s := sel.(type) {
+	case nameSelector, wildcardSelector:
+		return nil
+	case indexSelector:
+		if s.index < 0 {
+			return fmt.Errorf("negative index is not supported in streaming mode")
+		}
+		return nil
+	case sliceSelector:
+		if s.step <= 0 || (s.start != nil && *s.start < 0) || (s.end != nil && *s.end < 0) {
+			return fmt.Errorf("slice with negative bounds or step is not supported in streaming mode")
+		}
+		return nil
+	}
+	return fmt.Errorf("filter selector is not supported in streaming mode")
+}
+
+// streamSelectorMatches 判断选择器是否选中指定的键
+func streamSelectorMatches(sel pathSelector, key interface{}) bool {
+	switch s := sel.(type) {
+	case nameSelector:
+		name, ok := key.(string)
+		return ok && name == s.name
+	case wildcardSelector:
+		return true
+	case indexSelector:
+		i, ok := key.(int)
+		return ok && i == s.index
+	case sliceSelector:
+		i, ok := key.(int)
+		if !ok {
+			return false
+		}
+		start := 0
+		if s.start != nil {
+			start = *s.start
+		}
+		if i < start || (s.end != nil && i >= *s.end) {
+			return false
+		}
+		return (i-start)%s.step == 0
+	}
+	return false
+}
+
+// streamPathMatches 判断键序列是否与段序列匹配
+func streamPathMatches(segments []pathSegment, keys []interface{}) bool {
+	if len(segments) == 0 {
+		return len(keys) == 0
+	}
+	seg := segments[0]
+	selected := func(key interface{}) bool {
+		for _, sel := range seg.selectors {
+			if streamSelectorMatches(sel, key) {
+				return true
+			}
+		}
+		return false
+	}
+	if !seg.descendant {
+		return len(keys) > 0 && selected(keys[0]) && streamPathMatches(segments[1:], keys[1:])
+	}
+	for i := range keys {
+		if selected(keys[i]) && streamPathMatches(segments[1:], keys[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// value 处理当前位置的一个值
+func (d *StreamDecoder) value() error {
+	var matched []streamHandler
+	for _, h := range d.handlers {
+		if streamPathMatches(h.path.segments, d.keys) {
+			matched = append(matched, h)
+		}
+	}
+	if len(matched) > 0 {
+		return d.dispatch(matched)
+	}
+
+	tok, err := d.dec.Token()
+	if err != nil {
+		return d.wrapError(err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		for d.dec.More() {
+			tok, err := d.dec.Token()
+			if err != nil {
+				return d.wrapError(err)
+			}
+			d.keys = append(d.keys, tok.(string))
+			if err := d.value(); err != nil {
+				return err
+			}
+			d.keys = d.keys[:len(d.keys)-1]
+		}
+	case json.Delim('['):
+		for i := 0; d.dec.More(); i++ {
+			d.keys = append(d.keys, i)
+			if err := d.value(); err != nil {
+				return err
+			}
+			d.keys = d.keys[:len(d.keys)-1]
+		}
+	default:
+		return nil
+	}
+	// 读取结束符
+	if _, err := d.dec.Token(); err != nil {
+		return d.wrapError(err)
+	}
+	return nil
+}
+
+// dispatch 解码当前值并调用匹配的回调
+func (d *StreamDecoder) dispatch(handlers []streamHandler) error {
+	if len(handlers) == 1 {
+		decoded := false
+		err := handlers[0].fn(func(v interface{}) error {
+			if decoded {
+				return fmt.Errorf("value at %s already decoded", d.Location())
+			}
+			decoded = true
+			return d.wrapError(d.dec.Decode(v))
+		})
+		if err == nil && !decoded {
+			// 回调未读取值时跳过它
+			var skip json.RawMessage
+			err = d.wrapError(d.dec.Decode(&skip))
+		}
+		return err
+	}
+	// 多个回调匹配同一位置时先读取原始字节，再按相同的解码选项分别解码
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return d.wrapError(err)
+	}
+	for _, h := range handlers {
+		err := h.fn(func(v interface{}) error {
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			if d.useNumber {
+				dec.UseNumber()
+			}
+			if d.disallowUnknown {
+				dec.DisallowUnknownFields()
+			}
+			return d.wrapError(dec.Decode(v))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrapError 为解析错误附加当前位置
+func (d *StreamDecoder) wrapError(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return fmt.Errorf("%s: %w", d.Location(), err)
+}
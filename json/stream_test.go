@@ -0,0 +1,122 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamDecoderHandle(t *testing.T) {
+	const input = `{"store":{"book":[{"title":"a","price":8},{"title":"b","price":12},{"title":"c","price":9}],"bicycle":{"price":20}}}`
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "child", path: "$.store.bicycle", want: []string{`{"price":20}`}},
+		{name: "index", path: "$.store.book[1].title", want: []string{`"b"`}},
+		{name: "wildcard", path: "$.store.book[*].title", want: []string{`"a"`, `"b"`, `"c"`}},
+		{name: "slice", path: "$.store.book[0:3:2].title", want: []string{`"a"`, `"c"`}},
+		{name: "descendant", path: "$..price", want: []string{`8`, `12`, `9`, `20`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewStreamDecoder(strings.NewReader(input))
+			var got []string
+			err := d.Handle(tt.path, func(decode func(v interface{}) error) error {
+				var raw json.RawMessage
+				if err := decode(&raw); err != nil {
+					return err
+				}
+				got = append(got, string(raw))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Decode(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderUnsupportedPath(t *testing.T) {
+	d := NewStreamDecoder(strings.NewReader(`[]`))
+	for _, path := range []string{"$[-1]", "$[?@.a]", "$[::-1]"} {
+		if err := d.Handle(path, func(func(v interface{}) error) error { return nil }); err == nil {
+			t.Errorf("Handle(%q) error = nil, want error", path)
+		}
+	}
+}
+
+func TestStreamDecoderStop(t *testing.T) {
+	var got []int
+	err := StreamPath(strings.NewReader(`[1,2,3,4]`), "$[*]", func(v int) error {
+		got = append(got, v)
+		if v == 2 {
+			return ErrStopStreaming
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("got %v, want [1 2]", got)
+	}
+}
+
+func TestStreamDecoderOptionsWithMultipleHandlers(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+	for _, handlers := range []int{1, 2} {
+		d := NewStreamDecoder(strings.NewReader(`{"items":[{"id":1,"extra":true}]}`))
+		d.DisallowUnknownFields()
+		for i := 0; i < handlers; i++ {
+			if err := OnPath(d, "$.items[*]", func(item) error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := d.Decode(); err == nil {
+			t.Errorf("%d handler(s): Decode() error = nil, want unknown field error", handlers)
+		}
+
+		d = NewStreamDecoder(strings.NewReader(`{"n":12345678901234567890}`))
+		d.UseNumber()
+		var got []interface{}
+		for i := 0; i < handlers; i++ {
+			err := OnPath(d, "$.n", func(v interface{}) error {
+				got = append(got, v)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := d.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range got {
+			if v != json.Number("12345678901234567890") {
+				t.Errorf("%d handler(s): decoded %#v, want json.Number", handlers, v)
+			}
+		}
+	}
+}
+
+func TestStreamDecoderLocation(t *testing.T) {
+	d := NewStreamDecoder(strings.NewReader(`{"a":[{"b":1},{"b":"x"}]}`))
+	if err := OnPath(d, "$.a[*].b", func(int) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	err := d.Decode()
+	if err == nil || !strings.Contains(err.Error(), "$['a'][1]['b']") {
+		t.Errorf("Decode() error = %v, want location $['a'][1]['b']", err)
+	}
+}
@@ -0,0 +1,363 @@
+package jsonutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidStream 表示 StreamEncoder 的调用顺序无法构成合法的 JSON
+var ErrInvalidStream = errors.New("invalid JSON stream")
+
+// streamOptions 保存流式编码器的选项
+type streamOptions struct {
+	prefix     string
+	indent     string
+	escapeHTML bool
+	flushEvery int
+	bufferSize int
+}
+
+// StreamOption 是设置流式编码器选项的函数类型
+type StreamOption func(*streamOptions)
+
+// WithStreamIndent 按 MarshalIndent 的方式格式化输出，每行以 prefix 开头并按层级重复 indent
+func WithStreamIndent(prefix, indent string) StreamOption {
+	return func(o *streamOptions) {
+		o.prefix = prefix
+		o.indent = indent
+	}
+}
+
+// WithStreamEscapeHTML 设置是否将字符串中的 <、>、& 转义为 \u003c 等形式，默认转义，与 Marshal 一致
+func WithStreamEscapeHTML(escape bool) StreamOption {
+	return func(o *streamOptions) {
+		o.escapeHTML = escape
+	}
+}
+
+// WithStreamFlushEvery 每写入 n 个数组元素或对象成员自动刷新一次，0 表示只在缓冲区满或调用 Flush 时写出
+// 底层 Writer 实现了 Flush 方法（如 http.ResponseWriter、gzip.Writer）时一并调用
+func WithStreamFlushEvery(n int) StreamOption {
+	return func(o *streamOptions) {
+		o.flushEvery = n
+	}
+}
+
+// WithStreamBufferSize 设置写缓冲区的大小，默认为 64 KiB
+func WithStreamBufferSize(n int) StreamOption {
+	return func(o *streamOptions) {
+		o.bufferSize = n
+	}
+}
+
+// streamFrame 表示一层尚未结束的对象或数组
+type streamFrame struct {
+	object     bool
+	count      int
+	keyPending bool
+}
+
+// StreamEncoder 以增量方式向 io.Writer 写出一个 JSON 值，内存占用与输出大小无关
+// 通过 BeginObject、Key、BeginArray、Value、End 描述结构，调用顺序不合法时返回包装 ErrInvalidStream 的错误；
+// 写入失败后的所有调用都返回同一个错误；结束时必须调用 Close 确认结构完整并写出缓冲区
+type StreamEncoder struct {
+	out     io.Writer
+	w       *bufio.Writer
+	opts    streamOptions
+	stack   []streamFrame
+	done    bool
+	err     error
+	pending int
+	buf     bytes.Buffer
+	enc     *json.Encoder
+}
+
+// NewStreamEncoder 创建流式编码器
+func NewStreamEncoder(w io.Writer, opts ...StreamOption) *StreamEncoder {
+	o := streamOptions{escapeHTML: true, bufferSize: 64 * 1024}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bufferSize <= 0 {
+		o.bufferSize = 64 * 1024
+	}
+	e := &StreamEncoder{out: w, w: bufio.NewWriterSize(w, o.bufferSize), opts: o}
+	e.enc = json.NewEncoder(&e.buf)
+	e.enc.SetEscapeHTML(o.escapeHTML)
+	return e
+}
+
+// BeginObject 开始一个对象
+func (e *StreamEncoder) BeginObject() error {
+	return e.begin(true)
+}
+
+// BeginArray 开始一个数组
+func (e *StreamEncoder) BeginArray() error {
+	return e.begin(false)
+}
+
+// Key 写入对象成员的键，之后必须写入对应的值
+func (e *StreamEncoder) Key(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+	top := e.top()
+	switch {
+	case top == nil || !top.object:
+		return fmt.Errorf("%w: key %q outside of an object", ErrInvalidStream, key)
+	case top.keyPending:
+		return fmt.Errorf("%w: key %q follows a key without a value", ErrInvalidStream, key)
+	}
+	e.separator(top)
+	e.buf.Reset()
+	if err := e.enc.Encode(key); err != nil {
+		return err
+	}
+	e.write(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n")))
+	if e.pretty() {
+		e.write([]byte(": "))
+	} else {
+		e.write([]byte(":"))
+	}
+	top.count++
+	top.keyPending = true
+	return e.err
+}
+
+// Value 写入一个完整的值，编码方式与 Marshal 相同，可以是数组元素、对象成员的值或顶层值
+func (e *StreamEncoder) Value(v interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.checkValue(); err != nil {
+		return err
+	}
+	e.buf.Reset()
+	if e.pretty() {
+		e.enc.SetIndent(e.linePrefix(len(e.stack)), e.opts.indent)
+	}
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	top := e.top()
+	e.beforeValue(top)
+	e.write(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n")))
+	return e.afterValue(top)
+}
+
+// Field 写入对象成员，等同于 Key 后接 Value
+func (e *StreamEncoder) Field(key string, v interface{}) error {
+	if err := e.Key(key); err != nil {
+		return err
+	}
+	return e.Value(v)
+}
+
+// End 结束最内层的对象或数组
+func (e *StreamEncoder) End() error {
+	if e.err != nil {
+		return e.err
+	}
+	top := e.top()
+	switch {
+	case top == nil:
+		return fmt.Errorf("%w: End without an open object or array", ErrInvalidStream)
+	case top.keyPending:
+		return fmt.Errorf("%w: object closed after a key without a value", ErrInvalidStream)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	if top.count > 0 && e.pretty() {
+		e.write([]byte("\n" + e.linePrefix(len(e.stack))))
+	}
+	if top.object {
+		e.write([]byte("}"))
+	} else {
+		e.write([]byte("]"))
+	}
+	return e.afterValue(e.top())
+}
+
+// Depth 返回当前尚未结束的对象和数组的层数
+func (e *StreamEncoder) Depth() int {
+	return len(e.stack)
+}
+
+// Flush 将缓冲区中的数据写出，底层 Writer 实现了 Flush 方法时一并调用
+func (e *StreamEncoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.pending = 0
+	if err := e.w.Flush(); err != nil {
+		e.err = err
+		return err
+	}
+	switch f := e.out.(type) {
+	case interface{ Flush() error }:
+		if err := f.Flush(); err != nil {
+			e.err = err
+			return err
+		}
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+// Close 确认已写出一个完整的 JSON 值并刷新缓冲区，不会关闭底层 Writer
+func (e *StreamEncoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	switch {
+	case len(e.stack) > 0:
+		return fmt.Errorf("%w: %d unclosed object(s) or array(s)", ErrInvalidStream, len(e.stack))
+	case !e.done:
+		return fmt.Errorf("%w: no value written", ErrInvalidStream)
+	}
+	return e.Flush()
+}
+
+// begin 开始一个对象或数组
+func (e *StreamEncoder) begin(object bool) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.checkValue(); err != nil {
+		return err
+	}
+	e.beforeValue(e.top())
+	if object {
+		e.write([]byte("{"))
+	} else {
+		e.write([]byte("["))
+	}
+	e.stack = append(e.stack, streamFrame{object: object})
+	return e.err
+}
+
+// checkValue 检查当前位置能否写入一个值
+func (e *StreamEncoder) checkValue() error {
+	top := e.top()
+	switch {
+	case top == nil && e.done:
+		return fmt.Errorf("%w: more than one top-level value", ErrInvalidStream)
+	case top != nil && top.object && !top.keyPending:
+		return fmt.Errorf("%w: object value without a key", ErrInvalidStream)
+	}
+	return nil
+}
+
+// beforeValue 在数组元素前写出分隔符，对象成员的分隔符已由 Key 写出
+func (e *StreamEncoder) beforeValue(top *streamFrame) {
+	if top != nil && !top.object {
+		e.separator(top)
+		top.count++
+	}
+}
+
+// afterValue 在一个值写完后更新状态，并按 WithStreamFlushEvery 的设置刷新
+func (e *StreamEncoder) afterValue(top *streamFrame) error {
+	if top == nil {
+		e.done = true
+		return e.err
+	}
+	top.keyPending = false
+	if e.err == nil && e.opts.flushEvery > 0 {
+		e.pending++
+		if e.pending >= e.opts.flushEvery {
+			return e.Flush()
+		}
+	}
+	return e.err
+}
+
+// separator 写出成员之间的逗号以及格式化时的换行和缩进
+func (e *StreamEncoder) separator(top *streamFrame) {
+	if top.count > 0 {
+		e.write([]byte(","))
+	}
+	if e.pretty() {
+		e.write([]byte("\n" + e.linePrefix(len(e.stack))))
+	}
+}
+
+// top 返回最内层的对象或数组，处于顶层时返回 nil
+func (e *StreamEncoder) top() *streamFrame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return &e.stack[len(e.stack)-1]
+}
+
+// pretty 判断是否格式化输出
+func (e *StreamEncoder) pretty() bool {
+	return e.opts.prefix != "" || e.opts.indent != ""
+}
+
+// linePrefix 返回指定层级的行首字符串
+func (e *StreamEncoder) linePrefix(depth int) string {
+	return e.opts.prefix + strings.Repeat(e.opts.indent, depth)
+}
+
+// write 写出字节，出错后记录错误并忽略后续写入
+func (e *StreamEncoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+	if _, err := e.w.Write(p); err != nil {
+		e.err = err
+	}
+}
+
+// StreamArray 将 seq 产生的元素写为一个完整的数组，seq 的签名与 iter.Seq[T] 相同
+func StreamArray[T any](e *StreamEncoder, seq func(yield func(T) bool)) error {
+	if err := e.BeginArray(); err != nil {
+		return err
+	}
+	var err error
+	seq(func(v T) bool {
+		err = e.Value(v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.End()
+}
+
+// StreamArrayChan 将从 ch 接收的元素写为一个完整的数组，直到 ch 被关闭
+// 出错时停止接收并返回，调用方需自行让发送方退出
+func StreamArrayChan[T any](e *StreamEncoder, ch <-chan T) error {
+	if err := e.BeginArray(); err != nil {
+		return err
+	}
+	for v := range ch {
+		if err := e.Value(v); err != nil {
+			return err
+		}
+	}
+	return e.End()
+}
+
+// StreamObject 将 seq 产生的键值对写为一个完整的对象，seq 的签名与 iter.Seq2[string, V] 相同
+func StreamObject[V any](e *StreamEncoder, seq func(yield func(string, V) bool)) error {
+	if err := e.BeginObject(); err != nil {
+		return err
+	}
+	var err error
+	seq(func(k string, v V) bool {
+		err = e.Field(k, v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.End()
+}
@@ -0,0 +1,986 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TOMLSyntaxError 表示 TOML 文本的语法或语义错误
+type TOMLSyntaxError struct {
+	Line   int    // 行号，从 1 开始
+	Column int    // 列号（按字符计），从 1 开始
+	Msg    string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *TOMLSyntaxError) Error() string {
+	return fmt.Sprintf("toml syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseTOML 解析 TOML 1.0 文本，返回与 FromJSON 解码到 interface{} 相同结构的值，需要保留数字精度时使用 ParseFormatExact
+// 整数和浮点数转换为 float64，日期时间转换为 RFC 3339 形式的字符串；inf 和 nan 转换为对应的 float64
+func ParseTOML(data []byte) (map[string]interface{}, error) {
+	return parseTOMLValue(data, false)
+}
+
+// parseTOMLValue 解析 TOML 文本，exact 为 true 时数字保持为 json.Number
+func parseTOMLValue(data []byte, exact bool) (map[string]interface{}, error) {
+	root, err := parseTOMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return toPlainTree(root, exact).(map[string]interface{}), nil
+}
+
+// TOMLToJSON 将 TOML 文本转换为 JSON，键按文档中的顺序输出，整数保持原始精度
+// JSON 无法表示 inf 和 nan，遇到它们时返回错误
+func TOMLToJSON(data []byte) ([]byte, error) {
+	root, err := parseTOMLDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(root)
+}
+
+// UnmarshalTOML 将 TOML 文本解析到 v 中，字段映射规则与 Unmarshal 相同（使用 json 标签）
+func UnmarshalTOML(data []byte, v interface{}) error {
+	if p, ok := v.(*interface{}); ok {
+		value, err := ParseTOML(data)
+		if err != nil {
+			return err
+		}
+		*p = value
+		return nil
+	}
+	js, err := TOMLToJSON(data)
+	if err != nil {
+		return err
+	}
+	return unmarshalConverted(js, v, false)
+}
+
+// FromTOML 将 TOML 字符串解析到 v 中
+func FromTOML(str string, v interface{}) error {
+	return UnmarshalTOML([]byte(str), v)
+}
+
+// FromTOMLFile 读取 TOML 文件并解析到 v 中
+func FromTOMLFile(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return UnmarshalTOML(data, v)
+}
+
+// MarshalTOML 将对象按 JSON 规则编码后转换为 TOML
+func MarshalTOML(v interface{}) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToTOML(data)
+}
+
+// ToTOMLFile 将对象原子地保存为 TOML 文件，写入方式和选项见 WriteFileAtomic
+func ToTOMLFile(v interface{}, filename string, opts ...WriteOption) error {
+	data, err := MarshalTOML(v)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filename, data, opts...)
+}
+
+// JSONToTOML 将 JSON 转换为 TOML，顶层必须是对象
+// 对象转换为表，元素全是对象的数组转换为表数组；TOML 没有 null，值为 null 的键会被省略，数组中的 null 返回错误
+func JSONToTOML(data []byte) ([]byte, error) {
+	v, err := decodeOrderedBytes(data, true)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := v.(*JSONObject)
+	if !ok {
+		return nil, fmt.Errorf("toml: top-level value must be an object, got %s", jsonTypeName(v))
+	}
+	e := &tomlEncoder{}
+	if err := e.writeTable(nil, root, false); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// tomlSlot 标识表中的一个键
+type tomlSlot struct {
+	table *JSONObject
+	key   string
+}
+
+// tomlParser 是 TOML 的递归下降解析器
+type tomlParser struct {
+	data     []byte
+	pos      int
+	root     *JSONObject
+	current  *JSONObject
+	explicit map[*JSONObject]bool // 由 [table] 定义的表
+	dotted   map[*JSONObject]bool // 由点分键创建的表
+	frozen   map[*JSONObject]bool // 内联表，定义后不可修改
+	arrays   map[tomlSlot]bool    // 由 [[table]] 创建的表数组
+}
+
+// parseTOMLDocument 解析完整的 TOML 文档，表为 *JSONObject，数字为 json.Number
+func parseTOMLDocument(data []byte) (*JSONObject, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, &TOMLSyntaxError{Line: 1, Column: 1, Msg: "invalid UTF-8"}
+	}
+	root := NewJSONObject()
+	p := &tomlParser{
+		data:     data,
+		root:     root,
+		current:  root,
+		explicit: map[*JSONObject]bool{},
+		dotted:   map[*JSONObject]bool{},
+		frozen:   map[*JSONObject]bool{},
+		arrays:   map[tomlSlot]bool{},
+	}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			return root, nil
+		}
+		switch p.data[p.pos] {
+		case '#', '\r', '\n':
+		case '[':
+			if err := p.parseHeader(); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.parseKeyValue(p.current); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// errorf 创建带有当前位置的语法错误
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line, col := 1, 1
+	for _, r := range string(p.data[:p.pos]) {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &TOMLSyntaxError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe 描述当前位置的字符，用于错误信息
+func (p *tomlParser) describe() string {
+	if p.pos >= len(p.data) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	if r == '\n' {
+		return "newline"
+	}
+	return strconv.QuoteRune(r)
+}
+
+// skipBlank 跳过空格和制表符
+func (p *tomlParser) skipBlank() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment 跳过注释，注释中不允许出现除制表符外的控制字符
+func (p *tomlParser) skipComment() error {
+	if p.pos >= len(p.data) || p.data[p.pos] != '#' {
+		return nil
+	}
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		if c := p.data[p.pos]; c == '\r' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+			return nil
+		} else if isTOMLControl(c) {
+			return p.errorf("control character %q in comment", c)
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// newline 消费一个换行符（LF 或 CRLF），返回是否成功
+func (p *tomlParser) newline() bool {
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+		return true
+	}
+	if p.pos+1 < len(p.data) && p.data[p.pos] == '\r' && p.data[p.pos+1] == '\n' {
+		p.pos += 2
+		return true
+	}
+	return false
+}
+
+// endOfLine 确认当前行剩余部分只有空白和注释，并消费换行符
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank()
+	if err := p.skipComment(); err != nil {
+		return err
+	}
+	if p.pos >= len(p.data) || p.newline() {
+		return nil
+	}
+	return p.errorf("expected newline, got %s", p.describe())
+}
+
+// skipWhitespace 跳过数组中允许出现的空白、换行和注释
+func (p *tomlParser) skipWhitespace() error {
+	for {
+		p.skipBlank()
+		if err := p.skipComment(); err != nil {
+			return err
+		}
+		if !p.newline() {
+			return nil
+		}
+	}
+}
+
+// isTOMLControl 判断字节是否为 TOML 不允许直接出现的控制字符
+func isTOMLControl(c byte) bool {
+	return (c < 0x20 && c != '\t') || c == 0x7f
+}
+
+// parseHeader 解析 [table] 或 [[array]] 表头
+func (p *tomlParser) parseHeader() error {
+	start := p.pos
+	p.pos++
+	isArray := p.pos < len(p.data) && p.data[p.pos] == '['
+	if isArray {
+		p.pos++
+	}
+	p.skipBlank()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipBlank()
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !bytes.HasPrefix(p.data[p.pos:], []byte(closing)) {
+		return p.errorf("expected %q, got %s", closing, p.describe())
+	}
+	p.pos += len(closing)
+
+	t := p.root
+	for i, key := range keys {
+		last := i == len(keys)-1
+		value, ok := t.Lookup(key)
+		if !ok {
+			if last && isArray {
+				table := NewJSONObject()
+				t.Set(key, []interface{}{table})
+				p.arrays[tomlSlot{t, key}] = true
+				t = table
+				break
+			}
+			table := NewJSONObject()
+			t.Set(key, table)
+			t = table
+			continue
+		}
+		switch c := value.(type) {
+		case *JSONObject:
+			if p.frozen[c] || (last && (isArray || p.explicit[c] || p.dotted[c])) {
+				p.pos = start
+				return p.errorf("table %s is already defined", strings.Join(keys[:i+1], "."))
+			}
+			t = c
+		case []interface{}:
+			if !p.arrays[tomlSlot{t, key}] || (last && !isArray) {
+				p.pos = start
+				return p.errorf("key %s is already defined as an array", strings.Join(keys[:i+1], "."))
+			}
+			if last {
+				table := NewJSONObject()
+				t.Set(key, append(c, table))
+				t = table
+			} else {
+				t = c[len(c)-1].(*JSONObject)
+			}
+		default:
+			p.pos = start
+			return p.errorf("key %s is already defined as a value", strings.Join(keys[:i+1], "."))
+		}
+	}
+	if !isArray {
+		p.explicit[t] = true
+	}
+	p.current = t
+	return nil
+}
+
+// parseKeyValue 解析 key = value 并写入表 t，点分键会创建中间表
+func (p *tomlParser) parseKeyValue(t *JSONObject) error {
+	start := p.pos
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipBlank()
+	if p.pos >= len(p.data) || p.data[p.pos] != '=' {
+		return p.errorf("expected '=' after key, got %s", p.describe())
+	}
+	p.pos++
+	p.skipBlank()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	for i, key := range keys[:len(keys)-1] {
+		existing, ok := t.Lookup(key)
+		if !ok {
+			table := NewJSONObject()
+			p.dotted[table] = true
+			t.Set(key, table)
+			t = table
+			continue
+		}
+		c, isTable := existing.(*JSONObject)
+		if !isTable || !p.dotted[c] || p.frozen[c] {
+			p.pos = start
+			return p.errorf("key %s is already defined", strings.Join(keys[:i+1], "."))
+		}
+		t = c
+	}
+	key := keys[len(keys)-1]
+	if t.Has(key) {
+		p.pos = start
+		return p.errorf("duplicate key %s", strings.Join(keys, "."))
+	}
+	t.Set(key, value)
+	return nil
+}
+
+// parseKey 解析可能带点的键，返回各段
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("expected key, got end of input")
+		}
+		var key string
+		switch c := p.data[p.pos]; {
+		case c == '"':
+			if bytes.HasPrefix(p.data[p.pos:], []byte(`"""`)) {
+				return nil, p.errorf("multi-line strings cannot be used as keys")
+			}
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			if bytes.HasPrefix(p.data[p.pos:], []byte("'''")) {
+				return nil, p.errorf("multi-line strings cannot be used as keys")
+			}
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case isTOMLBareKeyChar(c):
+			start := p.pos
+			for p.pos < len(p.data) && isTOMLBareKeyChar(p.data[p.pos]) {
+				p.pos++
+			}
+			key = string(p.data[start:p.pos])
+		default:
+			return nil, p.errorf("expected key, got %s", p.describe())
+		}
+		keys = append(keys, key)
+		p.skipBlank()
+		if p.pos >= len(p.data) || p.data[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// isTOMLBareKeyChar 判断字符是否可以出现在不带引号的键中
+func isTOMLBareKeyChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+// parseValue 解析一个值
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("expected value, got end of input")
+	}
+	switch p.data[p.pos] {
+	case '"':
+		if bytes.HasPrefix(p.data[p.pos:], []byte(`"""`)) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case '\'':
+		if bytes.HasPrefix(p.data[p.pos:], []byte("'''")) {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	}
+	return p.parseScalar()
+}
+
+// parseBasicString 解析单行基本字符串
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) || p.data[p.pos] == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		case c == '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case isTOMLControl(c):
+			return "", p.errorf("control character %q in string", c)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseMultilineBasicString 解析多行基本字符串，紧跟开头引号的换行会被去掉，行尾的反斜杠会连接下一行
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.newline()
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case bytes.HasPrefix(p.data[p.pos:], []byte(`"""`)):
+			p.pos += 3
+			// 结束引号之前最多可以有两个引号属于字符串内容
+			for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] == '"'; i++ {
+				sb.WriteByte('"')
+				p.pos++
+			}
+			return sb.String(), nil
+		case c == '\\':
+			if p.isLineEndingBackslash() {
+				p.pos++
+				for p.pos < len(p.data) {
+					if b := p.data[p.pos]; b == ' ' || b == '\t' {
+						p.pos++
+					} else if !p.newline() {
+						break
+					}
+				}
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case c == '\r' && p.newline():
+			sb.WriteByte('\n')
+		case c != '\n' && isTOMLControl(c):
+			return "", p.errorf("control character %q in string", c)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// isLineEndingBackslash 判断当前的反斜杠之后是否只有空白直到行尾
+func (p *tomlParser) isLineEndingBackslash() bool {
+	i := p.pos + 1
+	for i < len(p.data) && (p.data[i] == ' ' || p.data[i] == '\t') {
+		i++
+	}
+	return i < len(p.data) && (p.data[i] == '\n' || (p.data[i] == '\r' && i+1 < len(p.data) && p.data[i+1] == '\n'))
+}
+
+// parseEscape 解析基本字符串中的转义序列
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	if p.pos+1 >= len(p.data) {
+		return p.errorf("unterminated escape sequence")
+	}
+	c := p.data[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.data) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.data[p.pos:p.pos+n]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape \\%c%s", c, p.data[p.pos:p.pos+n])
+		}
+		sb.WriteRune(rune(code))
+		p.pos += n
+	default:
+		p.pos -= 2
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+// parseLiteralString 解析单行字面量字符串，不处理转义
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	start := p.pos
+	for {
+		if p.pos >= len(p.data) || p.data[p.pos] == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.data[p.pos]
+		if c == '\'' {
+			s := string(p.data[start:p.pos])
+			p.pos++
+			return s, nil
+		}
+		if isTOMLControl(c) {
+			return "", p.errorf("control character %q in string", c)
+		}
+		p.pos++
+	}
+}
+
+// parseMultilineLiteralString 解析多行字面量字符串
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.newline()
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case bytes.HasPrefix(p.data[p.pos:], []byte("'''")):
+			p.pos += 3
+			for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] == '\''; i++ {
+				sb.WriteByte('\'')
+				p.pos++
+			}
+			return sb.String(), nil
+		case c == '\r' && p.newline():
+			sb.WriteByte('\n')
+		case c != '\n' && isTOMLControl(c):
+			return "", p.errorf("control character %q in string", c)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseArray 解析数组，元素之间允许换行和注释，允许尾随逗号
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.pos++
+	arr := []interface{}{}
+	for {
+		if err := p.skipWhitespace(); err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+		if err := p.skipWhitespace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return arr, nil
+		default:
+			return nil, p.errorf("expected ',' or ']' in array, got %s", p.describe())
+		}
+	}
+}
+
+// parseInlineTable 解析内联表，内联表必须写在一行内且定义后不可修改
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	table := NewJSONObject()
+	p.skipBlank()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		p.frozen[table] = true
+		return table, nil
+	}
+	for {
+		p.skipBlank()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			p.frozen[table] = true
+			return table, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table, got %s", p.describe())
+		}
+	}
+}
+
+var (
+	tomlDecimalInt = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlHexInt     = regexp.MustCompile(`^0x[0-9A-Fa-f](_?[0-9A-Fa-f])*$`)
+	tomlOctInt     = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	tomlBinInt     = regexp.MustCompile(`^0b[01](_?[01])*$`)
+	tomlFloat      = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlDate       = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlTime       = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+	tomlDateTime   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})[Tt ](\d{2}:\d{2}:\d{2}(\.\d+)?)([Zz]|[+-]\d{2}:\d{2})?$`)
+)
+
+// parseScalar 解析布尔值、数字和日期时间
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.data) && isTOMLScalarChar(p.data[p.pos]) {
+		p.pos++
+	}
+	// 日期和时间之间可以用空格分隔
+	if tomlDate.Match(p.data[start:p.pos]) && p.pos+3 < len(p.data) && p.data[p.pos] == ' ' &&
+		isDigit(p.data[p.pos+1]) && isDigit(p.data[p.pos+2]) && p.data[p.pos+3] == ':' {
+		p.pos++
+		for p.pos < len(p.data) && isTOMLScalarChar(p.data[p.pos]) {
+			p.pos++
+		}
+	}
+	text := string(p.data[start:p.pos])
+	if text == "" {
+		return nil, p.errorf("expected value, got %s", p.describe())
+	}
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	clean := strings.ReplaceAll(text, "_", "")
+	switch {
+	case tomlDecimalInt.MatchString(text):
+		n, err := strconv.ParseInt(clean, 10, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("integer %s out of range", text)
+		}
+		return json.Number(strconv.FormatInt(n, 10)), nil
+	case tomlHexInt.MatchString(text), tomlOctInt.MatchString(text), tomlBinInt.MatchString(text):
+		n, err := strconv.ParseInt(clean, 0, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("integer %s out of range", text)
+		}
+		return json.Number(strconv.FormatInt(n, 10)), nil
+	case tomlFloat.MatchString(text):
+		if _, err := strconv.ParseFloat(clean, 64); err != nil {
+			p.pos = start
+			return nil, p.errorf("float %s out of range", text)
+		}
+		return json.Number(strings.TrimPrefix(clean, "+")), nil
+	}
+	if s, ok := normalizeTOMLDateTime(text); ok {
+		return s, nil
+	}
+	p.pos = start
+	return nil, p.errorf("invalid value %q", text)
+}
+
+// isTOMLScalarChar 判断字符是否可能出现在布尔值、数字或日期时间中
+func isTOMLScalarChar(c byte) bool {
+	return isTOMLBareKeyChar(c) || c == '+' || c == '.' || c == ':'
+}
+
+// isDigit 判断字节是否为十进制数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// normalizeTOMLDateTime 校验日期时间并转换为 RFC 3339 形式（分隔符为 T，时区为大写 Z）
+func normalizeTOMLDateTime(text string) (string, bool) {
+	switch {
+	case tomlDate.MatchString(text):
+		_, err := time.Parse("2006-01-02", text)
+		return text, err == nil
+	case tomlTime.MatchString(text):
+		_, err := time.Parse("15:04:05", text[:8])
+		return text, err == nil
+	}
+	m := tomlDateTime.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	zone := strings.ToUpper(m[4])
+	if _, err := time.Parse("2006-01-02T15:04:05", m[1]+"T"+m[2][:8]); err != nil {
+		return "", false
+	}
+	if zone != "" && zone != "Z" {
+		if _, err := time.Parse("-07:00", zone); err != nil {
+			return "", false
+		}
+	}
+	return m[1] + "T" + m[2] + zone, true
+}
+
+// tomlEncoder 将 JSON 值写为 TOML
+type tomlEncoder struct {
+	buf bytes.Buffer
+}
+
+// writeTable 写出表的内容：先写普通键值，再写子表和表数组；只包含子表的表省略表头
+func (e *tomlEncoder) writeTable(path []string, t *JSONObject, isArrayItem bool) error {
+	var simpleKeys, subKeys []string
+	for _, key := range t.Keys() {
+		switch value := t.Get(key); {
+		case value == nil:
+		case isTOMLTable(value) || isTableArray(value):
+			subKeys = append(subKeys, key)
+		default:
+			simpleKeys = append(simpleKeys, key)
+		}
+	}
+	if len(path) > 0 && (isArrayItem || len(simpleKeys) > 0 || len(subKeys) == 0) {
+		if e.buf.Len() > 0 {
+			e.buf.WriteByte('\n')
+		}
+		if isArrayItem {
+			e.buf.WriteString("[[" + tomlKeyPath(path) + "]]\n")
+		} else {
+			e.buf.WriteString("[" + tomlKeyPath(path) + "]\n")
+		}
+	}
+	for _, key := range simpleKeys {
+		e.buf.WriteString(tomlKey(key))
+		e.buf.WriteString(" = ")
+		if err := e.writeValue(t.Get(key)); err != nil {
+			return fmt.Errorf("toml: key %s: %w", tomlKeyPath(append(path, key)), err)
+		}
+		e.buf.WriteByte('\n')
+	}
+	for _, key := range subKeys {
+		sub := append(append([]string(nil), path...), key)
+		switch c := t.Get(key).(type) {
+		case *JSONObject:
+			if err := e.writeTable(sub, c, false); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, item := range c {
+				if err := e.writeTable(sub, item.(*JSONObject), true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isTOMLTable 判断值是否为对象
+func isTOMLTable(v interface{}) bool {
+	_, ok := v.(*JSONObject)
+	return ok
+}
+
+// isTableArray 判断值是否为元素全是对象的非空数组
+func isTableArray(v interface{}) bool {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) == 0 {
+		return false
+	}
+	for _, item := range arr {
+		if _, ok := item.(*JSONObject); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeValue 写出内联的值
+func (e *tomlEncoder) writeValue(v interface{}) error {
+	switch c := v.(type) {
+	case nil:
+		return fmt.Errorf("null cannot be represented in TOML")
+	case bool:
+		e.buf.WriteString(strconv.FormatBool(c))
+	case string:
+		e.buf.WriteString(quoteTOMLString(c))
+	case json.Number:
+		s := string(c)
+		if !strings.ContainsAny(s, ".eE") {
+			if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+				return fmt.Errorf("integer %s out of range", s)
+			}
+		}
+		e.buf.WriteString(s)
+	case *JSONObject:
+		e.buf.WriteByte('{')
+		first := true
+		for _, key := range c.Keys() {
+			item := c.Get(key)
+			if item == nil {
+				continue
+			}
+			if first {
+				e.buf.WriteByte(' ')
+			} else {
+				e.buf.WriteString(", ")
+			}
+			first = false
+			e.buf.WriteString(tomlKey(key))
+			e.buf.WriteString(" = ")
+			if err := e.writeValue(item); err != nil {
+				return err
+			}
+		}
+		if !first {
+			e.buf.WriteByte(' ')
+		}
+		e.buf.WriteByte('}')
+	case []interface{}:
+		e.buf.WriteByte('[')
+		for i, item := range c {
+			if i > 0 {
+				e.buf.WriteString(", ")
+			}
+			if err := e.writeValue(item); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+// tomlKeyPath 将键路径写为点分形式
+func tomlKeyPath(path []string) string {
+	parts := make([]string, len(path))
+	for i, key := range path {
+		parts[i] = tomlKey(key)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlKey 返回键的 TOML 写法，只含字母、数字、下划线和连字符时不加引号
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			return quoteTOMLString(key)
+		}
+	}
+	return key
+}
+
+// quoteTOMLString 将字符串编码为 TOML 基本字符串
+func quoteTOMLString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
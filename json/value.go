@@ -0,0 +1,246 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// decodeAny 将 JSON 字节解析为通用的 interface{} 值，数字保持为 json.Number
+func decodeAny(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := UnmarshalExact(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// toFloat64 将 JSON 数值转换为 float64
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		// 超出 float64 范围的数字仍是数字，转换为 ±Inf
+		f, err := n.Float64()
+		return f, err == nil || errors.Is(err, strconv.ErrRange)
+	case Decimal:
+		return n.Float64(), true
+	case *Decimal:
+		if n == nil {
+			return 0, false
+		}
+		return n.Float64(), true
+	default:
+		return 0, false
+	}
+}
+
+// isNumber 判断值是否为 JSON 数值
+func isNumber(v interface{}) bool {
+	_, ok := toFloat64(v)
+	return ok
+}
+
+// compareNumbers 比较两个 JSON 数值，返回 -1、0 或 1
+// 转换为 float64 后相等时再按十进制精确比较，因此超过 2^53 的相邻整数也能区分
+func compareNumbers(a, b interface{}) (int, bool) {
+	fa, ok := toFloat64(a)
+	if !ok {
+		return 0, false
+	}
+	fb, ok := toFloat64(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case fa < fb:
+		return -1, true
+	case fa > fb:
+		return 1, true
+	}
+	da, okA := toDecimal(a)
+	db, okB := toDecimal(b)
+	if okA && okB {
+		return da.Cmp(db), true
+	}
+	return 0, true
+}
+
+// valuesEqual 按 JSON 语义比较两个值是否相等，数值按值比较，对象忽略键顺序
+func valuesEqual(a, b interface{}) bool {
+	if isNumber(a) || isNumber(b) {
+		c, ok := compareNumbers(a, b)
+		return ok && c == 0
+	}
+	switch va := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		vb, ok := b.(bool)
+		return ok && va == vb
+	case string:
+		vb, ok := b.(string)
+		return ok && va == vb
+	case map[string]interface{}, *JSONObject:
+		if !isObject(b) || objectLen(a) != objectLen(b) {
+			return false
+		}
+		for _, k := range objectKeys(a) {
+			v, _ := objectGet(a, k)
+			w, ok := objectGet(b, k)
+			if !ok || !valuesEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !valuesEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// sortedKeys 返回按字典序排列的 map 键
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isObject 判断值是否为 JSON 对象，即 map[string]interface{} 或非 nil 的 *JSONObject
+func isObject(v interface{}) bool {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		return true
+	case *JSONObject:
+		return c != nil
+	}
+	return false
+}
+
+// newObject 创建空对象，ordered 为 true 时创建 *JSONObject
+func newObject(ordered bool) interface{} {
+	if ordered {
+		return NewJSONObject()
+	}
+	return map[string]interface{}{}
+}
+
+// objectLen 返回对象的成员数
+func objectLen(v interface{}) int {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		return len(c)
+	case *JSONObject:
+		return c.Len()
+	}
+	return 0
+}
+
+// objectKeys 返回对象的键：*JSONObject 按插入顺序，map 按字典序
+func objectKeys(v interface{}) []string {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		return sortedKeys(c)
+	case *JSONObject:
+		return c.keys
+	}
+	return nil
+}
+
+// objectGet 获取对象成员
+func objectGet(v interface{}, key string) (interface{}, bool) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		item, ok := c[key]
+		return item, ok
+	case *JSONObject:
+		return c.Lookup(key)
+	}
+	return nil, false
+}
+
+// objectSet 设置对象成员，*JSONObject 中新键追加到末尾
+func objectSet(v interface{}, key string, item interface{}) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		c[key] = item
+	case *JSONObject:
+		c.Set(key, item)
+	}
+}
+
+// objectDelete 删除对象成员
+func objectDelete(v interface{}, key string) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		delete(c, key)
+	case *JSONObject:
+		c.Remove(key)
+	}
+}
+
+// cloneValue 深拷贝由 map[string]interface{}、*JSONObject 和 []interface{} 组成的 JSON 值
+func cloneValue(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, item := range c {
+			m[k] = cloneValue(item)
+		}
+		return m
+	case *JSONObject:
+		if c == nil {
+			return c
+		}
+		obj := &JSONObject{keys: append([]string(nil), c.keys...), values: make(map[string]interface{}, len(c.values))}
+		for k, item := range c.values {
+			obj.values[k] = cloneValue(item)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(c))
+		for i, item := range c {
+			arr[i] = cloneValue(item)
+		}
+		return arr
+	default:
+		return v
+	}
+}
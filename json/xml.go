@@ -0,0 +1,559 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// XMLConvention 表示 XML 与 JSON 互相转换时采用的约定
+type XMLConvention int
+
+const (
+	// XMLConventionHutool 与 Hutool（org.json）一致：属性和子元素都是普通的键，文本放在 "content" 键，识别数字和布尔值
+	XMLConventionHutool XMLConvention = iota
+	// XMLConventionBadgerFish BadgerFish 约定：属性以 "@" 为前缀，文本放在 "$" 键，命名空间声明放在 "@xmlns" 对象中，值都是字符串
+	XMLConventionBadgerFish
+	// XMLConventionParker Parker 约定：丢弃根元素和属性，只有文本的元素直接转换为值，识别数字和布尔值
+	XMLConventionParker
+)
+
+// xmlOptions 保存 XML 转换的选项
+type xmlOptions struct {
+	convention    XMLConvention
+	attrPrefix    string
+	attrPrefixSet bool
+	textKey       string
+	keepStrings   bool
+	forceArray    map[string]bool
+	rootName      string
+	itemName      string
+	indent        string
+	header        bool
+}
+
+// XMLOption 是设置 XML 转换选项的函数类型
+type XMLOption func(*xmlOptions)
+
+// WithXMLConvention 设置转换约定，默认为 XMLConventionHutool
+func WithXMLConvention(c XMLConvention) XMLOption {
+	return func(o *xmlOptions) {
+		o.convention = c
+	}
+}
+
+// WithXMLAttributePrefix 设置属性对应的键前缀，Hutool 约定默认无前缀，BadgerFish 约定默认为 "@"
+// JSON 转 XML 时带有该前缀的键会写为属性，前缀为空时所有键都写为子元素
+func WithXMLAttributePrefix(prefix string) XMLOption {
+	return func(o *xmlOptions) {
+		o.attrPrefix = prefix
+		o.attrPrefixSet = true
+	}
+}
+
+// WithXMLTextKey 设置文本内容对应的键，Hutool 约定默认为 "content"，BadgerFish 约定默认为 "$"
+func WithXMLTextKey(key string) XMLOption {
+	return func(o *xmlOptions) {
+		o.textKey = key
+	}
+}
+
+// WithXMLKeepStrings XML 转 JSON 时所有文本和属性值都保留为字符串，不识别数字和布尔值
+func WithXMLKeepStrings() XMLOption {
+	return func(o *xmlOptions) {
+		o.keepStrings = true
+	}
+}
+
+// WithXMLForceArray XML 转 JSON 时指定名称的元素即使只出现一次也转换为数组
+func WithXMLForceArray(names ...string) XMLOption {
+	return func(o *xmlOptions) {
+		if o.forceArray == nil {
+			o.forceArray = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			o.forceArray[name] = true
+		}
+	}
+}
+
+// WithXMLRootName JSON 转 XML 时设置根元素名称
+// 未设置时，只有一个键的对象以该键作为根元素（Parker 约定除外），否则根元素为 "root"
+func WithXMLRootName(name string) XMLOption {
+	return func(o *xmlOptions) {
+		o.rootName = name
+	}
+}
+
+// WithXMLItemName JSON 转 XML 时设置没有键名的数组元素（如嵌套数组的元素）使用的元素名，默认为 "item"
+func WithXMLItemName(name string) XMLOption {
+	return func(o *xmlOptions) {
+		o.itemName = name
+	}
+}
+
+// WithXMLIndent JSON 转 XML 时使用指定的缩进格式化输出
+func WithXMLIndent(indent string) XMLOption {
+	return func(o *xmlOptions) {
+		o.indent = indent
+	}
+}
+
+// WithXMLHeader JSON 转 XML 时输出 <?xml version="1.0" encoding="UTF-8"?> 声明
+func WithXMLHeader() XMLOption {
+	return func(o *xmlOptions) {
+		o.header = true
+	}
+}
+
+// newXMLOptions 创建默认选项并应用自定义选项
+func newXMLOptions(opts []XMLOption) *xmlOptions {
+	o := &xmlOptions{itemName: "item"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	switch o.convention {
+	case XMLConventionBadgerFish:
+		if !o.attrPrefixSet {
+			o.attrPrefix = "@"
+		}
+		if o.textKey == "" {
+			o.textKey = "$"
+		}
+		o.keepStrings = true
+	case XMLConventionHutool:
+		if o.textKey == "" {
+			o.textKey = "content"
+		}
+	}
+	return o
+}
+
+// xmlElement 是 XML 元素的简化表示，文本按出现顺序保存去除首尾空白后的片段
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	texts    []string
+	pending  strings.Builder
+}
+
+// flushText 将累积的字符数据作为一个文本片段保存
+func (e *xmlElement) flushText() {
+	if s := strings.TrimSpace(e.pending.String()); s != "" {
+		e.texts = append(e.texts, s)
+	}
+	e.pending.Reset()
+}
+
+// ParseXML 按指定约定将 XML 文档转换为 JSON 值，对象为保持元素顺序的 *JSONObject
+// 重复出现的同名元素转换为数组；注释、处理指令和 DOCTYPE 会被忽略
+func ParseXML(data []byte, opts ...XMLOption) (interface{}, error) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, err
+	}
+	o := newXMLOptions(opts)
+	value := o.elementValue(root)
+	if o.convention == XMLConventionParker {
+		return value, nil
+	}
+	return NewJSONObject().Set(root.name, value), nil
+}
+
+// XMLToJSON 按指定约定将 XML 文档转换为 JSON
+func XMLToJSON(data []byte, opts ...XMLOption) ([]byte, error) {
+	v, err := ParseXML(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(v)
+}
+
+// parseXMLTree 将 XML 文档解析为元素树，元素和属性名保留原始的命名空间前缀
+func parseXMLTree(data []byte) (*xmlElement, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlElement
+	var stack []*xmlElement
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &xmlElement{name: xmlQualifiedName(t.Name), attrs: t.Attr}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("xml: multiple root elements")
+				}
+				root = el
+			} else {
+				parent := stack[len(stack)-1]
+				parent.flushText()
+				parent.children = append(parent.children, el)
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			name := xmlQualifiedName(t.Name)
+			if len(stack) == 0 || stack[len(stack)-1].name != name {
+				return nil, fmt.Errorf("xml: unexpected end element </%s>", name)
+			}
+			stack[len(stack)-1].flushText()
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, fmt.Errorf("xml: text outside of root element")
+				}
+				continue
+			}
+			stack[len(stack)-1].pending.Write(t)
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("xml: no root element")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("xml: element <%s> is not closed", stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// xmlQualifiedName 返回带命名空间前缀的名称
+func xmlQualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// elementValue 按约定将元素转换为 JSON 值
+func (o *xmlOptions) elementValue(el *xmlElement) interface{} {
+	if o.convention == XMLConventionParker {
+		return o.parkerValue(el)
+	}
+	obj := NewJSONObject()
+	arrays := map[string]bool{}
+	for _, attr := range el.attrs {
+		if o.convention == XMLConventionBadgerFish && isXMLNamespaceDecl(attr.Name) {
+			ns, _ := obj.Get("@xmlns").(*JSONObject)
+			if ns == nil {
+				ns = NewJSONObject()
+				obj.Set("@xmlns", ns)
+			}
+			if attr.Name.Space == "" {
+				ns.Set("$", attr.Value)
+			} else {
+				ns.Set(attr.Name.Local, attr.Value)
+			}
+			continue
+		}
+		o.addValue(obj, arrays, o.attrPrefix+xmlQualifiedName(attr.Name), o.scalar(attr.Value), false)
+	}
+	for _, child := range el.children {
+		o.addValue(obj, arrays, child.name, o.elementValue(child), o.forceArray[child.name])
+	}
+	text := o.textValue(el.texts)
+	if obj.Len() == 0 && o.convention == XMLConventionHutool {
+		if text == nil {
+			return ""
+		}
+		return text
+	}
+	if text != nil {
+		o.addValue(obj, arrays, o.textKey, text, false)
+	}
+	return obj
+}
+
+// parkerValue 按 Parker 约定转换元素：只有文本的元素转换为值，子元素同名时转换为数组
+func (o *xmlOptions) parkerValue(el *xmlElement) interface{} {
+	if len(el.children) == 0 {
+		return o.textValue(el.texts)
+	}
+	if len(el.children) > 1 {
+		same := true
+		for _, child := range el.children[1:] {
+			if child.name != el.children[0].name {
+				same = false
+				break
+			}
+		}
+		if same {
+			arr := make([]interface{}, 0, len(el.children))
+			for _, child := range el.children {
+				arr = append(arr, o.parkerValue(child))
+			}
+			return arr
+		}
+	}
+	obj := NewJSONObject()
+	arrays := map[string]bool{}
+	for _, child := range el.children {
+		o.addValue(obj, arrays, child.name, o.parkerValue(child), o.forceArray[child.name])
+	}
+	return obj
+}
+
+// addValue 向对象添加值，同名的值合并为数组；arrays 记录哪些键是因重复而生成的数组
+func (o *xmlOptions) addValue(obj *JSONObject, arrays map[string]bool, key string, value interface{}, force bool) {
+	existing, ok := obj.Lookup(key)
+	switch {
+	case !ok && force:
+		obj.Set(key, []interface{}{value})
+		arrays[key] = true
+	case !ok:
+		obj.Set(key, value)
+	case arrays[key]:
+		obj.Set(key, append(existing.([]interface{}), value))
+	default:
+		obj.Set(key, []interface{}{existing, value})
+		arrays[key] = true
+	}
+}
+
+// textValue 返回元素文本对应的值，没有文本时返回 nil，多段文本（混合内容）返回数组
+func (o *xmlOptions) textValue(texts []string) interface{} {
+	switch len(texts) {
+	case 0:
+		return nil
+	case 1:
+		return o.scalar(texts[0])
+	}
+	arr := make([]interface{}, len(texts))
+	for i, s := range texts {
+		arr[i] = o.scalar(s)
+	}
+	return arr
+}
+
+// scalar 将文本转换为标量值，未设置 WithXMLKeepStrings 时识别布尔值和 JSON 格式的数字
+// 数字保存为 json.Number 以保留原始精度，带前导零的数字（如编号 "007"）保留为字符串
+func (o *xmlOptions) scalar(s string) interface{} {
+	if o.keepStrings {
+		return s
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if isJSONNumberText(s) {
+		return json.Number(s)
+	}
+	return s
+}
+
+// isJSONNumberText 判断字符串是否为合法的 JSON 数字
+func isJSONNumberText(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// isXMLNamespaceDecl 判断属性是否为命名空间声明
+func isXMLNamespaceDecl(name xml.Name) bool {
+	return name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns")
+}
+
+// JSONToXML 按指定约定将 JSON 转换为 XML
+// 数组转换为重复的同名元素，嵌套数组的元素使用 WithXMLItemName 设置的名称包装，null 转换为空元素
+func JSONToXML(data []byte, opts ...XMLOption) ([]byte, error) {
+	v, err := decodeOrderedBytes(data, true)
+	if err != nil {
+		return nil, err
+	}
+	o := newXMLOptions(opts)
+	name := o.rootName
+	if name == "" {
+		name = "root"
+		if o.convention != XMLConventionParker && objectLen(v) == 1 {
+			key := objectKeys(v)[0]
+			if item, _ := objectGet(v, key); !isXMLArray(item) {
+				name, v = key, item
+			}
+		}
+	}
+	if isXMLArray(v) {
+		v = NewJSONObject().Set(o.itemName, v)
+	}
+	elements, err := o.toElements(name, v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if o.header {
+		buf.WriteString(strings.TrimSuffix(xml.Header, "\n"))
+	}
+	o.writeElement(&buf, elements[0], 0)
+	return buf.Bytes(), nil
+}
+
+// ToXML 将 Go 值按 JSON 规则编码后转换为 XML
+func ToXML(v interface{}, opts ...XMLOption) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToXML(data, opts...)
+}
+
+// isXMLArray 判断值是否为数组
+func isXMLArray(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// toElements 将 JSON 值转换为名为 name 的元素，数组转换为多个同名元素
+func (o *xmlOptions) toElements(name string, v interface{}) ([]*xmlElement, error) {
+	if !isXMLName(name) {
+		return nil, fmt.Errorf("xml: invalid element name %q", name)
+	}
+	if arr, ok := v.([]interface{}); ok {
+		out := make([]*xmlElement, 0, len(arr))
+		for _, item := range arr {
+			if isXMLArray(item) {
+				children, err := o.toElements(o.itemName, item)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, &xmlElement{name: name, children: children})
+				continue
+			}
+			els, err := o.toElements(name, item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, els...)
+		}
+		return out, nil
+	}
+	el := &xmlElement{name: name}
+	if !isObject(v) {
+		if v != nil {
+			el.texts = []string{xmlScalarText(v)}
+		}
+		return []*xmlElement{el}, nil
+	}
+	named := o.convention != XMLConventionParker
+	for _, key := range objectKeys(v) {
+		item, _ := objectGet(v, key)
+		switch {
+		case o.convention == XMLConventionBadgerFish && key == "@xmlns" && isObject(item):
+			for _, prefix := range objectKeys(item) {
+				uri, _ := objectGet(item, prefix)
+				attr := xml.Attr{Name: xml.Name{Space: "xmlns", Local: prefix}, Value: xmlScalarText(uri)}
+				if prefix == "$" {
+					attr.Name = xml.Name{Local: "xmlns"}
+				}
+				el.attrs = append(el.attrs, attr)
+			}
+		case named && key == o.textKey:
+			if arr, ok := item.([]interface{}); ok {
+				for _, s := range arr {
+					el.texts = append(el.texts, xmlScalarText(s))
+				}
+			} else if item != nil {
+				el.texts = append(el.texts, xmlScalarText(item))
+			}
+		case named && o.attrPrefix != "" && strings.HasPrefix(key, o.attrPrefix):
+			attrName := key[len(o.attrPrefix):]
+			if !isXMLName(attrName) {
+				return nil, fmt.Errorf("xml: invalid attribute name %q", attrName)
+			}
+			if isObject(item) || isXMLArray(item) {
+				return nil, fmt.Errorf("xml: attribute %q must be a scalar value", attrName)
+			}
+			el.attrs = append(el.attrs, xml.Attr{Name: xml.Name{Local: attrName}, Value: xmlScalarText(item)})
+		default:
+			children, err := o.toElements(key, item)
+			if err != nil {
+				return nil, err
+			}
+			el.children = append(el.children, children...)
+		}
+	}
+	return []*xmlElement{el}, nil
+}
+
+// xmlScalarText 返回标量值的文本形式
+func xmlScalarText(v interface{}) string {
+	switch c := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case bool:
+		return strconv.FormatBool(c)
+	case json.Number:
+		return string(c)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// isXMLName 判断字符串是否为合法的 XML 名称（允许带一个命名空间前缀）
+func isXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == utf8.RuneError {
+			return false
+		}
+		first := i == 0 || s[i-1] == ':'
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case r == ':' && !first && i < len(s)-1:
+		case !first && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return strings.Count(s, ":") <= 1
+}
+
+// writeElement 将元素写为 XML，设置了缩进时每个子元素独占一行；混合内容的文本写在子元素之前
+func (o *xmlOptions) writeElement(buf *bytes.Buffer, el *xmlElement, depth int) {
+	if o.indent != "" && buf.Len() > 0 {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(o.indent, depth))
+	}
+	buf.WriteByte('<')
+	buf.WriteString(el.name)
+	for _, attr := range el.attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(xmlQualifiedName(attr.Name))
+		buf.WriteString(`="`)
+		xml.EscapeText(buf, []byte(attr.Value))
+		buf.WriteByte('"')
+	}
+	if len(el.children) == 0 && len(el.texts) == 0 {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteByte('>')
+	xml.EscapeText(buf, []byte(strings.Join(el.texts, " ")))
+	for _, child := range el.children {
+		o.writeElement(buf, child, depth+1)
+	}
+	if o.indent != "" && len(el.children) > 0 {
+		buf.WriteByte('\n')
+		buf.WriteString(strings.Repeat(o.indent, depth))
+	}
+	buf.WriteString("</")
+	buf.WriteString(el.name)
+	buf.WriteByte('>')
+}
@@ -0,0 +1,1633 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// YAMLSyntaxError 表示 YAML 文本的语法错误
+type YAMLSyntaxError struct {
+	Line   int    // 行号，从 1 开始
+	Column int    // 列号（按字符计），从 1 开始
+	Msg    string // 错误描述
+}
+
+// Error 实现 error 接口
+func (e *YAMLSyntaxError) Error() string {
+	return fmt.Sprintf("yaml syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseYAML 解析 YAML 1.2 文本中的第一个文档，返回与 FromJSON 解码到 interface{} 相同结构的值，需要保留数字精度时使用 ParseFormatExact
+// 标量按 core schema 识别 null、布尔值和数字；支持锚点与别名、<< 合并键、块标量和流式集合；
+// 映射的键统一转换为字符串，没有文档时返回 nil
+func ParseYAML(data []byte) (interface{}, error) {
+	return parseYAMLValue(data, false)
+}
+
+// parseYAMLValue 解析 YAML 文本中的第一个文档，exact 为 true 时数字保持为 json.Number
+func parseYAMLValue(data []byte, exact bool) (interface{}, error) {
+	docs, err := parseYAMLStream(data)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return toPlainTree(docs[0], exact), nil
+}
+
+// ParseYAMLDocuments 解析包含多个文档（以 --- 分隔）的 YAML 流，返回每个文档的值
+func ParseYAMLDocuments(data []byte) ([]interface{}, error) {
+	docs, err := parseYAMLStream(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range docs {
+		docs[i] = toPlainTree(doc, false)
+	}
+	return docs, nil
+}
+
+// YAMLToJSON 将 YAML 文本中的第一个文档转换为 JSON，键按文档中的顺序输出，整数保持原始精度
+// JSON 无法表示 .inf 和 .nan，遇到它们时返回包含其路径和行列号的 *YAMLSyntaxError
+func YAMLToJSON(data []byte) ([]byte, error) {
+	docs, err := parseYAMLStream(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return []byte("null"), nil
+	}
+	if err := checkYAMLFinite(docs[0], nil); err != nil {
+		return nil, err
+	}
+	return Marshal(docs[0])
+}
+
+// yamlNonFinite 是解析得到的 .inf 或 .nan，记录其位置以便转换为 JSON 时报告错误
+type yamlNonFinite struct {
+	value        float64
+	line, column int
+}
+
+// checkYAMLFinite 检查值中是否包含 JSON 无法表示的浮点数
+func checkYAMLFinite(v interface{}, keys []interface{}) error {
+	switch c := v.(type) {
+	case yamlNonFinite:
+		return &YAMLSyntaxError{
+			Line:   c.line,
+			Column: c.column,
+			Msg:    fmt.Sprintf("%s: %s cannot be represented in JSON", pathLabel(dottedPath(keys)), yamlScalarText(c.value)),
+		}
+	case *JSONObject:
+		for _, k := range c.keys {
+			if err := checkYAMLFinite(c.values[k], childKeys(keys, k)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range c {
+			if err := checkYAMLFinite(item, childKeys(keys, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML 将 YAML 文本中的第一个文档解析到 v 中，字段映射规则与 Unmarshal 相同（使用 json 标签）
+func UnmarshalYAML(data []byte, v interface{}) error {
+	if p, ok := v.(*interface{}); ok {
+		value, err := ParseYAML(data)
+		if err != nil {
+			return err
+		}
+		*p = value
+		return nil
+	}
+	js, err := YAMLToJSON(data)
+	if err != nil {
+		return err
+	}
+	return unmarshalConverted(js, v, false)
+}
+
+// FromYAML 将 YAML 字符串解析到 v 中
+func FromYAML(str string, v interface{}) error {
+	return UnmarshalYAML([]byte(str), v)
+}
+
+// FromYAMLFile 读取 YAML 文件并解析到 v 中
+func FromYAMLFile(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return UnmarshalYAML(data, v)
+}
+
+// MarshalYAML 将对象按 JSON 规则编码后转换为 YAML
+func MarshalYAML(v interface{}) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToYAML(data)
+}
+
+// ToYAMLFile 将对象原子地保存为 YAML 文件，写入方式和选项见 WriteFileAtomic
+func ToYAMLFile(v interface{}, filename string, opts ...WriteOption) error {
+	data, err := MarshalYAML(v)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filename, data, opts...)
+}
+
+// JSONToYAML 将 JSON 转换为块样式的 YAML，对象的键保持原有顺序，多行字符串输出为 | 块标量
+func JSONToYAML(data []byte) ([]byte, error) {
+	v, err := decodeOrderedBytes(data, true)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch {
+	case objectLen(v) > 0:
+		writeYAMLMapping(&buf, v.(*JSONObject), 0, false)
+	case len(asYAMLArray(v)) > 0:
+		writeYAMLSequence(&buf, asYAMLArray(v), 0, false)
+	default:
+		buf.WriteString(yamlScalarText(v))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	// maxYAMLDepth 是允许的最大嵌套深度
+	maxYAMLDepth = 10000
+	// maxYAMLAliasNodes 是别名展开的节点总数上限，用于防御“十亿笑声”攻击
+	maxYAMLAliasNodes = 1000000
+)
+
+// yamlParser 是 YAML 的递归下降解析器，直接在文本上按列号判断块结构
+type yamlParser struct {
+	data       []byte
+	pos        int
+	anchors    map[string]interface{}
+	aliasNodes int
+	depth      int
+}
+
+// parseYAMLStream 解析 YAML 流中的全部文档，映射为 *JSONObject，数字为 json.Number
+func parseYAMLStream(data []byte) ([]interface{}, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, &YAMLSyntaxError{Line: 1, Column: 1, Msg: "invalid UTF-8"}
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	p := &yamlParser{data: data}
+	var docs []interface{}
+	for {
+		if err := p.skipToContent(false); err != nil {
+			return nil, err
+		}
+		// 忽略 %YAML、%TAG 等指令
+		for p.pos < len(p.data) && p.column() == 0 && p.data[p.pos] == '%' {
+			p.skipLine()
+			if err := p.skipToContent(false); err != nil {
+				return nil, err
+			}
+		}
+		if p.pos >= len(p.data) {
+			return docs, nil
+		}
+		if p.isDocMarker("...") {
+			p.pos += 3
+			continue
+		}
+		if p.isDocMarker("---") {
+			p.pos += 3
+		}
+		p.anchors = map[string]interface{}{}
+		if err := p.skipToContent(false); err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		if p.pos < len(p.data) && !p.isDocMarker("---") && !p.isDocMarker("...") {
+			v, err := p.parseBlockNode(-1, false, false)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.skipToContent(false); err != nil {
+				return nil, err
+			}
+			if p.pos < len(p.data) && !p.isDocMarker("---") && !p.isDocMarker("...") {
+				return nil, p.errorf("expected end of document, got %s", p.describe())
+			}
+			doc = v
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// errorf 创建带有当前位置的语法错误
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+// errorAt 创建带有指定位置的语法错误
+func (p *yamlParser) errorAt(pos int, format string, args ...interface{}) error {
+	line, col := p.position(pos)
+	return &YAMLSyntaxError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// position 返回字节偏移对应的行号和列号（按字符计）
+func (p *yamlParser) position(pos int) (line, col int) {
+	line, col = 1, 1
+	for _, r := range string(p.data[:pos]) {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// markNonFinite 将 .inf、.nan 替换为记录了位置的 yamlNonFinite
+func (p *yamlParser) markNonFinite(pos int, v interface{}) interface{} {
+	if f, ok := v.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		line, col := p.position(pos)
+		return yamlNonFinite{value: f, line: line, column: col}
+	}
+	return v
+}
+
+// describe 描述当前位置的字符，用于错误信息
+func (p *yamlParser) describe() string {
+	if p.pos >= len(p.data) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	if r == '\n' {
+		return "newline"
+	}
+	return strconv.QuoteRune(r)
+}
+
+// peek 返回当前位置偏移 off 处的字节，越界时返回 0
+func (p *yamlParser) peek(off int) byte {
+	if p.pos+off < len(p.data) {
+		return p.data[p.pos+off]
+	}
+	return 0
+}
+
+// column 返回当前位置的列号（从 0 开始，按字节计，缩进只由空格组成）
+func (p *yamlParser) column() int {
+	return p.pos - (bytes.LastIndexByte(p.data[:p.pos], '\n') + 1)
+}
+
+// isYAMLBlank 判断字节是否为空格或制表符
+func isYAMLBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isWSAt 判断 i 处是否为空白、换行或输入结束
+func (p *yamlParser) isWSAt(i int) bool {
+	return i >= len(p.data) || isYAMLBlank(p.data[i]) || p.data[i] == '\n'
+}
+
+// isFlowIndicator 判断字节是否为流式集合的指示符
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+// isDocMarker 判断当前位置是否为行首的 --- 或 ... 文档标记
+func (p *yamlParser) isDocMarker(marker string) bool {
+	return p.column() == 0 && bytes.HasPrefix(p.data[p.pos:], []byte(marker)) && p.isWSAt(p.pos+3)
+}
+
+// atDocBoundary 判断当前位置是否为输入结束或文档标记
+func (p *yamlParser) atDocBoundary() bool {
+	return p.pos >= len(p.data) || p.isDocMarker("---") || p.isDocMarker("...")
+}
+
+// skipLine 跳到下一行的行首
+func (p *yamlParser) skipLine() {
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	if p.pos < len(p.data) {
+		p.pos++
+	}
+}
+
+// skipSpace 跳过当前行的空白和注释，不跨越换行
+func (p *yamlParser) skipSpace() {
+	for p.pos < len(p.data) && isYAMLBlank(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '#' && (p.pos == 0 || p.isWSAt(p.pos-1)) {
+		for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// atLineEnd 判断当前位置是否为行尾或输入结束
+func (p *yamlParser) atLineEnd() bool {
+	return p.pos >= len(p.data) || p.data[p.pos] == '\n'
+}
+
+// skipToContent 跳过空白、注释和空行，停在下一个内容字符上；块结构中不允许用制表符缩进
+func (p *yamlParser) skipToContent(flow bool) error {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case c == ' ' || c == '\n':
+			p.pos++
+		case c == '\t':
+			if !flow && p.column() == p.indentWidth() {
+				i := p.pos
+				for i < len(p.data) && isYAMLBlank(p.data[i]) {
+					i++
+				}
+				if i < len(p.data) && p.data[i] != '\n' && p.data[i] != '#' {
+					return p.errorf("tab characters must not be used for indentation")
+				}
+			}
+			p.pos++
+		case c == '#' && (p.pos == 0 || p.isWSAt(p.pos-1)):
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// indentWidth 返回当前行开头的空格数
+func (p *yamlParser) indentWidth() int {
+	start := bytes.LastIndexByte(p.data[:p.pos], '\n') + 1
+	i := start
+	for i < len(p.data) && p.data[i] == ' ' {
+		i++
+	}
+	return i - start
+}
+
+// enter 增加嵌套深度并检查上限
+func (p *yamlParser) enter() error {
+	p.depth++
+	if p.depth > maxYAMLDepth {
+		return p.errorf("exceeded max depth of %d", maxYAMLDepth)
+	}
+	return nil
+}
+
+// parseProperties 解析节点的锚点（&name）和标签（!tag），flow 为 true 时允许跨行
+func (p *yamlParser) parseProperties(flow bool) (anchor, tag string, err error) {
+	for p.pos < len(p.data) {
+		switch {
+		case p.data[p.pos] == '&' && anchor == "":
+			p.pos++
+			if anchor = p.scanAnchorName(); anchor == "" {
+				return "", "", p.errorf("expected anchor name")
+			}
+		case p.data[p.pos] == '!' && tag == "":
+			if tag, err = p.scanTag(); err != nil {
+				return "", "", err
+			}
+		default:
+			return anchor, tag, nil
+		}
+		if flow {
+			if err := p.skipToContent(true); err != nil {
+				return "", "", err
+			}
+		} else {
+			p.skipSpace()
+		}
+	}
+	return anchor, tag, nil
+}
+
+// scanAnchorName 读取锚点或别名的名称
+func (p *yamlParser) scanAnchorName() string {
+	start := p.pos
+	for p.pos < len(p.data) && !p.isWSAt(p.pos) && !isFlowIndicator(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// scanTag 读取标签，将 !!name 和 !<tag:yaml.org,2002:name> 规范为 name
+func (p *yamlParser) scanTag() (string, error) {
+	start := p.pos
+	if p.peek(1) == '<' {
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return "", p.errorf("unterminated verbatim tag")
+		}
+		p.pos += end + 1
+		return strings.TrimPrefix(string(p.data[start+2:p.pos-1]), "tag:yaml.org,2002:"), nil
+	}
+	for p.pos < len(p.data) && !p.isWSAt(p.pos) && !isFlowIndicator(p.data[p.pos]) {
+		p.pos++
+	}
+	tag := string(p.data[start:p.pos])
+	if strings.HasPrefix(tag, "!!") {
+		return tag[2:], nil
+	}
+	return tag, nil
+}
+
+// parseBlockNode 解析块上下文中的节点，parent 是所属集合的缩进
+// inline 表示节点与映射的键位于同一行，此时不能开始块集合；compact 表示允许与 parent 同列的块序列
+func (p *yamlParser) parseBlockNode(parent int, inline, compact bool) (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	anchor, tag, err := p.parseProperties(false)
+	if err != nil {
+		return nil, err
+	}
+	if (anchor != "" || tag != "") && p.atLineEnd() {
+		if err := p.skipToContent(false); err != nil {
+			return nil, err
+		}
+		col := p.column()
+		if p.atDocBoundary() || col < parent || (col == parent && !(compact && p.isSeqIndicator())) {
+			v, err := p.resolveScalar("", true, tag)
+			if err == nil && anchor != "" {
+				p.anchors[anchor] = v
+			}
+			return v, err
+		}
+		inline = false
+	}
+	start := p.pos
+	var v interface{}
+	switch c := p.peek(0); {
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, p.errorf("an alias cannot have properties")
+		}
+		if p.isImplicitKey() {
+			v, err = p.parseBlockMap(p.column())
+		} else {
+			v, err = p.parseAlias()
+		}
+	case p.isSeqIndicator():
+		if inline {
+			return nil, p.errorf("block sequence entries are not allowed here")
+		}
+		v, err = p.parseBlockSeq(p.column())
+	case c == '?' && p.isWSAt(p.pos+1):
+		if inline {
+			return nil, p.errorf("explicit mapping keys are not allowed here")
+		}
+		v, err = p.parseBlockMap(p.column())
+	case c == '|' || c == '>':
+		var s string
+		if s, err = p.parseBlockScalar(parent); err == nil {
+			v, err = p.resolveScalar(s, false, tag)
+		}
+	case c == '[' || c == '{':
+		v, err = p.parseFlowNode()
+	default:
+		if p.isImplicitKey() {
+			if inline {
+				return nil, p.errorf("mapping values are not allowed here")
+			}
+			v, err = p.parseBlockMap(p.column())
+		} else {
+			v, err = p.parseScalarNode(parent, tag)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkTag(start, tag, v); err != nil {
+		return nil, err
+	}
+	v = p.markNonFinite(start, v)
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// isSeqIndicator 判断当前位置是否为块序列的 "- " 指示符
+func (p *yamlParser) isSeqIndicator() bool {
+	return p.peek(0) == '-' && p.isWSAt(p.pos+1)
+}
+
+// checkTag 检查集合上的 !!map、!!seq 标签与实际类型一致
+func (p *yamlParser) checkTag(pos int, tag string, v interface{}) error {
+	switch {
+	case tag == "map" && !isObject(v):
+		return p.errorAt(pos, "!!map tag applied to a non-mapping node")
+	case tag == "seq" && !isYAMLSeq(v):
+		return p.errorAt(pos, "!!seq tag applied to a non-sequence node")
+	}
+	return nil
+}
+
+// isYAMLSeq 判断值是否为序列
+func isYAMLSeq(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// asYAMLArray 返回数组值，不是数组时返回 nil
+func asYAMLArray(v interface{}) []interface{} {
+	arr, _ := v.([]interface{})
+	return arr
+}
+
+// isImplicitKey 判断当前行从当前位置开始是否为 "键: " 形式的映射条目
+func (p *yamlParser) isImplicitKey() bool {
+	i := p.pos
+	if i >= len(p.data) {
+		return false
+	}
+	switch p.data[i] {
+	case '"', '\'':
+		quote := p.data[i]
+		for i++; ; i++ {
+			if i >= len(p.data) || p.data[i] == '\n' {
+				return false
+			}
+			if quote == '"' && p.data[i] == '\\' {
+				i++
+				continue
+			}
+			if p.data[i] == quote {
+				if quote == '\'' && i+1 < len(p.data) && p.data[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+		}
+		i++
+		for i < len(p.data) && isYAMLBlank(p.data[i]) {
+			i++
+		}
+		return i < len(p.data) && p.data[i] == ':' && p.isWSAt(i+1)
+	case '[', '{':
+		return false
+	}
+	for ; i < len(p.data) && p.data[i] != '\n'; i++ {
+		if p.data[i] == ':' && p.isWSAt(i+1) {
+			return true
+		}
+		if p.data[i] == '#' && i > p.pos && isYAMLBlank(p.data[i-1]) {
+			return false
+		}
+	}
+	return false
+}
+
+// parseBlockMap 解析缩进为 indent 的块映射
+func (p *yamlParser) parseBlockMap(indent int) (interface{}, error) {
+	obj := NewJSONObject()
+	var merges []interface{}
+	for {
+		keyStart := p.pos
+		var key string
+		var value interface{}
+		merge := false
+		if p.peek(0) == '?' && p.isWSAt(p.pos+1) {
+			p.pos++
+			p.skipSpace()
+			var k interface{}
+			var err error
+			if p.atLineEnd() {
+				if err := p.skipToContent(false); err != nil {
+					return nil, err
+				}
+				if !p.atDocBoundary() && p.column() > indent {
+					k, err = p.parseBlockNode(indent, false, false)
+				}
+			} else {
+				k, err = p.parseBlockNode(indent, false, false)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if key, err = yamlKeyString(k); err != nil {
+				return nil, p.errorAt(keyStart, "%v", err)
+			}
+			if err := p.skipToContent(false); err != nil {
+				return nil, err
+			}
+			if !p.atDocBoundary() && p.column() == indent && p.peek(0) == ':' && p.isWSAt(p.pos+1) {
+				p.pos++
+				if value, err = p.parseMapValue(indent); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			var err error
+			if key, merge, err = p.parseImplicitKey(); err != nil {
+				return nil, err
+			}
+			for p.pos < len(p.data) && isYAMLBlank(p.data[p.pos]) {
+				p.pos++
+			}
+			if p.peek(0) != ':' {
+				return nil, p.errorf("expected ':' after mapping key, got %s", p.describe())
+			}
+			p.pos++
+			if value, err = p.parseMapValue(indent); err != nil {
+				return nil, err
+			}
+		}
+		if merge {
+			merges = append(merges, value)
+		} else {
+			if obj.Has(key) {
+				return nil, p.errorAt(keyStart, "duplicate mapping key %q", key)
+			}
+			obj.Set(key, value)
+		}
+		if err := p.skipToContent(false); err != nil {
+			return nil, err
+		}
+		if p.atDocBoundary() || p.column() < indent {
+			break
+		}
+		if p.column() > indent {
+			return nil, p.errorf("bad indentation of a mapping entry")
+		}
+		if !(p.peek(0) == '?' && p.isWSAt(p.pos+1)) && !p.isImplicitKey() {
+			return nil, p.errorf("expected a mapping key, got %s", p.describe())
+		}
+	}
+	if err := p.applyMerges(obj, merges); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// applyMerges 按 << 合并键的语义把映射中尚不存在的键从合并源复制过来，先出现的源优先
+func (p *yamlParser) applyMerges(obj *JSONObject, merges []interface{}) error {
+	for _, m := range merges {
+		sources := []interface{}{m}
+		if arr, ok := m.([]interface{}); ok {
+			sources = arr
+		}
+		for _, src := range sources {
+			srcObj, ok := src.(*JSONObject)
+			if !ok {
+				return p.errorf("merge key value must be a mapping or a sequence of mappings")
+			}
+			for _, k := range srcObj.Keys() {
+				if !obj.Has(k) {
+					obj.Set(k, cloneValue(srcObj.Get(k)))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseImplicitKey 解析单行的隐式键，返回键和是否为 << 合并键
+func (p *yamlParser) parseImplicitKey() (string, bool, error) {
+	switch p.peek(0) {
+	case '"':
+		s, err := p.parseDoubleQuoted()
+		return s, false, err
+	case '\'':
+		s, err := p.parseSingleQuoted()
+		return s, false, err
+	case '*':
+		start := p.pos
+		v, err := p.parseAlias()
+		if err != nil {
+			return "", false, err
+		}
+		key, err := yamlKeyString(v)
+		if err != nil {
+			return "", false, p.errorAt(start, "%v", err)
+		}
+		return key, false, nil
+	}
+	start, end := p.pos, p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		if p.data[p.pos] == ':' && p.isWSAt(p.pos+1) {
+			break
+		}
+		if !isYAMLBlank(p.data[p.pos]) {
+			end = p.pos + 1
+		}
+		p.pos++
+	}
+	key := string(p.data[start:end])
+	return key, key == "<<", nil
+}
+
+// parseMapValue 解析映射条目冒号之后的值
+func (p *yamlParser) parseMapValue(indent int) (interface{}, error) {
+	p.skipSpace()
+	if !p.atLineEnd() {
+		return p.parseBlockNode(indent, true, true)
+	}
+	if err := p.skipToContent(false); err != nil {
+		return nil, err
+	}
+	if p.atDocBoundary() {
+		return nil, nil
+	}
+	switch col := p.column(); {
+	case col > indent:
+		return p.parseBlockNode(indent, false, false)
+	case col == indent && p.isSeqIndicator():
+		return p.parseBlockSeq(indent)
+	}
+	return nil, nil
+}
+
+// parseBlockSeq 解析缩进为 indent 的块序列
+func (p *yamlParser) parseBlockSeq(indent int) (interface{}, error) {
+	arr := []interface{}{}
+	for {
+		p.pos++
+		p.skipSpace()
+		var item interface{}
+		var err error
+		if p.atLineEnd() {
+			if err := p.skipToContent(false); err != nil {
+				return nil, err
+			}
+			if !p.atDocBoundary() && p.column() > indent {
+				item, err = p.parseBlockNode(indent, false, false)
+			}
+		} else {
+			item, err = p.parseBlockNode(indent, false, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, item)
+		if err := p.skipToContent(false); err != nil {
+			return nil, err
+		}
+		if p.atDocBoundary() || p.column() < indent {
+			break
+		}
+		if p.column() > indent {
+			return nil, p.errorf("bad indentation of a sequence entry")
+		}
+		if !p.isSeqIndicator() {
+			break
+		}
+	}
+	return arr, nil
+}
+
+// parseAlias 解析 *name 别名，返回锚点值的副本
+func (p *yamlParser) parseAlias() (interface{}, error) {
+	start := p.pos
+	p.pos++
+	name := p.scanAnchorName()
+	v, ok := p.anchors[name]
+	if !ok {
+		return nil, p.errorAt(start, "unknown anchor %q", name)
+	}
+	p.aliasNodes += countYAMLNodes(v)
+	if p.aliasNodes > maxYAMLAliasNodes {
+		return nil, p.errorAt(start, "too many alias expansions")
+	}
+	return cloneValue(v), nil
+}
+
+// countYAMLNodes 统计值中的节点数
+func countYAMLNodes(v interface{}) int {
+	n := 1
+	switch c := v.(type) {
+	case *JSONObject:
+		for _, k := range c.keys {
+			n += countYAMLNodes(c.values[k])
+		}
+	case []interface{}:
+		for _, item := range c {
+			n += countYAMLNodes(item)
+		}
+	}
+	return n
+}
+
+// parseScalarNode 解析块上下文中的引号标量或普通标量
+func (p *yamlParser) parseScalarNode(parent int, tag string) (interface{}, error) {
+	switch c := p.peek(0); c {
+	case '"':
+		s, err := p.parseDoubleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return p.resolveScalar(s, false, tag)
+	case '\'':
+		s, err := p.parseSingleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return p.resolveScalar(s, false, tag)
+	case ']', '}', ',', '@', '`':
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return p.resolveScalar(p.parsePlain(parent, false), true, tag)
+}
+
+// parsePlain 解析普通（无引号）标量，续行按 YAML 规则折叠：单个换行变为空格，空行保留为换行
+// 块上下文中续行的列号必须大于 parent
+func (p *yamlParser) parsePlain(parent int, flow bool) string {
+	var sb strings.Builder
+	sb.WriteString(p.plainSegment(flow))
+	for {
+		save := p.pos
+		i := p.pos
+		for i < len(p.data) && isYAMLBlank(p.data[i]) {
+			i++
+		}
+		if i >= len(p.data) || p.data[i] != '\n' {
+			break
+		}
+		breaks := 0
+		for i < len(p.data) && (p.data[i] == '\n' || isYAMLBlank(p.data[i])) {
+			if p.data[i] == '\n' {
+				breaks++
+			}
+			i++
+		}
+		p.pos = i
+		if p.atDocBoundary() || p.data[i] == '#' || (!flow && p.column() <= parent) || (flow && isFlowIndicator(p.data[i])) {
+			p.pos = save
+			break
+		}
+		segment := p.plainSegment(flow)
+		if segment == "" {
+			p.pos = save
+			break
+		}
+		if breaks == 1 {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteString(strings.Repeat("\n", breaks-1))
+		}
+		sb.WriteString(segment)
+	}
+	return sb.String()
+}
+
+// plainSegment 读取普通标量在当前行的部分，停在 ": "、" #"、行尾或流式指示符处，不包含末尾空白
+func (p *yamlParser) plainSegment(flow bool) string {
+	start, end := p.pos, p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '\n' || (c == '#' && p.pos > start && isYAMLBlank(p.data[p.pos-1])) {
+			break
+		}
+		if c == ':' && (p.isWSAt(p.pos+1) || (flow && isFlowIndicator(p.peek(1)))) {
+			break
+		}
+		if flow && isFlowIndicator(c) {
+			break
+		}
+		p.pos++
+		if !isYAMLBlank(c) {
+			end = p.pos
+		}
+	}
+	p.pos = end
+	return string(p.data[start:end])
+}
+
+// parseDoubleQuoted 解析双引号标量，支持 YAML 转义序列和多行折叠
+func (p *yamlParser) parseDoubleQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorAt(start, "unterminated quoted scalar")
+		}
+		switch c := p.data[p.pos]; {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		case c == '\\' && p.peek(1) == '\n':
+			p.pos += 2
+			for p.pos < len(p.data) && isYAMLBlank(p.data[p.pos]) {
+				p.pos++
+			}
+		case c == '\\':
+			if err := p.parseYAMLEscape(&sb); err != nil {
+				return "", err
+			}
+		case isYAMLBlank(c) || c == '\n':
+			p.foldQuoted(&sb)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseSingleQuoted 解析单引号标量，连续两个单引号表示一个单引号
+func (p *yamlParser) parseSingleQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorAt(start, "unterminated quoted scalar")
+		}
+		switch c := p.data[p.pos]; {
+		case c == '\'' && p.peek(1) == '\'':
+			sb.WriteByte('\'')
+			p.pos += 2
+		case c == '\'':
+			p.pos++
+			return sb.String(), nil
+		case isYAMLBlank(c) || c == '\n':
+			p.foldQuoted(&sb)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// foldQuoted 处理引号标量中的空白：行尾空白被丢弃，单个换行折叠为空格，空行保留为换行
+func (p *yamlParser) foldQuoted(sb *strings.Builder) {
+	start := p.pos
+	for p.pos < len(p.data) && isYAMLBlank(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != '\n' {
+		sb.Write(p.data[start:p.pos])
+		return
+	}
+	breaks := 0
+	for p.pos < len(p.data) && (p.data[p.pos] == '\n' || isYAMLBlank(p.data[p.pos])) {
+		if p.data[p.pos] == '\n' {
+			breaks++
+		}
+		p.pos++
+	}
+	if breaks == 1 {
+		sb.WriteByte(' ')
+	} else {
+		sb.WriteString(strings.Repeat("\n", breaks-1))
+	}
+}
+
+// yamlEscapes 是 YAML 双引号标量中的单字符转义
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// parseYAMLEscape 解析双引号标量中的转义序列
+func (p *yamlParser) parseYAMLEscape(sb *strings.Builder) error {
+	c := p.peek(1)
+	if s, ok := yamlEscapes[c]; ok {
+		sb.WriteString(s)
+		p.pos += 2
+		return nil
+	}
+	n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if n == 0 || p.pos+2+n > len(p.data) {
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	code, err := strconv.ParseUint(string(p.data[p.pos+2:p.pos+2+n]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape sequence \\%c%s", c, p.data[p.pos+2:p.pos+2+n])
+	}
+	sb.WriteRune(rune(code))
+	p.pos += 2 + n
+	return nil
+}
+
+// parseBlockScalar 解析 | 字面块标量或 > 折叠块标量，支持 +/- 截断指示符和缩进指示符
+func (p *yamlParser) parseBlockScalar(parent int) (string, error) {
+	folded := p.data[p.pos] == '>'
+	p.pos++
+	var chomp byte
+	explicit := 0
+	for i := 0; i < 2; i++ {
+		switch c := p.peek(0); {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+			p.pos++
+		case c >= '1' && c <= '9' && explicit == 0:
+			explicit = int(c - '0')
+			p.pos++
+		}
+	}
+	p.skipSpace()
+	if !p.atLineEnd() {
+		return "", p.errorf("invalid block scalar header, got %s", p.describe())
+	}
+	if p.pos < len(p.data) {
+		p.pos++
+	}
+	indent := parent + explicit
+	if explicit == 0 {
+		indent = parent + 1
+		for i := p.pos; i < len(p.data); {
+			j := i
+			for j < len(p.data) && p.data[j] == ' ' {
+				j++
+			}
+			if j < len(p.data) && p.data[j] != '\n' {
+				if j-i > parent {
+					indent = j - i
+				}
+				break
+			}
+			i = j + 1
+		}
+	}
+	var lines []string
+	finalBreak := false
+	for p.pos < len(p.data) {
+		lineStart := p.pos
+		j := lineStart
+		for j < len(p.data) && p.data[j] == ' ' && j-lineStart < indent {
+			j++
+		}
+		end := bytes.IndexByte(p.data[lineStart:], '\n')
+		if end < 0 {
+			end = len(p.data)
+		} else {
+			end += lineStart
+		}
+		if j-lineStart < indent {
+			if len(bytes.TrimLeft(p.data[lineStart:end], " ")) > 0 {
+				break
+			}
+			lines = append(lines, "")
+		} else {
+			if indent == 0 && (p.isDocMarker("---") || p.isDocMarker("...")) {
+				break
+			}
+			lines = append(lines, string(p.data[j:end]))
+			finalBreak = end < len(p.data)
+		}
+		p.pos = end
+		if p.pos < len(p.data) {
+			p.pos++
+		}
+	}
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	trailing := len(lines) - n
+	var body string
+	if folded {
+		body = foldYAMLLines(lines[:n])
+	} else {
+		body = strings.Join(lines[:n], "\n")
+	}
+	switch {
+	case chomp == '-':
+		return body, nil
+	case chomp == '+':
+		if n > 0 && finalBreak {
+			trailing++
+		}
+		return body + strings.Repeat("\n", trailing), nil
+	case n > 0 && finalBreak:
+		return body + "\n", nil
+	}
+	return body, nil
+}
+
+// foldYAMLLines 按折叠块标量的规则连接各行：相邻的普通行以空格连接，空行和更深缩进的行保留换行
+func foldYAMLLines(lines []string) string {
+	var sb strings.Builder
+	empties := 0
+	first := true
+	prevMore := false
+	for _, line := range lines {
+		if line == "" {
+			empties++
+			continue
+		}
+		more := isYAMLBlank(line[0])
+		switch {
+		case first:
+			sb.WriteString(strings.Repeat("\n", empties))
+		case empties == 0 && !more && !prevMore:
+			sb.WriteByte(' ')
+		case !more && !prevMore:
+			sb.WriteString(strings.Repeat("\n", empties))
+		default:
+			sb.WriteString(strings.Repeat("\n", empties+1))
+		}
+		sb.WriteString(line)
+		empties = 0
+		first = false
+		prevMore = more
+	}
+	return sb.String()
+}
+
+// parseFlowNode 解析流式上下文中的节点
+func (p *yamlParser) parseFlowNode() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	anchor, tag, err := p.parseProperties(true)
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	var v interface{}
+	switch c := p.peek(0); {
+	case c == '[':
+		v, err = p.parseFlowSeq()
+	case c == '{':
+		v, err = p.parseFlowMap()
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, p.errorf("an alias cannot have properties")
+		}
+		v, err = p.parseAlias()
+	case c == '"':
+		var s string
+		if s, err = p.parseDoubleQuoted(); err == nil {
+			v, err = p.resolveScalar(s, false, tag)
+		}
+	case c == '\'':
+		var s string
+		if s, err = p.parseSingleQuoted(); err == nil {
+			v, err = p.resolveScalar(s, false, tag)
+		}
+	case p.pos >= len(p.data):
+		return nil, p.errorf("unexpected end of input in flow collection")
+	default:
+		v, err = p.resolveScalar(p.parsePlain(-1, true), true, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkTag(start, tag, v); err != nil {
+		return nil, err
+	}
+	v = p.markNonFinite(start, v)
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// isFlowValueIndicator 判断当前位置的冒号是否为流式映射的键值分隔符
+// 紧跟在引号标量或流式集合之后的冒号后面可以没有空格
+func (p *yamlParser) isFlowValueIndicator(jsonLike bool) bool {
+	return p.peek(0) == ':' && (jsonLike || p.isWSAt(p.pos+1) || isFlowIndicator(p.peek(1)))
+}
+
+// endsJSONLike 判断刚解析的节点是否以引号或括号结尾
+func (p *yamlParser) endsJSONLike() bool {
+	return p.pos > 0 && strings.IndexByte(`"']}`, p.data[p.pos-1]) >= 0
+}
+
+// parseFlowSeq 解析 [a, b, c] 形式的流式序列，元素可以是单个键值对
+func (p *yamlParser) parseFlowSeq() (interface{}, error) {
+	start := p.pos
+	p.pos++
+	arr := []interface{}{}
+	for {
+		if err := p.skipToContent(true); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorAt(start, "unterminated flow sequence")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		itemStart := p.pos
+		item, err := p.parseFlowNode()
+		if err != nil {
+			return nil, err
+		}
+		jsonLike := p.endsJSONLike()
+		if err := p.skipToContent(true); err != nil {
+			return nil, err
+		}
+		if p.isFlowValueIndicator(jsonLike) {
+			key, err := yamlKeyString(item)
+			if err != nil {
+				return nil, p.errorAt(itemStart, "%v", err)
+			}
+			p.pos++
+			if err := p.skipToContent(true); err != nil {
+				return nil, err
+			}
+			var value interface{}
+			if c := p.peek(0); c != ',' && c != ']' {
+				if value, err = p.parseFlowNode(); err != nil {
+					return nil, err
+				}
+				if err := p.skipToContent(true); err != nil {
+					return nil, err
+				}
+			}
+			item = NewJSONObject().Set(key, value)
+		}
+		arr = append(arr, item)
+		switch p.peek(0) {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in flow sequence, got %s", p.describe())
+		}
+	}
+}
+
+// parseFlowMap 解析 {a: 1, b: 2} 形式的流式映射
+func (p *yamlParser) parseFlowMap() (interface{}, error) {
+	start := p.pos
+	p.pos++
+	obj := NewJSONObject()
+	for {
+		if err := p.skipToContent(true); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorAt(start, "unterminated flow mapping")
+		}
+		if p.data[p.pos] == '}' {
+			p.pos++
+			return obj, nil
+		}
+		if p.data[p.pos] == '?' && p.isWSAt(p.pos+1) {
+			p.pos++
+			if err := p.skipToContent(true); err != nil {
+				return nil, err
+			}
+		}
+		keyStart := p.pos
+		var key string
+		jsonLike := false
+		switch c := p.peek(0); {
+		case c == ':' && (p.isWSAt(p.pos+1) || isFlowIndicator(p.peek(1))):
+		case strings.IndexByte(`"'[{*&!`, c) >= 0:
+			k, err := p.parseFlowNode()
+			if err != nil {
+				return nil, err
+			}
+			jsonLike = p.endsJSONLike()
+			if key, err = yamlKeyString(k); err != nil {
+				return nil, p.errorAt(keyStart, "%v", err)
+			}
+		default:
+			key = p.parsePlain(-1, true)
+		}
+		if err := p.skipToContent(true); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if p.isFlowValueIndicator(jsonLike) {
+			p.pos++
+			if err := p.skipToContent(true); err != nil {
+				return nil, err
+			}
+			if c := p.peek(0); c != ',' && c != '}' {
+				v, err := p.parseFlowNode()
+				if err != nil {
+					return nil, err
+				}
+				value = v
+				if err := p.skipToContent(true); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if obj.Has(key) {
+			return nil, p.errorAt(keyStart, "duplicate mapping key %q", key)
+		}
+		obj.Set(key, value)
+		switch p.peek(0) {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' in flow mapping, got %s", p.describe())
+		}
+	}
+}
+
+// yamlKeyString 将作为映射键的标量转换为字符串
+func yamlKeyString(v interface{}) (string, error) {
+	switch c := v.(type) {
+	case string:
+		return c, nil
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(c), nil
+	case json.Number:
+		return string(c), nil
+	case float64:
+		return yamlScalarText(c), nil
+	case yamlNonFinite:
+		return yamlScalarText(c.value), nil
+	}
+	return "", fmt.Errorf("unsupported %s mapping key, keys must be scalars", jsonTypeName(v))
+}
+
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlOctPattern   = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHexPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolveScalar 按标签或 core schema 将标量文本转换为值，plain 表示无引号的普通标量
+func (p *yamlParser) resolveScalar(text string, plain bool, tag string) (interface{}, error) {
+	switch tag {
+	case "":
+		if plain {
+			return resolveYAMLPlain(text), nil
+		}
+		return text, nil
+	case "!", "str", "binary":
+		return text, nil
+	case "null":
+		return nil, nil
+	case "bool":
+		if b, ok := resolveYAMLPlain(text).(bool); ok {
+			return b, nil
+		}
+	case "int":
+		if n, ok := yamlInt(text); ok {
+			return n, nil
+		}
+	case "float":
+		if v := resolveYAMLPlain(text); isNumber(v) {
+			return v, nil
+		}
+	case "map", "seq":
+		return nil, p.errorf("!!%s tag applied to a scalar", tag)
+	default:
+		if plain {
+			return resolveYAMLPlain(text), nil
+		}
+		return text, nil
+	}
+	return nil, p.errorf("invalid !!%s value %q", tag, text)
+}
+
+// resolveYAMLPlain 按 YAML 1.2 core schema 识别普通标量
+func resolveYAMLPlain(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	if n, ok := yamlInt(text); ok {
+		return n
+	}
+	if yamlFloatPattern.MatchString(text) {
+		return json.Number(normalizeYAMLFloat(text))
+	}
+	return text
+}
+
+// yamlInt 识别十进制、0o 八进制和 0x 十六进制整数，返回任意精度的十进制 json.Number
+func yamlInt(text string) (json.Number, bool) {
+	base := 10
+	digits := strings.TrimPrefix(text, "+")
+	switch {
+	case yamlIntPattern.MatchString(text):
+	case yamlOctPattern.MatchString(text):
+		base, digits = 8, text[2:]
+	case yamlHexPattern.MatchString(text):
+		base, digits = 16, text[2:]
+	default:
+		return "", false
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return "", false
+	}
+	return json.Number(n.String()), true
+}
+
+// normalizeYAMLFloat 将 YAML 浮点数写法转换为合法的 JSON 数字（去掉正号和前导零，补全小数点两侧）
+func normalizeYAMLFloat(text string) string {
+	sign := ""
+	switch text[0] {
+	case '-':
+		sign = "-"
+		text = text[1:]
+	case '+':
+		text = text[1:]
+	}
+	mantissa, exp := text, ""
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		mantissa, exp = text[:i], text[i:]
+	}
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	s := sign + intPart
+	if frac != "" {
+		s += "." + frac
+	}
+	return s + exp
+}
+
+// writeYAMLMapping 以块样式写出映射，inlineFirst 表示第一个键紧跟在 "- " 之后
+func writeYAMLMapping(buf *bytes.Buffer, obj *JSONObject, indent int, inlineFirst bool) {
+	for i, key := range obj.Keys() {
+		if i > 0 || !inlineFirst {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteString(yamlStringText(key))
+		buf.WriteByte(':')
+		writeYAMLChild(buf, obj.Get(key), indent, true)
+	}
+}
+
+// writeYAMLSequence 以块样式写出序列，inlineFirst 表示第一个元素紧跟在 "- " 之后
+func writeYAMLSequence(buf *bytes.Buffer, arr []interface{}, indent int, inlineFirst bool) {
+	for i, item := range arr {
+		if i > 0 || !inlineFirst {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteByte('-')
+		writeYAMLChild(buf, item, indent, false)
+	}
+}
+
+// writeYAMLChild 写出映射值或序列元素，indent 是所属集合的缩进
+func writeYAMLChild(buf *bytes.Buffer, v interface{}, indent int, inMapping bool) {
+	switch {
+	case objectLen(v) > 0:
+		if inMapping {
+			buf.WriteByte('\n')
+			writeYAMLMapping(buf, v.(*JSONObject), indent+2, false)
+		} else {
+			buf.WriteByte(' ')
+			writeYAMLMapping(buf, v.(*JSONObject), indent+2, true)
+		}
+	case len(asYAMLArray(v)) > 0:
+		if inMapping {
+			buf.WriteByte('\n')
+			writeYAMLSequence(buf, asYAMLArray(v), indent+2, false)
+		} else {
+			buf.WriteByte(' ')
+			writeYAMLSequence(buf, asYAMLArray(v), indent+2, true)
+		}
+	default:
+		if s, ok := v.(string); ok && canWriteYAMLLiteral(s) {
+			writeYAMLLiteral(buf, s, indent+2)
+			return
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalarText(v))
+		buf.WriteByte('\n')
+	}
+}
+
+// canWriteYAMLLiteral 判断多行字符串能否无损地写为 | 块标量
+func canWriteYAMLLiteral(s string) bool {
+	if !strings.Contains(s, "\n") {
+		return false
+	}
+	firstContent := true
+	for _, line := range strings.Split(s, "\n") {
+		if firstContent && line != "" {
+			if isYAMLBlank(line[0]) {
+				return false
+			}
+			firstContent = false
+		}
+		for _, r := range line {
+			if r != '\t' && !unicode.IsPrint(r) {
+				return false
+			}
+		}
+	}
+	return !firstContent
+}
+
+// writeYAMLLiteral 将多行字符串写为 | 块标量，根据结尾换行数选择截断指示符
+func writeYAMLLiteral(buf *bytes.Buffer, s string, indent int) {
+	lines := strings.Split(s, "\n")
+	switch {
+	case !strings.HasSuffix(s, "\n"):
+		buf.WriteString(" |-\n")
+	case strings.HasSuffix(s, "\n\n"):
+		buf.WriteString(" |+\n")
+		lines = lines[:len(lines)-1]
+	default:
+		buf.WriteString(" |\n")
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		if line != "" {
+			buf.WriteString(strings.Repeat(" ", indent))
+			buf.WriteString(line)
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+// yamlScalarText 返回标量值的 YAML 写法
+func yamlScalarText(v interface{}) string {
+	switch c := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(c)
+	case json.Number:
+		return string(c)
+	case float64:
+		switch {
+		case math.IsInf(c, 1):
+			return ".inf"
+		case math.IsInf(c, -1):
+			return "-.inf"
+		case math.IsNaN(c):
+			return ".nan"
+		}
+		return strconv.FormatFloat(c, 'g', -1, 64)
+	case string:
+		return yamlStringText(c)
+	case *JSONObject:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// yamlStringText 返回字符串的 YAML 写法，不会被误识别为其他类型时不加引号
+func yamlStringText(s string) string {
+	if isYAMLPlainSafe(s) {
+		return s
+	}
+	return strings.ReplaceAll(quoteJSONString(s), "\x7f", `\x7F`)
+}
+
+// yaml11Bools 是 YAML 1.1 中表示布尔值的单词，为兼容旧解析器输出时加引号
+var yaml11Bools = map[string]bool{"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true}
+
+// isYAMLPlainSafe 判断字符串能否不加引号地写为普通标量
+func isYAMLPlainSafe(s string) bool {
+	if s == "" || yaml11Bools[strings.ToLower(s)] || strings.HasPrefix(s, "...") {
+		return false
+	}
+	if _, ok := resolveYAMLPlain(s).(string); !ok {
+		return false
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@` \t", s[0]) >= 0 || strings.HasSuffix(s, " ") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
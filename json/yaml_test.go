@@ -0,0 +1,105 @@
+package jsonutil
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "block mapping order", in: "b: 1\na: two\nc: true\n", want: `{"b":1,"a":"two","c":true}`},
+		{name: "sequence", in: "- 1\n- null\n- ~\n- 0x1F\n- 1.5e3\n", want: `[1,null,null,31,1.5e3]`},
+		{name: "large integer", in: "id: 12345678901234567890\n", want: `{"id":12345678901234567890}`},
+		{name: "nested", in: "a:\n  - x: 1\n    y: [1, 2]\n  - {z: 'q'}\n", want: `{"a":[{"x":1,"y":[1,2]},{"z":"q"}]}`},
+		{name: "anchors and merge", in: "base: &b {x: 1, y: 2}\nd:\n  <<: *b\n  y: 3\n", want: `{"base":{"x":1,"y":2},"d":{"y":3,"x":1}}`},
+		{name: "block scalars", in: "lit: |\n  a\n  b\nfold: >\n  a\n  b\n", want: `{"lit":"a\nb\n","fold":"a b\n"}`},
+		{name: "quoted special values", in: "a: '.inf'\nb: \".nan\"\n", want: `{"a":".inf","b":".nan"}`},
+		{name: "non-finite key", in: ".inf: 1\n", want: `{".inf":1}`},
+		{name: "empty", in: "", want: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := YAMLToJSON([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("YAMLToJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("YAMLToJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAMLToJSONNonFinite(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		line   int
+		column int
+		msg    string
+	}{
+		{name: "root", in: ".inf\n", line: 1, column: 1, msg: "(root): .inf cannot be represented in JSON"},
+		{name: "mapping", in: "a:\n  b: -.Inf\n", line: 2, column: 6, msg: "a.b: -.inf cannot be represented in JSON"},
+		{name: "sequence", in: "list:\n  - 1\n  - .nan\n", line: 3, column: 5, msg: "list[1]: .nan cannot be represented in JSON"},
+		{name: "flow", in: "a: {b: [1, .inf]}\n", line: 1, column: 12, msg: "a.b[1]: .inf cannot be represented in JSON"},
+		{name: "tagged", in: "x: !!float .inf\n", line: 1, column: 12, msg: "x: .inf cannot be represented in JSON"},
+		{name: "alias", in: "a: 1\nb: &n .nan\nc: *n\n", line: 2, column: 7, msg: "b: .nan cannot be represented in JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := YAMLToJSON([]byte(tt.in))
+			var syntaxErr *YAMLSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("YAMLToJSON() error = %v, want *YAMLSyntaxError", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column || syntaxErr.Msg != tt.msg {
+				t.Errorf("YAMLToJSON() error = %v, want line %d, column %d: %s", err, tt.line, tt.column, tt.msg)
+			}
+		})
+	}
+
+	var v struct{ X float64 }
+	if err := UnmarshalYAML([]byte("x: .inf\n"), &v); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("UnmarshalYAML() error = %v, want error with line", err)
+	}
+}
+
+func TestParseYAMLNonFinite(t *testing.T) {
+	v, err := ParseYAML([]byte("a: .inf\nb: [-.inf, .nan]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[string]interface{})
+	b := m["b"].([]interface{})
+	if !math.IsInf(m["a"].(float64), 1) || !math.IsInf(b[0].(float64), -1) || !math.IsNaN(b[1].(float64)) {
+		t.Errorf("ParseYAML() = %#v", v)
+	}
+}
+
+func TestYAMLSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		line int
+	}{
+		{name: "duplicate key", in: "a: 1\na: 2\n", line: 2},
+		{name: "bad indentation", in: "a:\n  b: 1\n   c: 2\n", line: 3},
+		{name: "unknown anchor", in: "a: *x\n", line: 1},
+		{name: "unterminated flow", in: "a: [1, 2\n", line: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAML([]byte(tt.in))
+			var syntaxErr *YAMLSyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Line != tt.line {
+				t.Errorf("ParseYAML() error = %v, want syntax error at line %d", err, tt.line)
+			}
+		})
+	}
+}